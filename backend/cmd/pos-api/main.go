@@ -1,17 +1,33 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"pos-system/internal/auth"
 	"pos-system/internal/category"
 	"pos-system/internal/config"
+	"pos-system/internal/customer"
 	"pos-system/internal/db"
+	"pos-system/internal/export"
+	"pos-system/internal/giftcard"
 	"pos-system/internal/inventory"
+	"pos-system/internal/invoice"
+	"pos-system/internal/money"
+	"pos-system/internal/payment"
 	"pos-system/internal/product"
+	"pos-system/internal/promotion"
+	"pos-system/internal/receipt"
 	"pos-system/internal/report"
+	"pos-system/internal/returns"
 	"pos-system/internal/sale"
+	"pos-system/internal/shift"
+	"pos-system/internal/table"
+	"pos-system/internal/tax"
+	"pos-system/internal/voucher"
 	"pos-system/internal/server"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
@@ -50,10 +66,109 @@ func main() {
 
 	// Initialize services
 	authService := auth.NewService(queries, cfg.JWTSecret)
-	productService := product.NewService(queries, pool)
+	var scalePrefixes []string
+	for _, prefix := range strings.Split(cfg.ScaleBarcodePrefixes, ",") {
+		if prefix = strings.TrimSpace(prefix); prefix != "" {
+			scalePrefixes = append(scalePrefixes, prefix)
+		}
+	}
+	scaleConfig := product.ScaleConfig{
+		Prefixes:   scalePrefixes,
+		ItemDigits: cfg.ScaleBarcodeItemDigits,
+		Mode:       cfg.ScaleBarcodeMode,
+	}
+	if err := scaleConfig.Validate(); err != nil {
+		logger.Fatal("Invalid scale barcode config", zap.Error(err))
+	}
+	productService := product.NewService(queries, pool, scaleConfig)
 	inventoryService := inventory.NewService(queries)
 	categoryService := category.NewService(queries)
-	saleService := sale.NewService(queries, pool)
+	taxService := tax.NewService(queries)
+	promotionService := promotion.NewService(queries)
+	voucherService := voucher.NewService(queries)
+	customerService := customer.NewService(queries)
+	shiftService := shift.NewService(queries, pool)
+	giftCardService := giftcard.NewService(queries, pool)
+	invoiceLocation, err := time.LoadLocation(cfg.InvoiceTimezone)
+	if err != nil {
+		logger.Fatal("Invalid INVOICE_TIMEZONE", zap.Error(err))
+	}
+	invoiceConfig := sale.InvoiceConfig{
+		Prefix:       cfg.InvoicePrefix,
+		StoreCode:    cfg.InvoiceStoreCode,
+		Reset:        cfg.InvoiceReset,
+		CounterWidth: cfg.InvoiceCounterWidth,
+		Location:     invoiceLocation,
+	}
+	if err := invoiceConfig.Validate(); err != nil {
+		logger.Fatal("Invalid invoice numbering config", zap.Error(err))
+	}
+	spendPerPoint, err := money.Parse(cfg.LoyaltySpendPerPoint)
+	if err != nil {
+		logger.Fatal("Invalid LOYALTY_SPEND_PER_POINT", zap.Error(err))
+	}
+	pointValue, err := money.Parse(cfg.LoyaltyPointValue)
+	if err != nil {
+		logger.Fatal("Invalid LOYALTY_POINT_VALUE", zap.Error(err))
+	}
+	loyaltyConfig := sale.LoyaltyConfig{
+		SpendPerPoint: spendPerPoint,
+		PointValue:    pointValue,
+	}
+	if err := loyaltyConfig.Validate(); err != nil {
+		logger.Fatal("Invalid loyalty config", zap.Error(err))
+	}
+	roundingIncrement, err := money.Parse(cfg.RoundingIncrement)
+	if err != nil {
+		logger.Fatal("Invalid CASH_ROUNDING_INCREMENT", zap.Error(err))
+	}
+	cashRounding := sale.CashRounding{
+		Increment: roundingIncrement,
+		Mode:      cfg.RoundingMode,
+	}
+	if err := cashRounding.Validate(); err != nil {
+		logger.Fatal("Invalid cash rounding config", zap.Error(err))
+	}
+
+	paymentProvider, err := payment.NewProvider(payment.Config{
+		Provider:       cfg.PaymentProvider,
+		CallbackSecret: cfg.PaymentCallbackKey,
+		QRISBaseURL:    cfg.QRISBaseURL,
+		QRISServerKey:  cfg.QRISServerKey,
+	})
+	if err != nil {
+		logger.Fatal("Invalid payment provider config", zap.Error(err))
+	}
+
+	saleService := sale.NewService(queries, pool, authService, sale.Config{
+		HeldCartTTL:  time.Duration(cfg.HeldCartTTLMinutes) * time.Minute,
+		Invoice:      invoiceConfig,
+		Loyalty:      loyaltyConfig,
+		CashRounding: cashRounding,
+		Payments:     paymentProvider,
+		ChargeTTL:    time.Duration(cfg.PaymentChargeTTL) * time.Minute,
+	})
+	receiptConfig := receipt.Config{
+		StoreName:    cfg.ReceiptStoreName,
+		StoreAddress: cfg.ReceiptStoreAddress,
+		StorePhone:   cfg.ReceiptStorePhone,
+		Footer:       cfg.ReceiptFooter,
+		PaperWidth:   cfg.ReceiptPaperWidth,
+		CodePage:     cfg.ReceiptCodePage,
+	}
+	if err := receiptConfig.Validate(); err != nil {
+		logger.Fatal("Invalid receipt config", zap.Error(err))
+	}
+	invoicePDFConfig := invoice.Config{
+		StoreName:    cfg.ReceiptStoreName,
+		StoreAddress: cfg.ReceiptStoreAddress,
+		StorePhone:   cfg.ReceiptStorePhone,
+		StoreEmail:   cfg.InvoiceStoreEmail,
+		TaxID:        cfg.InvoiceTaxID,
+		Terms:        cfg.InvoiceTerms,
+	}
+	returnService := returns.NewService(queries, pool, saleService)
+	tableService := table.NewService(queries, pool, saleService)
 	reportService := report.NewService(queries)
 
 	// Initialize handlers
@@ -61,7 +176,18 @@ func main() {
 	productHandler := product.NewHandler(productService)
 	inventoryHandler := inventory.NewHandler(inventoryService)
 	categoryHandler := category.NewHandler(categoryService)
+	taxHandler := tax.NewHandler(taxService)
+	promotionHandler := promotion.NewHandler(promotionService)
+	voucherHandler := voucher.NewHandler(voucherService)
+	customerHandler := customer.NewHandler(customerService)
+	shiftHandler := shift.NewHandler(shiftService)
+	giftCardHandler := giftcard.NewHandler(giftCardService)
 	saleHandler := sale.NewHandler(saleService)
+	receiptHandler := receipt.NewHandler(saleService, receiptConfig)
+	invoiceHandler := invoice.NewHandler(saleService, customerService, invoicePDFConfig)
+	exportHandler := export.NewHandler(saleService, logger)
+	returnHandler := returns.NewHandler(returnService)
+	tableHandler := table.NewHandler(tableService)
 	reportHandler := report.NewHandler(reportService)
 
 	// Initialize server
@@ -70,12 +196,35 @@ func main() {
 		productHandler,
 		inventoryHandler,
 		categoryHandler,
+		taxHandler,
+		promotionHandler,
+		voucherHandler,
+		customerHandler,
+		shiftHandler,
+		giftCardHandler,
 		saleHandler,
+		receiptHandler,
+		invoiceHandler,
+		exportHandler,
+		returnHandler,
+		tableHandler,
 		reportHandler,
 		authService,
 		logger,
 	)
 
+	// Fail QRIS and e-wallet sales whose charge expired unpaid and retry
+	// refunds the provider turned down
+	if paymentProvider != nil {
+		go func() {
+			for range time.Tick(time.Minute) {
+				if err := saleService.SweepPayments(context.Background()); err != nil {
+					logger.Error("Failed to sweep payment charges", zap.Error(err))
+				}
+			}
+		}()
+	}
+
 	// Start server
 	addr := fmt.Sprintf("%s:%s", cfg.ServerHost, cfg.ServerPort)
 	logger.Info("Starting server", zap.String("address", addr))
@@ -1,6 +1,6 @@
 -- name: CreateCategory :one
-INSERT INTO categories (name)
-VALUES ($1)
+INSERT INTO categories (name, tax_rate_id)
+VALUES ($1, $2)
 RETURNING *;
 
 -- name: GetCategoryByID :one
@@ -13,7 +13,7 @@ ORDER BY name;
 
 -- name: UpdateCategory :one
 UPDATE categories
-SET name = $2
+SET name = $2, tax_rate_id = $3
 WHERE id = $1
 RETURNING *;
 
@@ -0,0 +1,62 @@
+-- name: CreateCustomer :one
+INSERT INTO customers (name, phone, email)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetCustomerByID :one
+SELECT * FROM customers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListCustomers :many
+SELECT * FROM customers
+ORDER BY name
+LIMIT $1 OFFSET $2;
+
+-- name: SearchCustomersByPhone :many
+SELECT * FROM customers
+WHERE phone LIKE sqlc.arg(phone)::text || '%'
+ORDER BY phone
+LIMIT 20;
+
+-- name: UpdateCustomer :one
+UPDATE customers
+SET name = $2, phone = $3, email = $4, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCustomer :exec
+DELETE FROM customers WHERE id = $1;
+
+-- name: AddCustomerPoints :one
+-- Reversals never take the balance below zero, even if the customer has
+-- already spent the points being reversed.
+UPDATE customers
+SET points = GREATEST(points + sqlc.arg(points)::bigint, 0), updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: RedeemCustomerPoints :execrows
+UPDATE customers
+SET points = points - sqlc.arg(points)::bigint, updated_at = now()
+WHERE id = sqlc.arg(id) AND points >= sqlc.arg(points)::bigint;
+
+-- name: CreateCustomerPointEntry :one
+INSERT INTO customer_point_entries (customer_id, sale_id, type, points)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListCustomerSales :many
+SELECT s.*, u.username as cashier_name
+FROM sales s
+LEFT JOIN users u ON s.user_id = u.id
+WHERE s.customer_id = $1
+ORDER BY s.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: GetCustomerSalesSummary :one
+SELECT
+  COUNT(*) as visit_count,
+  COALESCE(SUM(total_amount), 0)::numeric as total_spent,
+  MAX(created_at)::timestamptz as last_visit
+FROM sales
+WHERE customer_id = $1 AND voided_at IS NULL AND payment_status = 'paid';
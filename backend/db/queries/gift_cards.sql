@@ -0,0 +1,67 @@
+-- name: CreateGiftCard :one
+INSERT INTO gift_cards (card_number, type, customer_id, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetGiftCardByID :one
+SELECT * FROM gift_cards
+WHERE id = $1 LIMIT 1;
+
+-- name: GetGiftCardByNumber :one
+SELECT * FROM gift_cards
+WHERE card_number = $1 LIMIT 1;
+
+-- name: GetGiftCardForUpdate :one
+SELECT * FROM gift_cards
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: GetGiftCardByNumberForUpdate :one
+SELECT * FROM gift_cards
+WHERE card_number = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListGiftCards :many
+SELECT * FROM gift_cards
+ORDER BY created_at DESC, id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ListExpiredGiftCardsForUpdate :many
+-- Cards past their expiry date that still hold a balance.
+SELECT * FROM gift_cards
+WHERE expires_at <= sqlc.arg(at) AND balance > 0
+ORDER BY id
+FOR UPDATE;
+
+-- name: UpdateGiftCardBalance :one
+UPDATE gift_cards
+SET balance = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetGiftCardActive :one
+UPDATE gift_cards
+SET active = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateGiftCardTransaction :one
+INSERT INTO gift_card_transactions (gift_card_id, type, amount, balance_after, sale_id, return_id, payment_method, user_id, note)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: ListGiftCardTransactions :many
+SELECT * FROM gift_card_transactions
+WHERE gift_card_id = $1
+ORDER BY created_at, id;
+
+-- name: ListGiftCardRedemptionsBySale :many
+SELECT * FROM gift_card_transactions
+WHERE sale_id = $1 AND type = 'redeem'
+ORDER BY id;
+
+-- name: GetGiftCardByReturn :one
+SELECT g.* FROM gift_cards g
+JOIN gift_card_transactions t ON t.gift_card_id = g.id
+WHERE t.return_id = $1
+LIMIT 1;
@@ -0,0 +1,18 @@
+-- name: CreateHeldCart :one
+INSERT INTO held_carts (user_id, label, items, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: ListHeldCartsByUser :many
+SELECT * FROM held_carts
+WHERE user_id = $1 AND expires_at > now()
+ORDER BY created_at;
+
+-- name: DeleteHeldCart :one
+DELETE FROM held_carts
+WHERE id = $1 AND user_id = $2 AND expires_at > now()
+RETURNING *;
+
+-- name: DeleteExpiredHeldCarts :execrows
+DELETE FROM held_carts
+WHERE expires_at <= now();
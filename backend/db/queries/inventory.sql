@@ -2,6 +2,13 @@
 SELECT * FROM inventory
 WHERE product_id = $1 LIMIT 1;
 
+-- name: GetInventoryByProductForUpdate :one
+-- Locks the row until the transaction ends. Lock several products in
+-- ascending product_id order so concurrent sales cannot deadlock.
+SELECT * FROM inventory
+WHERE product_id = $1 LIMIT 1
+FOR UPDATE;
+
 -- name: CreateInventory :one
 INSERT INTO inventory (product_id, qty)
 VALUES ($1, $2)
@@ -0,0 +1,6 @@
+-- name: NextInvoiceCounter :one
+INSERT INTO invoice_counters (scope, last_value)
+VALUES ($1, 1)
+ON CONFLICT (scope) DO UPDATE
+SET last_value = invoice_counters.last_value + 1, updated_at = now()
+RETURNING last_value;
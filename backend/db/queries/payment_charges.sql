@@ -0,0 +1,46 @@
+-- name: CreatePaymentCharge :one
+INSERT INTO payment_charges (sale_id, provider, method, reference, amount, qr_string, checkout_url, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetPaymentChargeBySale :one
+SELECT * FROM payment_charges
+WHERE sale_id = $1
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetPaymentChargeBySaleForUpdate :one
+SELECT * FROM payment_charges
+WHERE sale_id = $1
+ORDER BY id DESC
+LIMIT 1
+FOR UPDATE;
+
+-- name: GetPaymentChargeByReferenceForUpdate :one
+SELECT * FROM payment_charges
+WHERE provider = $1 AND reference = $2
+LIMIT 1
+FOR UPDATE;
+
+-- name: UpdatePaymentChargeStatus :one
+UPDATE payment_charges
+SET status = sqlc.arg(status), paid_at = COALESCE(sqlc.narg(paid_at)::timestamptz, paid_at), updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListOverduePendingSales :many
+-- Pending sales that no callback will settle: their charge has expired, or
+-- they are older than started_before and never got one.
+SELECT s.id AS sale_id, c.reference
+FROM sales s
+LEFT JOIN payment_charges c ON c.sale_id = s.id
+WHERE s.payment_status = 'pending'
+  AND (c.expires_at <= sqlc.arg(now) OR (c.id IS NULL AND s.created_at < sqlc.arg(started_before)))
+ORDER BY s.id;
+
+-- name: ListUnrefundedCharges :many
+-- Paid charges of voided sales: money still owed back to the customer.
+SELECT c.* FROM payment_charges c
+JOIN sales s ON s.id = c.sale_id
+WHERE c.status = 'paid' AND s.voided_at IS NOT NULL
+ORDER BY c.id;
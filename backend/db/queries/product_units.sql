@@ -0,0 +1,23 @@
+-- name: CreateProductUnit :one
+INSERT INTO product_units (product_id, name, factor, price)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetProductUnit :one
+SELECT * FROM product_units
+WHERE id = $1 AND product_id = $2 LIMIT 1;
+
+-- name: ListProductUnits :many
+SELECT * FROM product_units
+WHERE product_id = $1
+ORDER BY factor, id;
+
+-- name: UpdateProductUnit :one
+UPDATE product_units
+SET name = $3, factor = $4, price = $5
+WHERE id = $1 AND product_id = $2
+RETURNING *;
+
+-- name: DeleteProductUnit :execrows
+DELETE FROM product_units
+WHERE id = $1 AND product_id = $2;
@@ -1,6 +1,6 @@
 -- name: CreateProduct :one
-INSERT INTO products (sku, name, category_id, price, cost_price, unit)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO products (sku, name, category_id, price, cost_price, unit, tax_rate_id, tax_exempt, qty_precision)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING *;
 
 -- name: GetProductByID :one
@@ -38,7 +38,7 @@ ORDER BY p.name;
 
 -- name: UpdateProduct :one
 UPDATE products
-SET sku = $2, name = $3, category_id = $4, price = $5, cost_price = $6, unit = $7
+SET sku = $2, name = $3, category_id = $4, price = $5, cost_price = $6, unit = $7, tax_rate_id = $8, tax_exempt = $9, qty_precision = $10
 WHERE id = $1
 RETURNING *;
 
@@ -0,0 +1,29 @@
+-- name: CreatePromotion :one
+INSERT INTO promotions (name, type, category_id, product_id, percent, amount, buy_qty, get_qty, min_spend, starts_at, ends_at, active)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING *;
+
+-- name: GetPromotionByID :one
+SELECT * FROM promotions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListPromotions :many
+SELECT * FROM promotions
+ORDER BY starts_at DESC, id DESC;
+
+-- name: ListActivePromotions :many
+SELECT * FROM promotions
+WHERE active
+  AND starts_at <= sqlc.arg(at)
+  AND (ends_at IS NULL OR ends_at > sqlc.arg(at))
+ORDER BY id;
+
+-- name: UpdatePromotion :one
+UPDATE promotions
+SET name = $2, type = $3, category_id = $4, product_id = $5, percent = $6, amount = $7,
+    buy_qty = $8, get_qty = $9, min_spend = $10, starts_at = $11, ends_at = $12, active = $13
+WHERE id = $1
+RETURNING *;
+
+-- name: DeletePromotion :exec
+DELETE FROM promotions WHERE id = $1;
@@ -1,11 +1,33 @@
 -- name: SalesByDate :many
+WITH sales_by_day AS (
+  SELECT
+    DATE(s.created_at) as sale_date,
+    COUNT(*) as total_transactions,
+    SUM(s.total_amount) as gross_revenue,
+    SUM(s.rounding_amount) as total_rounding
+  FROM sales s
+  WHERE s.created_at >= $1 AND s.created_at <= $2
+    AND s.voided_at IS NULL
+    AND s.payment_status = 'paid'
+  GROUP BY DATE(s.created_at)
+),
+refunds_by_day AS (
+  SELECT
+    DATE(r.created_at) as refund_date,
+    SUM(r.refund_amount) as total_refunds
+  FROM sale_returns r
+  WHERE r.created_at >= $1 AND r.created_at <= $2
+  GROUP BY DATE(r.created_at)
+)
 SELECT 
-  DATE(s.created_at) as sale_date,
-  COUNT(*) as total_transactions,
-  COALESCE(SUM(s.total_amount), 0) as total_revenue
-FROM sales s
-WHERE s.created_at >= $1 AND s.created_at <= $2
-GROUP BY DATE(s.created_at)
+  COALESCE(sd.sale_date, rd.refund_date)::date as sale_date,
+  COALESCE(sd.total_transactions, 0)::bigint as total_transactions,
+  (COALESCE(sd.gross_revenue, 0) - COALESCE(rd.total_refunds, 0))::numeric as total_revenue,
+  COALESCE(sd.gross_revenue, 0)::numeric as gross_revenue,
+  COALESCE(rd.total_refunds, 0)::numeric as total_refunds,
+  COALESCE(sd.total_rounding, 0)::numeric as total_rounding
+FROM sales_by_day sd
+FULL OUTER JOIN refunds_by_day rd ON sd.sale_date = rd.refund_date
 ORDER BY sale_date DESC;
 
 -- name: TopProducts :many
@@ -13,23 +35,91 @@ SELECT
   p.id,
   p.name,
   p.sku,
-  SUM(si.qty) as total_qty_sold,
-  COALESCE(SUM(si.subtotal), 0) as total_revenue
+  (SUM(si.qty * si.unit_factor) - COALESCE(SUM(ri.returned_qty * si.unit_factor), 0))::numeric as total_qty_sold,
+  (COALESCE(SUM(si.subtotal), 0) - COALESCE(SUM(ri.refunded_amount), 0))::numeric as total_revenue
 FROM sale_items si
 JOIN products p ON si.product_id = p.id
 JOIN sales s ON si.sale_id = s.id
+LEFT JOIN (
+  SELECT sale_item_id, SUM(qty) as returned_qty, SUM(refund_amount) as refunded_amount
+  FROM sale_return_items
+  GROUP BY sale_item_id
+) ri ON ri.sale_item_id = si.id
 WHERE s.created_at >= $1 AND s.created_at <= $2
+  AND s.voided_at IS NULL
+  AND s.payment_status = 'paid'
 GROUP BY p.id, p.name, p.sku
 ORDER BY total_qty_sold DESC
 LIMIT $3;
 
 -- name: SalesByPaymentMethod :many
+WITH payments_by_method AS (
+  SELECT
+    sp.method,
+    COUNT(DISTINCT sp.sale_id) as transaction_count,
+    SUM(sp.amount) as total_amount
+  FROM sale_payments sp
+  JOIN sales s ON sp.sale_id = s.id
+  WHERE s.created_at >= $1 AND s.created_at <= $2
+    AND s.voided_at IS NULL
+    AND s.payment_status = 'paid'
+  GROUP BY sp.method
+),
+refunds_by_method AS (
+  SELECT
+    refund_method,
+    SUM(refund_amount) as total_refunds
+  FROM sale_returns
+  WHERE created_at >= $1 AND created_at <= $2
+  GROUP BY refund_method
+)
 SELECT 
-  payment_method,
-  COUNT(*) as transaction_count,
-  COALESCE(SUM(total_amount), 0) as total_amount
-FROM sales
-WHERE created_at >= $1 AND created_at <= $2
-GROUP BY payment_method
+  COALESCE(pm.method, rm.refund_method) as payment_method,
+  COALESCE(pm.transaction_count, 0)::bigint as transaction_count,
+  (COALESCE(pm.total_amount, 0) - COALESCE(rm.total_refunds, 0))::numeric as total_amount,
+  COALESCE(rm.total_refunds, 0)::numeric as total_refunds
+FROM payments_by_method pm
+FULL OUTER JOIN refunds_by_method rm
+  ON pm.method = COALESCE(rm.refund_method, '')
 ORDER BY total_amount DESC;
 
+-- name: TaxSummary :many
+-- Tax per rate charged. taxable_amount is the base the tax was charged on
+-- (line subtotals net of tax); refunded tax is prorated from each refund.
+WITH tax_by_rate AS (
+  SELECT
+    si.tax_rate,
+    si.tax_inclusive,
+    COUNT(DISTINCT s.id) as transaction_count,
+    SUM(si.subtotal - si.tax_amount) as taxable_amount,
+    SUM(si.tax_amount) as tax_amount
+  FROM sale_items si
+  JOIN sales s ON si.sale_id = s.id
+  WHERE s.created_at >= $1 AND s.created_at <= $2
+    AND s.voided_at IS NULL
+    AND s.payment_status = 'paid'
+  GROUP BY si.tax_rate, si.tax_inclusive
+),
+refunded_tax_by_rate AS (
+  SELECT
+    si.tax_rate,
+    si.tax_inclusive,
+    SUM(ROUND(ri.refund_amount * si.tax_amount / NULLIF(si.subtotal, 0), 2)) as tax_refunded
+  FROM sale_return_items ri
+  JOIN sale_returns r ON ri.return_id = r.id
+  JOIN sale_items si ON ri.sale_item_id = si.id
+  WHERE r.created_at >= $1 AND r.created_at <= $2
+  GROUP BY si.tax_rate, si.tax_inclusive
+)
+SELECT
+  COALESCE(tr.tax_rate, rt.tax_rate)::numeric as tax_rate,
+  COALESCE(tr.tax_inclusive, rt.tax_inclusive)::boolean as tax_inclusive,
+  COALESCE(tr.transaction_count, 0)::bigint as transaction_count,
+  COALESCE(tr.taxable_amount, 0)::numeric as taxable_amount,
+  COALESCE(tr.tax_amount, 0)::numeric as tax_amount,
+  COALESCE(rt.tax_refunded, 0)::numeric as tax_refunded,
+  (COALESCE(tr.tax_amount, 0) - COALESCE(rt.tax_refunded, 0))::numeric as net_tax
+FROM tax_by_rate tr
+FULL OUTER JOIN refunded_tax_by_rate rt
+  ON tr.tax_rate = rt.tax_rate AND tr.tax_inclusive = rt.tax_inclusive
+ORDER BY tax_rate DESC, tax_inclusive;
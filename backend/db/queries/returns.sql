@@ -0,0 +1,56 @@
+-- name: CreateSaleReturn :one
+INSERT INTO sale_returns (return_no, sale_id, user_id, refund_amount, refund_method, reason)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: CreateSaleReturnItem :one
+INSERT INTO sale_return_items (return_id, sale_item_id, product_id, qty, refund_amount)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetSaleReturnByID :one
+SELECT r.*, s.invoice_no, u.username as cashier_name
+FROM sale_returns r
+JOIN sales s ON r.sale_id = s.id
+LEFT JOIN users u ON r.user_id = u.id
+WHERE r.id = $1 LIMIT 1;
+
+-- name: ListSaleReturns :many
+SELECT r.*, s.invoice_no, u.username as cashier_name
+FROM sale_returns r
+JOIN sales s ON r.sale_id = s.id
+LEFT JOIN users u ON r.user_id = u.id
+ORDER BY r.created_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: ListSaleReturnsBySale :many
+SELECT r.*, s.invoice_no, u.username as cashier_name
+FROM sale_returns r
+JOIN sales s ON r.sale_id = s.id
+LEFT JOIN users u ON r.user_id = u.id
+WHERE r.sale_id = $1
+ORDER BY r.created_at;
+
+-- name: GetSaleReturnItems :many
+SELECT ri.*, p.name as product_name, p.sku, si.unit_name
+FROM sale_return_items ri
+JOIN products p ON ri.product_id = p.id
+JOIN sale_items si ON ri.sale_item_id = si.id
+WHERE ri.return_id = $1
+ORDER BY ri.id;
+
+-- name: GetReturnableSaleItems :many
+SELECT si.*,
+  COALESCE(SUM(ri.qty), 0)::numeric as returned_qty,
+  COALESCE(SUM(ri.refund_amount), 0)::numeric as refunded_amount,
+  COALESCE(p.qty_precision, 0)::smallint as qty_precision
+FROM sale_items si
+LEFT JOIN sale_return_items ri ON ri.sale_item_id = si.id
+LEFT JOIN products p ON si.product_id = p.id
+WHERE si.sale_id = $1
+GROUP BY si.id, p.qty_precision
+ORDER BY si.id;
+
+-- name: CountSaleReturnsBySale :one
+SELECT COUNT(*) FROM sale_returns
+WHERE sale_id = $1;
@@ -0,0 +1,15 @@
+-- name: CreateSaleIdempotencyKey :one
+INSERT INTO sale_idempotency_keys (user_id, idempotency_key, request_hash, sale_id)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, idempotency_key) DO NOTHING
+RETURNING *;
+
+-- name: DeleteSaleIdempotencyKeyBySale :exec
+-- Frees the key of a sale that failed before the customer could pay, so a
+-- retry with the same key starts a new sale.
+DELETE FROM sale_idempotency_keys
+WHERE sale_id = $1;
+
+-- name: GetSaleIdempotencyKey :one
+SELECT * FROM sale_idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2;
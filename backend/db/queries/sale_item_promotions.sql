@@ -0,0 +1,14 @@
+-- name: CreateSaleItemPromotion :one
+INSERT INTO sale_item_promotions (sale_id, sale_item_id, promotion_id, promotion_name, amount)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetSaleItemPromotionsBySaleID :many
+SELECT * FROM sale_item_promotions
+WHERE sale_id = $1
+ORDER BY id;
+
+-- name: GetSaleItemPromotionsBySaleIDs :many
+SELECT * FROM sale_item_promotions
+WHERE sale_id = ANY(sqlc.arg(sale_ids)::int[])
+ORDER BY sale_id, id;
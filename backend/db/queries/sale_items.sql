@@ -1,6 +1,6 @@
 -- name: CreateSaleItem :one
-INSERT INTO sale_items (sale_id, product_id, qty, price, discount, subtotal)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO sale_items (sale_id, product_id, qty, price, discount, subtotal, list_price, override_price, override_by, tax_rate_id, tax_rate, tax_inclusive, tax_amount, promotion_discount, voucher_discount, unit_id, unit_name, unit_factor)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
 RETURNING *;
 
 -- name: GetSaleItemsBySaleID :many
@@ -10,6 +10,14 @@ JOIN products p ON si.product_id = p.id
 WHERE si.sale_id = $1
 ORDER BY si.id;
 
+-- name: GetSaleItemsBySaleIDs :many
+-- Items of several sales at once, for building pages of sales.
+SELECT si.*, p.name as product_name, p.sku
+FROM sale_items si
+JOIN products p ON si.product_id = p.id
+WHERE si.sale_id = ANY(sqlc.arg(sale_ids)::int[])
+ORDER BY si.sale_id, si.id;
+
 -- name: GetSaleItemsByProductID :many
 SELECT si.*, s.invoice_no, s.created_at as sale_date
 FROM sale_items si
@@ -0,0 +1,14 @@
+-- name: CreateSalePayment :one
+INSERT INTO sale_payments (sale_id, method, amount)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetSalePaymentsBySaleID :many
+SELECT * FROM sale_payments
+WHERE sale_id = $1
+ORDER BY id;
+
+-- name: GetSalePaymentsBySaleIDs :many
+SELECT * FROM sale_payments
+WHERE sale_id = ANY(sqlc.arg(sale_ids)::int[])
+ORDER BY sale_id, id;
@@ -1,6 +1,6 @@
 -- name: CreateSale :one
-INSERT INTO sales (invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO sales (invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method, subtotal_amount, tax_amount, voucher_code, voucher_discount, customer_id, points_earned, points_redeemed, shift_id, rounding_amount, payment_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 RETURNING *;
 
 -- name: GetSaleByID :one
@@ -15,12 +15,88 @@ FROM sales s
 LEFT JOIN users u ON s.user_id = u.id
 WHERE s.invoice_no = $1 LIMIT 1;
 
+-- name: GetSaleForUpdate :one
+SELECT * FROM sales
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: CountSales :one
+-- Counts the sales ListSales pages through, ignoring the cursor.
+SELECT COUNT(*)
+FROM sales s
+WHERE (sqlc.narg(created_from)::timestamptz IS NULL OR s.created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR s.created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(cashier_id)::int IS NULL OR s.user_id = sqlc.narg(cashier_id))
+  AND (sqlc.narg(payment_method)::text IS NULL OR EXISTS (
+    SELECT 1 FROM sale_payments p
+    WHERE p.sale_id = s.id AND lower(p.method) = lower(sqlc.narg(payment_method))
+  ))
+  AND (sqlc.narg(invoice_prefix)::text IS NULL OR s.invoice_no LIKE sqlc.narg(invoice_prefix) || '%')
+  AND (sqlc.narg(min_total)::numeric IS NULL OR s.total_amount >= sqlc.narg(min_total))
+  AND (sqlc.narg(max_total)::numeric IS NULL OR s.total_amount <= sqlc.narg(max_total))
+  AND (sqlc.narg(product_id)::int IS NULL OR EXISTS (
+    SELECT 1 FROM sale_items i
+    WHERE i.sale_id = s.id AND i.product_id = sqlc.narg(product_id)
+  ));
+
 -- name: ListSales :many
+-- Sales newest first, filtered by whichever arguments are not null. Pages
+-- are keyed on (created_at, id), so sales inserted while paging never shift
+-- or repeat rows; cursor_created_at and cursor_id are the last row seen.
 SELECT s.*, u.username as cashier_name
 FROM sales s
 LEFT JOIN users u ON s.user_id = u.id
-ORDER BY s.created_at DESC
-LIMIT $1 OFFSET $2;
+WHERE (sqlc.narg(created_from)::timestamptz IS NULL OR s.created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR s.created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(cashier_id)::int IS NULL OR s.user_id = sqlc.narg(cashier_id))
+  AND (sqlc.narg(payment_method)::text IS NULL OR EXISTS (
+    SELECT 1 FROM sale_payments p
+    WHERE p.sale_id = s.id AND lower(p.method) = lower(sqlc.narg(payment_method))
+  ))
+  AND (sqlc.narg(invoice_prefix)::text IS NULL OR s.invoice_no LIKE sqlc.narg(invoice_prefix) || '%')
+  AND (sqlc.narg(min_total)::numeric IS NULL OR s.total_amount >= sqlc.narg(min_total))
+  AND (sqlc.narg(max_total)::numeric IS NULL OR s.total_amount <= sqlc.narg(max_total))
+  AND (sqlc.narg(product_id)::int IS NULL OR EXISTS (
+    SELECT 1 FROM sale_items i
+    WHERE i.sale_id = s.id AND i.product_id = sqlc.narg(product_id)
+  ))
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (s.created_at, s.id) < (sqlc.narg(cursor_created_at), sqlc.narg(cursor_id)::int))
+ORDER BY s.created_at DESC, s.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ListSaleJournal :many
+-- One row per sale item, oldest first, for the sales export. Sales awaiting
+-- payment are left out; otherwise the filters match ListSales. Rows are read
+-- in batches keyed on (created_at, sale_id, item_id), with the cursor
+-- arguments taken from the last row of a batch.
+SELECT s.id as sale_id, s.invoice_no, s.created_at, s.voided_at, u.username as cashier_name,
+  si.id as item_id, p.name as product_name, p.sku, si.qty,
+  COALESCE(si.unit_name, p.unit) as unit, si.price, si.discount,
+  si.promotion_discount, si.voucher_discount, si.tax_amount, si.subtotal
+FROM sales s
+JOIN sale_items si ON si.sale_id = s.id
+JOIN products p ON si.product_id = p.id
+LEFT JOIN users u ON s.user_id = u.id
+WHERE s.payment_status <> 'pending'
+  AND (sqlc.narg(created_from)::timestamptz IS NULL OR s.created_at >= sqlc.narg(created_from))
+  AND (sqlc.narg(created_to)::timestamptz IS NULL OR s.created_at < sqlc.narg(created_to))
+  AND (sqlc.narg(cashier_id)::int IS NULL OR s.user_id = sqlc.narg(cashier_id))
+  AND (sqlc.narg(payment_method)::text IS NULL OR EXISTS (
+    SELECT 1 FROM sale_payments p
+    WHERE p.sale_id = s.id AND lower(p.method) = lower(sqlc.narg(payment_method))
+  ))
+  AND (sqlc.narg(invoice_prefix)::text IS NULL OR s.invoice_no LIKE sqlc.narg(invoice_prefix) || '%')
+  AND (sqlc.narg(min_total)::numeric IS NULL OR s.total_amount >= sqlc.narg(min_total))
+  AND (sqlc.narg(max_total)::numeric IS NULL OR s.total_amount <= sqlc.narg(max_total))
+  AND (sqlc.narg(product_id)::int IS NULL OR EXISTS (
+    SELECT 1 FROM sale_items i
+    WHERE i.sale_id = s.id AND i.product_id = sqlc.narg(product_id)
+  ))
+  AND (sqlc.narg(cursor_created_at)::timestamptz IS NULL
+    OR (s.created_at, s.id, si.id) > (sqlc.narg(cursor_created_at), sqlc.narg(cursor_sale_id)::int, sqlc.narg(cursor_item_id)::int))
+ORDER BY s.created_at, s.id, si.id
+LIMIT sqlc.arg('limit');
 
 -- name: ListSalesByDateRange :many
 SELECT s.*, u.username as cashier_name
@@ -32,8 +108,39 @@ ORDER BY s.created_at DESC;
 -- name: GetSalesStats :one
 SELECT 
   COUNT(*) as total_sales,
-  COALESCE(SUM(total_amount), 0) as total_revenue,
-  COALESCE(AVG(total_amount), 0) as avg_sale_amount
+  (COALESCE(SUM(total_amount), 0) - (
+    SELECT COALESCE(SUM(r.refund_amount), 0) FROM sale_returns r
+    WHERE r.created_at >= $1 AND r.created_at <= $2
+  ))::numeric as total_revenue,
+  COALESCE(AVG(total_amount), 0) as avg_sale_amount,
+  COALESCE(SUM(total_amount), 0)::numeric as gross_revenue,
+  (
+    SELECT COALESCE(SUM(r.refund_amount), 0) FROM sale_returns r
+    WHERE r.created_at >= $1 AND r.created_at <= $2
+  )::numeric as total_refunds,
+  COALESCE(SUM(rounding_amount), 0)::numeric as total_rounding
 FROM sales
-WHERE created_at >= $1 AND created_at <= $2;
+WHERE created_at >= $1 AND created_at <= $2
+  AND voided_at IS NULL
+  AND payment_status = 'paid';
 
+-- name: VoidSale :one
+UPDATE sales
+SET voided_at = now(), voided_by = $2, void_reason = $3
+WHERE id = $1 AND voided_at IS NULL
+RETURNING *;
+
+-- name: CompletePendingSale :one
+-- Marks a sale awaiting payment as paid. Matches nothing once the sale has
+-- left pending, so a charge is only ever applied once.
+UPDATE sales
+SET payment_status = 'paid'
+WHERE id = $1 AND payment_status = 'pending'
+RETURNING *;
+
+-- name: FailPendingSale :one
+-- Voids a sale whose payment did not go through.
+UPDATE sales
+SET payment_status = 'failed', voided_at = now(), void_reason = $2
+WHERE id = $1 AND payment_status = 'pending'
+RETURNING *;
@@ -0,0 +1,99 @@
+-- name: CreateShift :one
+INSERT INTO shifts (user_id, opening_float, opening_note)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetShiftByID :one
+SELECT * FROM shifts
+WHERE id = $1 LIMIT 1;
+
+-- name: GetShiftForUpdate :one
+SELECT * FROM shifts
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: GetOpenShiftByUser :one
+SELECT * FROM shifts
+WHERE user_id = $1 AND closed_at IS NULL
+LIMIT 1;
+
+-- name: GetOpenShiftByUserForShare :one
+-- Held by a sale until it commits, so the shift cannot close while one of
+-- its sales is still being written.
+SELECT * FROM shifts
+WHERE user_id = $1 AND closed_at IS NULL
+LIMIT 1
+FOR SHARE;
+
+-- name: ListShifts :many
+SELECT * FROM shifts
+ORDER BY opened_at DESC
+LIMIT $1 OFFSET $2;
+
+-- name: CloseShift :one
+UPDATE shifts
+SET closed_at = now(),
+    expected_cash = sqlc.arg(expected_cash),
+    counted_cash = sqlc.arg(counted_cash),
+    cash_variance = sqlc.arg(counted_cash) - sqlc.arg(expected_cash),
+    closing_note = sqlc.arg(closing_note)
+WHERE id = sqlc.arg(id) AND closed_at IS NULL
+RETURNING *;
+
+-- name: CreateShiftCashMovement :one
+INSERT INTO shift_cash_movements (shift_id, type, amount, reason, user_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListShiftCashMovements :many
+SELECT * FROM shift_cash_movements
+WHERE shift_id = $1
+ORDER BY created_at, id;
+
+-- name: GetShiftPaymentsByMethod :many
+-- Tenders of the shift's paid sales that were not voided, net of change.
+SELECT sp.method, COALESCE(SUM(sp.amount), 0)::numeric as amount
+FROM sale_payments sp
+JOIN sales s ON s.id = sp.sale_id
+WHERE s.shift_id = $1 AND s.voided_at IS NULL AND s.payment_status = 'paid'
+GROUP BY sp.method
+ORDER BY sp.method;
+
+-- name: GetShiftRefundsByMethod :many
+-- Refunds the cashier paid out while the shift was open.
+SELECT COALESCE(r.refund_method, 'cash')::text as method, COALESCE(SUM(r.refund_amount), 0)::numeric as amount
+FROM sale_returns r
+WHERE r.user_id = sqlc.arg(user_id)
+  AND r.created_at >= sqlc.arg(opened_at)
+  AND r.created_at < sqlc.arg(closed_at)
+GROUP BY 1
+ORDER BY 1;
+
+-- name: GetShiftSalesSummary :one
+SELECT
+  COUNT(*) as sale_count,
+  COALESCE(SUM(total_amount), 0)::numeric as total_amount
+FROM sales
+WHERE shift_id = $1 AND voided_at IS NULL AND payment_status = 'paid';
+
+-- name: CreateShiftTender :one
+INSERT INTO shift_tenders (shift_id, method, expected, counted, variance)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListShiftTenders :many
+SELECT * FROM shift_tenders
+WHERE shift_id = $1
+ORDER BY method;
+
+-- name: GetShiftGiftCardSalesByMethod :many
+-- Gift card sales and reloads the cashier took payment for while the shift
+-- was open.
+SELECT t.payment_method::text as method, COALESCE(SUM(t.amount), 0)::numeric as amount
+FROM gift_card_transactions t
+WHERE t.user_id = sqlc.arg(user_id)
+  AND t.payment_method IS NOT NULL
+  AND t.created_at >= sqlc.arg(opened_at)
+  AND t.created_at < sqlc.arg(closed_at)
+GROUP BY 1
+ORDER BY 1;
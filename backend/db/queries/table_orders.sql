@@ -0,0 +1,161 @@
+-- name: CreateDiningTable :one
+INSERT INTO dining_tables (name, seats)
+VALUES ($1, $2)
+RETURNING *;
+
+-- name: UpdateDiningTable :one
+UPDATE dining_tables
+SET name = $2, seats = $3, active = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: GetDiningTable :one
+SELECT * FROM dining_tables
+WHERE id = $1 LIMIT 1;
+
+-- name: ListDiningTables :many
+-- Every table with its open order, if any.
+SELECT t.*, o.id as order_id, o.status as order_status, o.guests, o.opened_at
+FROM dining_tables t
+LEFT JOIN table_orders o ON o.table_id = t.id AND o.status IN ('open', 'bill_requested')
+ORDER BY t.name;
+
+-- name: GetOpenTableOrderByTable :one
+SELECT * FROM table_orders
+WHERE table_id = $1 AND status IN ('open', 'bill_requested')
+LIMIT 1;
+
+-- name: CreateTableOrder :one
+INSERT INTO table_orders (table_id, user_id, guests, note)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetTableOrder :one
+SELECT o.*, t.name as table_name
+FROM table_orders o
+JOIN dining_tables t ON o.table_id = t.id
+WHERE o.id = $1 LIMIT 1;
+
+-- name: GetTableOrderForUpdate :one
+SELECT * FROM table_orders
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ReviseTableOrder :exec
+-- Records a change to the order's items. Changing them after the bill was
+-- requested reopens the order.
+UPDATE table_orders
+SET revision = revision + 1, status = 'open'
+WHERE id = $1;
+
+-- name: RequestTableBill :one
+UPDATE table_orders
+SET status = 'bill_requested'
+WHERE id = $1
+RETURNING *;
+
+-- name: CancelTableOrder :one
+UPDATE table_orders
+SET status = 'cancelled', closed_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SettleTableOrder :one
+-- Closes the order with the sale that paid for it. Matches nothing when the
+-- order is no longer open or its items changed since revision was read.
+UPDATE table_orders
+SET status = 'settled', sale_id = $2, closed_at = now()
+WHERE id = $1 AND revision = $3 AND status IN ('open', 'bill_requested') AND sale_id IS NULL
+RETURNING *;
+
+-- name: HoldTableOrderForPayment :one
+-- Links the order to a sale still awaiting a QRIS or e-wallet payment. The
+-- order stays bill_requested, and locked against changes, until the payment
+-- settles or releases it. Matches nothing under the same conditions as
+-- SettleTableOrder.
+UPDATE table_orders
+SET status = 'bill_requested', sale_id = $2
+WHERE id = $1 AND revision = $3 AND status IN ('open', 'bill_requested') AND sale_id IS NULL
+RETURNING *;
+
+-- name: SettleTableOrderBySale :exec
+-- Closes the order held for a sale once its payment has gone through.
+UPDATE table_orders
+SET status = 'settled', closed_at = now()
+WHERE sale_id = $1 AND status = 'bill_requested';
+
+-- name: ReleaseTableOrderSale :exec
+-- Frees the order held for a sale whose payment failed, so it can be
+-- settled again.
+UPDATE table_orders
+SET sale_id = NULL
+WHERE sale_id = $1 AND status = 'bill_requested';
+
+-- name: CreateTableOrderItem :one
+INSERT INTO table_order_items (order_id, product_id, qty, unit_id, unit_name, note)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetTableOrderItem :one
+SELECT * FROM table_order_items
+WHERE id = $1 AND order_id = $2 LIMIT 1;
+
+-- name: DeleteTableOrderItem :exec
+DELETE FROM table_order_items
+WHERE id = $1;
+
+-- name: ListTableOrderItems :many
+SELECT i.*, p.name as product_name
+FROM table_order_items i
+JOIN products p ON i.product_id = p.id
+WHERE i.order_id = $1
+ORDER BY i.id;
+
+-- name: SendTableOrderItems :execrows
+-- Puts every item of the order not yet sent to the kitchen on the ticket.
+UPDATE table_order_items
+SET ticket_id = $2
+WHERE order_id = $1 AND ticket_id IS NULL;
+
+-- name: CreateKitchenTicket :one
+INSERT INTO kitchen_tickets (order_id, ticket_no, user_id)
+VALUES ($1, (SELECT COUNT(*) + 1 FROM kitchen_tickets k WHERE k.order_id = $1), $2)
+RETURNING *;
+
+-- name: GetKitchenTicket :one
+SELECT k.*, t.name as table_name
+FROM kitchen_tickets k
+JOIN table_orders o ON k.order_id = o.id
+JOIN dining_tables t ON o.table_id = t.id
+WHERE k.id = $1 LIMIT 1;
+
+-- name: ListKitchenTicketsByStatus :many
+-- The kitchen's queue, oldest first.
+SELECT k.*, t.name as table_name
+FROM kitchen_tickets k
+JOIN table_orders o ON k.order_id = o.id
+JOIN dining_tables t ON o.table_id = t.id
+WHERE k.status = $1
+ORDER BY k.created_at, k.id
+LIMIT $2;
+
+-- name: ListKitchenTicketsByOrder :many
+SELECT k.*, t.name as table_name
+FROM kitchen_tickets k
+JOIN table_orders o ON k.order_id = o.id
+JOIN dining_tables t ON o.table_id = t.id
+WHERE k.order_id = $1
+ORDER BY k.ticket_no;
+
+-- name: ListKitchenTicketItems :many
+SELECT i.*, p.name as product_name
+FROM table_order_items i
+JOIN products p ON i.product_id = p.id
+WHERE i.ticket_id = ANY(sqlc.arg(ticket_ids)::int[])
+ORDER BY i.ticket_id, i.id;
+
+-- name: CompleteKitchenTicket :one
+UPDATE kitchen_tickets
+SET status = 'done', done_at = now()
+WHERE id = $1 AND status = 'pending'
+RETURNING *;
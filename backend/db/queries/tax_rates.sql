@@ -0,0 +1,29 @@
+-- name: CreateTaxRate :one
+INSERT INTO tax_rates (name, rate, inclusive)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetTaxRateByID :one
+SELECT * FROM tax_rates
+WHERE id = $1 LIMIT 1;
+
+-- name: ListTaxRates :many
+SELECT * FROM tax_rates
+ORDER BY name;
+
+-- name: GetProductTaxRate :one
+-- The product's own rate wins over its category's; exempt products have none.
+SELECT t.*
+FROM products p
+LEFT JOIN categories c ON p.category_id = c.id
+JOIN tax_rates t ON t.id = COALESCE(p.tax_rate_id, c.tax_rate_id)
+WHERE p.id = $1 AND NOT p.tax_exempt;
+
+-- name: UpdateTaxRate :one
+UPDATE tax_rates
+SET name = $2, rate = $3, inclusive = $4
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteTaxRate :exec
+DELETE FROM tax_rates WHERE id = $1;
@@ -0,0 +1,56 @@
+-- name: CreateVoucher :one
+INSERT INTO vouchers (code, description, type, value, min_spend, usage_limit, per_customer_limit, starts_at, expires_at, active)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetVoucherByID :one
+SELECT * FROM vouchers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetVoucherByCode :one
+SELECT * FROM vouchers
+WHERE code = $1 LIMIT 1;
+
+-- name: GetVoucherByCodeForUpdate :one
+-- Locks the voucher so concurrent checkouts cannot both take its last use.
+SELECT * FROM vouchers
+WHERE code = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListVouchers :many
+SELECT * FROM vouchers
+ORDER BY created_at DESC, id DESC;
+
+-- name: UpdateVoucher :one
+UPDATE vouchers
+SET code = $2, description = $3, type = $4, value = $5, min_spend = $6, usage_limit = $7,
+    per_customer_limit = $8, starts_at = $9, expires_at = $10, active = $11
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteVoucher :exec
+DELETE FROM vouchers WHERE id = $1;
+
+-- name: IncrementVoucherUsage :execrows
+UPDATE vouchers
+SET used_count = used_count + 1
+WHERE id = $1 AND (usage_limit IS NULL OR used_count < usage_limit);
+
+-- name: CreateVoucherRedemption :one
+INSERT INTO voucher_redemptions (voucher_id, sale_id, customer_ref, amount)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: CountVoucherRedemptionsByCustomer :one
+SELECT COUNT(*) FROM voucher_redemptions
+WHERE voucher_id = $1 AND customer_ref = $2;
+
+-- name: DeleteVoucherRedemptionBySale :one
+DELETE FROM voucher_redemptions
+WHERE sale_id = $1
+RETURNING *;
+
+-- name: DecrementVoucherUsage :exec
+UPDATE vouchers
+SET used_count = used_count - 1
+WHERE id = $1 AND used_count > 0;
@@ -109,3 +109,26 @@ func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
 	return nil, errors.New("invalid token")
 }
 
+
+// VerifyAdmin checks the credentials of an admin who is approving an action
+// on someone else's session, such as a price override at a cashier's till.
+func (s *Service) VerifyAdmin(ctx context.Context, username, password string) (*UserInfo, error) {
+	user, err := s.queries.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, errors.New("invalid credentials")
+	}
+
+	if user.Role != "admin" {
+		return nil, errors.New("admin access required")
+	}
+
+	return &UserInfo{
+		ID:       user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+	}, nil
+}
@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"pos-system/internal/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Service struct {
@@ -16,19 +19,57 @@ func NewService(queries *db.Queries) *Service {
 }
 
 type CreateCategoryRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	TaxRateID *int32 `json:"tax_rate_id"`
 }
 
 type UpdateCategoryRequest struct {
-	Name string `json:"name" binding:"required"`
+	Name      string `json:"name" binding:"required"`
+	TaxRateID *int32 `json:"tax_rate_id"`
 }
 
+// CategoryResponse carries the category's default tax rate, which applies to
+// its products that have no rate of their own.
 type CategoryResponse struct {
 	ID        int32  `json:"id"`
 	Name      string `json:"name"`
+	TaxRateID *int32 `json:"tax_rate_id"`
 	CreatedAt string `json:"created_at"`
 }
 
+// taxRateID validates an optional tax rate assignment
+func (s *Service) taxRateID(ctx context.Context, id *int32) (pgtype.Int4, error) {
+	if id == nil {
+		return pgtype.Int4{}, nil
+	}
+	if _, err := s.queries.GetTaxRateByID(ctx, *id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.Int4{}, errors.New("tax rate not found")
+		}
+		return pgtype.Int4{}, err
+	}
+	return pgtype.Int4{Int32: *id, Valid: true}, nil
+}
+
+func toResponse(category db.Category) CategoryResponse {
+	var createdAt string
+	if category.CreatedAt.Valid {
+		createdAt = category.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	var taxRateID *int32
+	if category.TaxRateID.Valid {
+		taxRateID = &category.TaxRateID.Int32
+	}
+
+	return CategoryResponse{
+		ID:        category.ID,
+		Name:      category.Name,
+		TaxRateID: taxRateID,
+		CreatedAt: createdAt,
+	}
+}
+
 func (s *Service) List(ctx context.Context) ([]CategoryResponse, error) {
 	categories, err := s.queries.ListCategories(ctx)
 	if err != nil {
@@ -37,15 +78,7 @@ func (s *Service) List(ctx context.Context) ([]CategoryResponse, error) {
 
 	result := make([]CategoryResponse, len(categories))
 	for i, cat := range categories {
-		var createdAt string
-		if cat.CreatedAt.Valid {
-			createdAt = cat.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
-		}
-		result[i] = CategoryResponse{
-			ID:        cat.ID,
-			Name:      cat.Name,
-			CreatedAt: createdAt,
-		}
+		result[i] = toResponse(cat)
 	}
 
 	return result, nil
@@ -57,21 +90,21 @@ func (s *Service) Create(ctx context.Context, req CreateCategoryRequest) (*Categ
 		return nil, errors.New("category name is required")
 	}
 
-	category, err := s.queries.CreateCategory(ctx, req.Name)
+	taxRateID, err := s.taxRateID(ctx, req.TaxRateID)
 	if err != nil {
 		return nil, err
 	}
 
-	var createdAt string
-	if category.CreatedAt.Valid {
-		createdAt = category.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	category, err := s.queries.CreateCategory(ctx, db.CreateCategoryParams{
+		Name:      req.Name,
+		TaxRateID: taxRateID,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &CategoryResponse{
-		ID:        category.ID,
-		Name:      category.Name,
-		CreatedAt: createdAt,
-	}, nil
+	result := toResponse(category)
+	return &result, nil
 }
 
 func (s *Service) Update(ctx context.Context, id int32, req UpdateCategoryRequest) (*CategoryResponse, error) {
@@ -89,24 +122,22 @@ func (s *Service) Update(ctx context.Context, id int32, req UpdateCategoryReques
 		return nil, err
 	}
 
-	category, err := s.queries.UpdateCategory(ctx, db.UpdateCategoryParams{
-		ID:   id,
-		Name: req.Name,
-	})
+	taxRateID, err := s.taxRateID(ctx, req.TaxRateID)
 	if err != nil {
 		return nil, err
 	}
 
-	var createdAt string
-	if category.CreatedAt.Valid {
-		createdAt = category.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	category, err := s.queries.UpdateCategory(ctx, db.UpdateCategoryParams{
+		ID:        id,
+		Name:      req.Name,
+		TaxRateID: taxRateID,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &CategoryResponse{
-		ID:        category.ID,
-		Name:      category.Name,
-		CreatedAt: createdAt,
-	}, nil
+	result := toResponse(category)
+	return &result, nil
 }
 
 func (s *Service) Delete(ctx context.Context, id int32) error {
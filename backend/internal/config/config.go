@@ -7,32 +7,98 @@ import (
 )
 
 type Config struct {
-	DBHost         string
-	DBPort         string
-	DBUser         string
-	DBPassword     string
-	DBName         string
-	DBSchema       string
-	DBSSLMode      string // New field for flexible SSL configuration
-	JWTSecret      string
-	ServerPort     string
-	ServerHost     string
-	Environment    string
+	DBHost             string
+	DBPort             string
+	DBUser             string
+	DBPassword         string
+	DBName             string
+	DBSchema           string
+	DBSSLMode          string // New field for flexible SSL configuration
+	JWTSecret          string
+	ServerPort         string
+	ServerHost         string
+	Environment        string
+	HeldCartTTLMinutes int
+	// Invoice numbering: PREFIX-STORE-DATE-COUNTER
+	InvoicePrefix       string
+	InvoiceStoreCode    string
+	InvoiceReset        string // daily, monthly, yearly or never
+	InvoiceCounterWidth int
+	InvoiceTimezone     string
+	// Loyalty points, as decimal amounts: spend per point earned and the
+	// value of one point when redeemed. 0 turns either off.
+	LoyaltySpendPerPoint string
+	LoyaltyPointValue    string
+	// Cash rounding: the increment cash payments are rounded to, as a
+	// decimal amount (0 turns it off), and nearest, up or down
+	RoundingIncrement string
+	RoundingMode      string
+	// QRIS and e-wallet payments: mock, qris or empty for none, the secret
+	// callbacks are signed with and how long a charge may stay unpaid
+	PaymentProvider    string
+	PaymentCallbackKey string
+	PaymentChargeTTL   int // minutes
+	QRISBaseURL        string
+	QRISServerKey      string
+	// Price-embedded EAN-13 labels from the store's scales: the prefixes
+	// that mark them, how many digits hold the product's SKU and whether
+	// the rest is the weight or the price
+	ScaleBarcodePrefixes   string // comma separated
+	ScaleBarcodeItemDigits int
+	ScaleBarcodeMode       string // weight or price
+	// Receipt header and footer, and the thermal printer it is laid out for
+	ReceiptStoreName    string
+	ReceiptStoreAddress string
+	ReceiptStorePhone   string
+	ReceiptFooter       string
+	ReceiptPaperWidth   int // 58 or 80 (mm)
+	ReceiptCodePage     string
+	// A4 PDF invoices reuse the receipt store name, address and phone
+	InvoiceStoreEmail string
+	InvoiceTaxID      string // NPWP
+	InvoiceTerms      string
 }
 
 func Load() *Config {
 	return &Config{
-		DBHost:      getEnv("DB_HOST", "localhost"),
-		DBPort:      getEnv("DB_PORT", "5432"),
-		DBUser:      getEnv("DB_USER", "postgres"),
-		DBPassword:  getEnv("DB_PASS", "postgres"),
-		DBName:      getEnv("DB_NAME", "pos_db"),
-		DBSchema:    getEnv("DB_SCHEMA", "public"),
-		DBSSLMode:   getEnv("DB_SSL_MODE", ""), // Default to empty to allow fallback logic
-		JWTSecret:   getEnv("JWT_SECRET", "change_this_secret_key_in_production"),
-		ServerPort:  getEnv("SERVER_PORT", "8080"),
-		ServerHost:  getEnv("SERVER_HOST", "0.0.0.0"),
-		Environment: getEnv("ENVIRONMENT", "development"),
+		DBHost:                 getEnv("DB_HOST", "localhost"),
+		DBPort:                 getEnv("DB_PORT", "5432"),
+		DBUser:                 getEnv("DB_USER", "postgres"),
+		DBPassword:             getEnv("DB_PASS", "postgres"),
+		DBName:                 getEnv("DB_NAME", "pos_db"),
+		DBSchema:               getEnv("DB_SCHEMA", "public"),
+		DBSSLMode:              getEnv("DB_SSL_MODE", ""), // Default to empty to allow fallback logic
+		JWTSecret:              getEnv("JWT_SECRET", "change_this_secret_key_in_production"),
+		ServerPort:             getEnv("SERVER_PORT", "8080"),
+		ServerHost:             getEnv("SERVER_HOST", "0.0.0.0"),
+		Environment:            getEnv("ENVIRONMENT", "development"),
+		HeldCartTTLMinutes:     getEnvAsInt("HELD_CART_TTL_MINUTES", 240),
+		InvoicePrefix:          getEnv("INVOICE_PREFIX", "INV"),
+		InvoiceStoreCode:       getEnv("INVOICE_STORE_CODE", ""),
+		InvoiceReset:           getEnv("INVOICE_RESET", "daily"),
+		InvoiceCounterWidth:    getEnvAsInt("INVOICE_COUNTER_WIDTH", 5),
+		InvoiceTimezone:        getEnv("INVOICE_TIMEZONE", "Local"),
+		LoyaltySpendPerPoint:   getEnv("LOYALTY_SPEND_PER_POINT", "10000"),
+		LoyaltyPointValue:      getEnv("LOYALTY_POINT_VALUE", "1"),
+		RoundingIncrement:      getEnv("CASH_ROUNDING_INCREMENT", "0"),
+		RoundingMode:           getEnv("CASH_ROUNDING_MODE", "nearest"),
+		PaymentProvider:        getEnv("PAYMENT_PROVIDER", ""),
+		PaymentCallbackKey:     getEnv("PAYMENT_CALLBACK_SECRET", ""),
+		PaymentChargeTTL:       getEnvAsInt("PAYMENT_CHARGE_TTL_MINUTES", 15),
+		QRISBaseURL:            getEnv("QRIS_BASE_URL", ""),
+		QRISServerKey:          getEnv("QRIS_SERVER_KEY", ""),
+		ScaleBarcodePrefixes:   getEnv("SCALE_BARCODE_PREFIXES", "20,21,22,23,24,25,26,27,28,29"),
+		ScaleBarcodeItemDigits: getEnvAsInt("SCALE_BARCODE_ITEM_DIGITS", 5),
+		ScaleBarcodeMode:       getEnv("SCALE_BARCODE_MODE", "weight"),
+		ReceiptStoreName:       getEnv("RECEIPT_STORE_NAME", "POS System"),
+		ReceiptStoreAddress:    getEnv("RECEIPT_STORE_ADDRESS", ""),
+		ReceiptStorePhone:      getEnv("RECEIPT_STORE_PHONE", ""),
+		ReceiptFooter:          getEnv("RECEIPT_FOOTER", "Terima kasih"),
+		ReceiptPaperWidth:      getEnvAsInt("RECEIPT_PAPER_WIDTH", 58),
+		ReceiptCodePage:        getEnv("RECEIPT_CODE_PAGE", "pc437"),
+		InvoiceStoreEmail:      getEnv("INVOICE_STORE_EMAIL", ""),
+		InvoiceTaxID:           getEnv("INVOICE_TAX_ID", ""),
+		InvoiceTerms:           getEnv("INVOICE_TERMS", ""),
 	}
 }
 
@@ -47,13 +113,13 @@ func (c *Config) GetDSN() string {
 			sslMode = "require"
 		}
 	}
-	
+
 	// Tambahkan search_path untuk custom schema
 	searchPath := ""
 	if c.DBSchema != "" && c.DBSchema != "public" {
 		searchPath = fmt.Sprintf("&search_path=%s", c.DBSchema)
 	}
-	
+
 	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s%s",
 		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName, sslMode, searchPath)
 }
@@ -77,4 +143,3 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
-
@@ -0,0 +1,327 @@
+package customer
+
+import (
+	"context"
+	"errors"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Service struct {
+	queries *db.Queries
+}
+
+func NewService(queries *db.Queries) *Service {
+	return &Service{queries: queries}
+}
+
+type CreateCustomerRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Phone string `json:"phone"`
+	Email string `json:"email"`
+}
+
+type UpdateCustomerRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Phone string `json:"phone"`
+	Email string `json:"email"`
+}
+
+type CustomerResponse struct {
+	ID        int32   `json:"id"`
+	Name      string  `json:"name"`
+	Phone     *string `json:"phone"`
+	Email     *string `json:"email"`
+	Points    int64   `json:"points"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+// PurchaseHistoryResponse is a customer's sales, newest first. VisitCount,
+// TotalSpent and LastVisit cover every sale that was not voided, not just
+// the page returned.
+type PurchaseHistoryResponse struct {
+	Customer   CustomerResponse   `json:"customer"`
+	VisitCount int64              `json:"visit_count"`
+	TotalSpent string             `json:"total_spent"`
+	LastVisit  *string            `json:"last_visit"`
+	Sales      []PurchaseResponse `json:"sales"`
+}
+
+type PurchaseResponse struct {
+	ID             int32                  `json:"id"`
+	InvoiceNo      string                 `json:"invoice_no"`
+	TotalAmount    string                 `json:"total_amount"`
+	PointsEarned   int64                  `json:"points_earned"`
+	PointsRedeemed int64                  `json:"points_redeemed"`
+	Voided         bool                   `json:"voided"`
+	CreatedAt      string                 `json:"created_at"`
+	Items          []PurchaseItemResponse `json:"items"`
+}
+
+type PurchaseItemResponse struct {
+	ProductID   int32             `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	Qty         quantity.Quantity `json:"qty"`
+	// Unit is the packaging unit the item was sold in, null for the base unit
+	Unit     *string `json:"unit"`
+	Price    string  `json:"price"`
+	Subtotal string  `json:"subtotal"`
+}
+
+// NormalizePhone drops the spaces, dashes, dots and brackets people type in
+// phone numbers so "0812-3456 789" and "08123456789" match. A leading + is
+// kept.
+func NormalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	var b strings.Builder
+	for i, r := range phone {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// numericToString formats a NUMERIC money column with two decimals
+func numericToString(n pgtype.Numeric) string {
+	amount, err := money.FromNumeric(n)
+	if err != nil {
+		return "0.00"
+	}
+	return amount.String()
+}
+
+// numericToQty reads a NUMERIC quantity column
+func numericToQty(n pgtype.Numeric) quantity.Quantity {
+	qty, err := quantity.FromNumeric(n)
+	if err != nil {
+		return 0
+	}
+	return qty
+}
+
+func optText(s string) pgtype.Text {
+	if s == "" {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: s, Valid: true}
+}
+
+func isDuplicatePhone(err error) bool {
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "duplicate key") && strings.Contains(errMsg, "customers_phone_key")
+}
+
+func toResponse(c db.Customer) CustomerResponse {
+	resp := CustomerResponse{
+		ID:     c.ID,
+		Name:   c.Name,
+		Points: c.Points,
+	}
+	if c.Phone.Valid {
+		resp.Phone = &c.Phone.String
+	}
+	if c.Email.Valid {
+		resp.Email = &c.Email.String
+	}
+	if c.CreatedAt.Valid {
+		resp.CreatedAt = c.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if c.UpdatedAt.Valid {
+		resp.UpdatedAt = c.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+func (s *Service) List(ctx context.Context, limit, offset int32) ([]CustomerResponse, error) {
+	customers, err := s.queries.ListCustomers(ctx, db.ListCustomersParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CustomerResponse, len(customers))
+	for i, c := range customers {
+		result[i] = toResponse(c)
+	}
+	return result, nil
+}
+
+// SearchByPhone returns customers whose phone number starts with phone.
+func (s *Service) SearchByPhone(ctx context.Context, phone string) ([]CustomerResponse, error) {
+	phone = NormalizePhone(phone)
+	if phone == "" {
+		return nil, errors.New("phone must contain digits")
+	}
+
+	customers, err := s.queries.SearchCustomersByPhone(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]CustomerResponse, len(customers))
+	for i, c := range customers {
+		result[i] = toResponse(c)
+	}
+	return result, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int32) (*CustomerResponse, error) {
+	c, err := s.queries.GetCustomerByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("customer not found")
+		}
+		return nil, err
+	}
+
+	result := toResponse(c)
+	return &result, nil
+}
+
+func (s *Service) Create(ctx context.Context, req CreateCustomerRequest) (*CustomerResponse, error) {
+	c, err := s.queries.CreateCustomer(ctx, db.CreateCustomerParams{
+		Name:  strings.TrimSpace(req.Name),
+		Phone: optText(NormalizePhone(req.Phone)),
+		Email: optText(strings.TrimSpace(req.Email)),
+	})
+	if err != nil {
+		if isDuplicatePhone(err) {
+			return nil, errors.New("customer phone already exists")
+		}
+		return nil, err
+	}
+
+	result := toResponse(c)
+	return &result, nil
+}
+
+func (s *Service) Update(ctx context.Context, id int32, req UpdateCustomerRequest) (*CustomerResponse, error) {
+	c, err := s.queries.UpdateCustomer(ctx, db.UpdateCustomerParams{
+		ID:    id,
+		Name:  strings.TrimSpace(req.Name),
+		Phone: optText(NormalizePhone(req.Phone)),
+		Email: optText(strings.TrimSpace(req.Email)),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("customer not found")
+		}
+		if isDuplicatePhone(err) {
+			return nil, errors.New("customer phone already exists")
+		}
+		return nil, err
+	}
+
+	result := toResponse(c)
+	return &result, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int32) error {
+	if _, err := s.queries.GetCustomerByID(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("customer not found")
+		}
+		return err
+	}
+
+	// Past sales keep their totals but lose the link (ON DELETE SET NULL)
+	return s.queries.DeleteCustomer(ctx, id)
+}
+
+// History returns a page of the customer's sales with what was bought in each.
+func (s *Service) History(ctx context.Context, id, limit, offset int32) (*PurchaseHistoryResponse, error) {
+	c, err := s.queries.GetCustomerByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("customer not found")
+		}
+		return nil, err
+	}
+
+	customerIDPg := pgtype.Int4{Int32: id, Valid: true}
+	summary, err := s.queries.GetCustomerSalesSummary(ctx, customerIDPg)
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.queries.ListCustomerSales(ctx, db.ListCustomerSalesParams{
+		CustomerID: customerIDPg,
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Items for the whole page in one query
+	ids := make([]int32, len(sales))
+	for i, sale := range sales {
+		ids[i] = sale.ID
+	}
+	items, err := s.queries.GetSaleItemsBySaleIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	itemsBySale := make(map[int32][]PurchaseItemResponse, len(sales))
+	for _, item := range items {
+		var unit *string
+		if item.UnitName.Valid {
+			unit = &item.UnitName.String
+		}
+		itemsBySale[item.SaleID.Int32] = append(itemsBySale[item.SaleID.Int32], PurchaseItemResponse{
+			ProductID:   item.ProductID.Int32,
+			ProductName: item.ProductName,
+			Qty:         numericToQty(item.Qty),
+			Unit:        unit,
+			Price:       numericToString(item.Price),
+			Subtotal:    numericToString(item.Subtotal),
+		})
+	}
+
+	purchases := make([]PurchaseResponse, len(sales))
+	for i, sale := range sales {
+		itemResponses := itemsBySale[sale.ID]
+		if itemResponses == nil {
+			itemResponses = []PurchaseItemResponse{}
+		}
+
+		var createdAt string
+		if sale.CreatedAt.Valid {
+			createdAt = sale.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		}
+
+		purchases[i] = PurchaseResponse{
+			ID:             sale.ID,
+			InvoiceNo:      sale.InvoiceNo,
+			TotalAmount:    numericToString(sale.TotalAmount),
+			PointsEarned:   sale.PointsEarned,
+			PointsRedeemed: sale.PointsRedeemed,
+			Voided:         sale.VoidedAt.Valid,
+			CreatedAt:      createdAt,
+			Items:          itemResponses,
+		}
+	}
+
+	var lastVisit *string
+	if summary.LastVisit.Valid {
+		v := summary.LastVisit.Time.Format("2006-01-02T15:04:05Z07:00")
+		lastVisit = &v
+	}
+
+	return &PurchaseHistoryResponse{
+		Customer:   toResponse(c),
+		VisitCount: summary.VisitCount,
+		TotalSpent: numericToString(summary.TotalSpent),
+		LastVisit:  lastVisit,
+		Sales:      purchases,
+	}, nil
+}
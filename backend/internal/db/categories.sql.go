@@ -7,18 +7,30 @@ package db
 
 import (
 	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createCategory = `-- name: CreateCategory :one
-INSERT INTO categories (name)
-VALUES ($1)
-RETURNING id, name, created_at
+INSERT INTO categories (name, tax_rate_id)
+VALUES ($1, $2)
+RETURNING id, name, created_at, tax_rate_id
 `
 
-func (q *Queries) CreateCategory(ctx context.Context, name string) (Category, error) {
-	row := q.db.QueryRow(ctx, createCategory, name)
+type CreateCategoryParams struct {
+	Name      string      `json:"name"`
+	TaxRateID pgtype.Int4 `json:"tax_rate_id"`
+}
+
+func (q *Queries) CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error) {
+	row := q.db.QueryRow(ctx, createCategory, arg.Name, arg.TaxRateID)
 	var i Category
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.TaxRateID,
+	)
 	return i, err
 }
 
@@ -32,19 +44,24 @@ func (q *Queries) DeleteCategory(ctx context.Context, id int32) error {
 }
 
 const getCategoryByID = `-- name: GetCategoryByID :one
-SELECT id, name, created_at FROM categories
+SELECT id, name, created_at, tax_rate_id FROM categories
 WHERE id = $1 LIMIT 1
 `
 
 func (q *Queries) GetCategoryByID(ctx context.Context, id int32) (Category, error) {
 	row := q.db.QueryRow(ctx, getCategoryByID, id)
 	var i Category
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.TaxRateID,
+	)
 	return i, err
 }
 
 const listCategories = `-- name: ListCategories :many
-SELECT id, name, created_at FROM categories
+SELECT id, name, created_at, tax_rate_id FROM categories
 ORDER BY name
 `
 
@@ -57,7 +74,12 @@ func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
 	items := []Category{}
 	for rows.Next() {
 		var i Category
-		if err := rows.Scan(&i.ID, &i.Name, &i.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.TaxRateID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -70,19 +92,25 @@ func (q *Queries) ListCategories(ctx context.Context) ([]Category, error) {
 
 const updateCategory = `-- name: UpdateCategory :one
 UPDATE categories
-SET name = $2
+SET name = $2, tax_rate_id = $3
 WHERE id = $1
-RETURNING id, name, created_at
+RETURNING id, name, created_at, tax_rate_id
 `
 
 type UpdateCategoryParams struct {
-	ID   int32  `json:"id"`
-	Name string `json:"name"`
+	ID        int32       `json:"id"`
+	Name      string      `json:"name"`
+	TaxRateID pgtype.Int4 `json:"tax_rate_id"`
 }
 
 func (q *Queries) UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error) {
-	row := q.db.QueryRow(ctx, updateCategory, arg.ID, arg.Name)
+	row := q.db.QueryRow(ctx, updateCategory, arg.ID, arg.Name, arg.TaxRateID)
 	var i Category
-	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.TaxRateID,
+	)
 	return i, err
 }
@@ -19,8 +19,8 @@ RETURNING id, product_id, qty, updated_at
 `
 
 type AdjustInventoryQtyParams struct {
-	ProductID pgtype.Int4 `json:"product_id"`
-	Qty       int32       `json:"qty"`
+	ProductID pgtype.Int4    `json:"product_id"`
+	Qty       pgtype.Numeric `json:"qty"`
 }
 
 func (q *Queries) AdjustInventoryQty(ctx context.Context, arg AdjustInventoryQtyParams) (Inventory, error) {
@@ -42,8 +42,8 @@ RETURNING id, product_id, qty, updated_at
 `
 
 type CreateInventoryParams struct {
-	ProductID pgtype.Int4 `json:"product_id"`
-	Qty       int32       `json:"qty"`
+	ProductID pgtype.Int4    `json:"product_id"`
+	Qty       pgtype.Numeric `json:"qty"`
 }
 
 func (q *Queries) CreateInventory(ctx context.Context, arg CreateInventoryParams) (Inventory, error) {
@@ -75,6 +75,26 @@ func (q *Queries) GetInventoryByProduct(ctx context.Context, productID pgtype.In
 	return i, err
 }
 
+const getInventoryByProductForUpdate = `-- name: GetInventoryByProductForUpdate :one
+SELECT id, product_id, qty, updated_at FROM inventory
+WHERE product_id = $1 LIMIT 1
+FOR UPDATE
+`
+
+// Locks the row until the transaction ends. Lock several products in
+// ascending product_id order so concurrent sales cannot deadlock.
+func (q *Queries) GetInventoryByProductForUpdate(ctx context.Context, productID pgtype.Int4) (Inventory, error) {
+	row := q.db.QueryRow(ctx, getInventoryByProductForUpdate, productID)
+	var i Inventory
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.Qty,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getLowStockItems = `-- name: GetLowStockItems :many
 SELECT i.id, i.product_id, i.qty, i.updated_at, p.name as product_name, p.sku, p.unit
 FROM inventory i
@@ -86,14 +106,14 @@ ORDER BY i.qty ASC
 type GetLowStockItemsRow struct {
 	ID          int32              `json:"id"`
 	ProductID   pgtype.Int4        `json:"product_id"`
-	Qty         int32              `json:"qty"`
+	Qty         pgtype.Numeric     `json:"qty"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 	ProductName string             `json:"product_name"`
 	Sku         pgtype.Text        `json:"sku"`
 	Unit        pgtype.Text        `json:"unit"`
 }
 
-func (q *Queries) GetLowStockItems(ctx context.Context, qty int32) ([]GetLowStockItemsRow, error) {
+func (q *Queries) GetLowStockItems(ctx context.Context, qty pgtype.Numeric) ([]GetLowStockItemsRow, error) {
 	rows, err := q.db.Query(ctx, getLowStockItems, qty)
 	if err != nil {
 		return nil, err
@@ -131,7 +151,7 @@ ORDER BY p.name
 type ListInventoryRow struct {
 	ID          int32              `json:"id"`
 	ProductID   pgtype.Int4        `json:"product_id"`
-	Qty         int32              `json:"qty"`
+	Qty         pgtype.Numeric     `json:"qty"`
 	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
 	ProductName string             `json:"product_name"`
 	Sku         pgtype.Text        `json:"sku"`
@@ -174,8 +194,8 @@ RETURNING id, product_id, qty, updated_at
 `
 
 type UpdateInventoryQtyParams struct {
-	ProductID pgtype.Int4 `json:"product_id"`
-	Qty       int32       `json:"qty"`
+	ProductID pgtype.Int4    `json:"product_id"`
+	Qty       pgtype.Numeric `json:"qty"`
 }
 
 func (q *Queries) UpdateInventoryQty(ctx context.Context, arg UpdateInventoryQtyParams) (Inventory, error) {
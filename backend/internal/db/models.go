@@ -12,45 +12,304 @@ type Category struct {
 	ID        int32              `json:"id"`
 	Name      string             `json:"name"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	TaxRateID pgtype.Int4        `json:"tax_rate_id"`
+}
+
+type Customer struct {
+	ID        int32              `json:"id"`
+	Name      string             `json:"name"`
+	Phone     pgtype.Text        `json:"phone"`
+	Email     pgtype.Text        `json:"email"`
+	Points    int64              `json:"points"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CustomerPointEntry struct {
+	ID         int32              `json:"id"`
+	CustomerID int32              `json:"customer_id"`
+	SaleID     pgtype.Int4        `json:"sale_id"`
+	Type       string             `json:"type"`
+	Points     int64              `json:"points"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+}
+
+type DiningTable struct {
+	ID        int32              `json:"id"`
+	Name      string             `json:"name"`
+	Seats     pgtype.Int4        `json:"seats"`
+	Active    bool               `json:"active"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type GiftCard struct {
+	ID         int32              `json:"id"`
+	CardNumber string             `json:"card_number"`
+	Type       string             `json:"type"`
+	Balance    pgtype.Numeric     `json:"balance"`
+	CustomerID pgtype.Int4        `json:"customer_id"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+	Active     bool               `json:"active"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt  pgtype.Timestamptz `json:"updated_at"`
+}
+
+type GiftCardTransaction struct {
+	ID            int32              `json:"id"`
+	GiftCardID    int32              `json:"gift_card_id"`
+	Type          string             `json:"type"`
+	Amount        pgtype.Numeric     `json:"amount"`
+	BalanceAfter  pgtype.Numeric     `json:"balance_after"`
+	SaleID        pgtype.Int4        `json:"sale_id"`
+	ReturnID      pgtype.Int4        `json:"return_id"`
+	PaymentMethod pgtype.Text        `json:"payment_method"`
+	UserID        pgtype.Int4        `json:"user_id"`
+	Note          pgtype.Text        `json:"note"`
+	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+}
+
+type HeldCart struct {
+	ID        int32              `json:"id"`
+	UserID    int32              `json:"user_id"`
+	Label     pgtype.Text        `json:"label"`
+	Items     []byte             `json:"items"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	ExpiresAt pgtype.Timestamptz `json:"expires_at"`
 }
 
 type Inventory struct {
 	ID        int32              `json:"id"`
 	ProductID pgtype.Int4        `json:"product_id"`
-	Qty       int32              `json:"qty"`
+	Qty       pgtype.Numeric     `json:"qty"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
+type InvoiceCounter struct {
+	Scope     string             `json:"scope"`
+	LastValue int64              `json:"last_value"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type KitchenTicket struct {
+	ID        int32              `json:"id"`
+	OrderID   int32              `json:"order_id"`
+	TicketNo  int32              `json:"ticket_no"`
+	UserID    pgtype.Int4        `json:"user_id"`
+	Status    string             `json:"status"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+	DoneAt    pgtype.Timestamptz `json:"done_at"`
+}
+
+type PaymentCharge struct {
+	ID          int32              `json:"id"`
+	SaleID      int32              `json:"sale_id"`
+	Provider    string             `json:"provider"`
+	Method      string             `json:"method"`
+	Reference   string             `json:"reference"`
+	Amount      pgtype.Numeric     `json:"amount"`
+	Status      string             `json:"status"`
+	QrString    pgtype.Text        `json:"qr_string"`
+	CheckoutUrl pgtype.Text        `json:"checkout_url"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	PaidAt      pgtype.Timestamptz `json:"paid_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Product struct {
+	ID           int32              `json:"id"`
+	Sku          pgtype.Text        `json:"sku"`
+	Name         string             `json:"name"`
+	CategoryID   pgtype.Int4        `json:"category_id"`
+	Price        pgtype.Numeric     `json:"price"`
+	CostPrice    pgtype.Numeric     `json:"cost_price"`
+	Unit         pgtype.Text        `json:"unit"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	TaxRateID    pgtype.Int4        `json:"tax_rate_id"`
+	TaxExempt    bool               `json:"tax_exempt"`
+	QtyPrecision int16              `json:"qty_precision"`
+}
+
+type ProductUnit struct {
+	ID        int32              `json:"id"`
+	ProductID int32              `json:"product_id"`
+	Name      string             `json:"name"`
+	Factor    pgtype.Numeric     `json:"factor"`
+	Price     pgtype.Numeric     `json:"price"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type Promotion struct {
 	ID         int32              `json:"id"`
-	Sku        pgtype.Text        `json:"sku"`
 	Name       string             `json:"name"`
+	Type       string             `json:"type"`
 	CategoryID pgtype.Int4        `json:"category_id"`
-	Price      pgtype.Numeric     `json:"price"`
-	CostPrice  pgtype.Numeric     `json:"cost_price"`
-	Unit       pgtype.Text        `json:"unit"`
+	ProductID  pgtype.Int4        `json:"product_id"`
+	Percent    pgtype.Numeric     `json:"percent"`
+	Amount     pgtype.Numeric     `json:"amount"`
+	BuyQty     pgtype.Int4        `json:"buy_qty"`
+	GetQty     pgtype.Int4        `json:"get_qty"`
+	MinSpend   pgtype.Numeric     `json:"min_spend"`
+	StartsAt   pgtype.Timestamptz `json:"starts_at"`
+	EndsAt     pgtype.Timestamptz `json:"ends_at"`
+	Active     bool               `json:"active"`
 	CreatedAt  pgtype.Timestamptz `json:"created_at"`
 }
 
 type Sale struct {
-	ID            int32              `json:"id"`
-	InvoiceNo     string             `json:"invoice_no"`
-	UserID        pgtype.Int4        `json:"user_id"`
-	TotalAmount   pgtype.Numeric     `json:"total_amount"`
-	PaidAmount    pgtype.Numeric     `json:"paid_amount"`
-	ChangeAmount  pgtype.Numeric     `json:"change_amount"`
-	PaymentMethod pgtype.Text        `json:"payment_method"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
+	ID              int32              `json:"id"`
+	InvoiceNo       string             `json:"invoice_no"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	TotalAmount     pgtype.Numeric     `json:"total_amount"`
+	PaidAmount      pgtype.Numeric     `json:"paid_amount"`
+	ChangeAmount    pgtype.Numeric     `json:"change_amount"`
+	PaymentMethod   pgtype.Text        `json:"payment_method"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	VoidedAt        pgtype.Timestamptz `json:"voided_at"`
+	VoidedBy        pgtype.Int4        `json:"voided_by"`
+	VoidReason      pgtype.Text        `json:"void_reason"`
+	SubtotalAmount  pgtype.Numeric     `json:"subtotal_amount"`
+	TaxAmount       pgtype.Numeric     `json:"tax_amount"`
+	VoucherCode     pgtype.Text        `json:"voucher_code"`
+	VoucherDiscount pgtype.Numeric     `json:"voucher_discount"`
+	CustomerID      pgtype.Int4        `json:"customer_id"`
+	PointsEarned    int64              `json:"points_earned"`
+	PointsRedeemed  int64              `json:"points_redeemed"`
+	ShiftID         pgtype.Int4        `json:"shift_id"`
+	RoundingAmount  pgtype.Numeric     `json:"rounding_amount"`
+	PaymentStatus   string             `json:"payment_status"`
+}
+
+type SaleIdempotencyKey struct {
+	UserID         int32              `json:"user_id"`
+	IdempotencyKey string             `json:"idempotency_key"`
+	RequestHash    string             `json:"request_hash"`
+	SaleID         int32              `json:"sale_id"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 }
 
 type SaleItem struct {
-	ID        int32          `json:"id"`
-	SaleID    pgtype.Int4    `json:"sale_id"`
-	ProductID pgtype.Int4    `json:"product_id"`
-	Qty       int32          `json:"qty"`
-	Price     pgtype.Numeric `json:"price"`
-	Discount  pgtype.Numeric `json:"discount"`
-	Subtotal  pgtype.Numeric `json:"subtotal"`
+	ID                int32          `json:"id"`
+	SaleID            pgtype.Int4    `json:"sale_id"`
+	ProductID         pgtype.Int4    `json:"product_id"`
+	Qty               pgtype.Numeric `json:"qty"`
+	Price             pgtype.Numeric `json:"price"`
+	Discount          pgtype.Numeric `json:"discount"`
+	Subtotal          pgtype.Numeric `json:"subtotal"`
+	ListPrice         pgtype.Numeric `json:"list_price"`
+	OverridePrice     pgtype.Numeric `json:"override_price"`
+	OverrideBy        pgtype.Int4    `json:"override_by"`
+	TaxRateID         pgtype.Int4    `json:"tax_rate_id"`
+	TaxRate           pgtype.Numeric `json:"tax_rate"`
+	TaxInclusive      bool           `json:"tax_inclusive"`
+	TaxAmount         pgtype.Numeric `json:"tax_amount"`
+	PromotionDiscount pgtype.Numeric `json:"promotion_discount"`
+	VoucherDiscount   pgtype.Numeric `json:"voucher_discount"`
+	UnitID            pgtype.Int4    `json:"unit_id"`
+	UnitName          pgtype.Text    `json:"unit_name"`
+	UnitFactor        pgtype.Numeric `json:"unit_factor"`
+}
+
+type SaleItemPromotion struct {
+	ID            int32          `json:"id"`
+	SaleID        int32          `json:"sale_id"`
+	SaleItemID    int32          `json:"sale_item_id"`
+	PromotionID   pgtype.Int4    `json:"promotion_id"`
+	PromotionName string         `json:"promotion_name"`
+	Amount        pgtype.Numeric `json:"amount"`
+}
+
+type SalePayment struct {
+	ID        int32              `json:"id"`
+	SaleID    int32              `json:"sale_id"`
+	Method    string             `json:"method"`
+	Amount    pgtype.Numeric     `json:"amount"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type SaleReturn struct {
+	ID           int32              `json:"id"`
+	ReturnNo     string             `json:"return_no"`
+	SaleID       int32              `json:"sale_id"`
+	UserID       pgtype.Int4        `json:"user_id"`
+	RefundAmount pgtype.Numeric     `json:"refund_amount"`
+	RefundMethod pgtype.Text        `json:"refund_method"`
+	Reason       pgtype.Text        `json:"reason"`
+	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+}
+
+type SaleReturnItem struct {
+	ID           int32          `json:"id"`
+	ReturnID     int32          `json:"return_id"`
+	SaleItemID   int32          `json:"sale_item_id"`
+	ProductID    pgtype.Int4    `json:"product_id"`
+	Qty          pgtype.Numeric `json:"qty"`
+	RefundAmount pgtype.Numeric `json:"refund_amount"`
+}
+
+type Shift struct {
+	ID           int32              `json:"id"`
+	UserID       int32              `json:"user_id"`
+	OpeningFloat pgtype.Numeric     `json:"opening_float"`
+	OpeningNote  pgtype.Text        `json:"opening_note"`
+	OpenedAt     pgtype.Timestamptz `json:"opened_at"`
+	ClosedAt     pgtype.Timestamptz `json:"closed_at"`
+	ExpectedCash pgtype.Numeric     `json:"expected_cash"`
+	CountedCash  pgtype.Numeric     `json:"counted_cash"`
+	CashVariance pgtype.Numeric     `json:"cash_variance"`
+	ClosingNote  pgtype.Text        `json:"closing_note"`
+}
+
+type ShiftCashMovement struct {
+	ID        int32              `json:"id"`
+	ShiftID   int32              `json:"shift_id"`
+	Type      string             `json:"type"`
+	Amount    pgtype.Numeric     `json:"amount"`
+	Reason    string             `json:"reason"`
+	UserID    pgtype.Int4        `json:"user_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type ShiftTender struct {
+	ID       int32          `json:"id"`
+	ShiftID  int32          `json:"shift_id"`
+	Method   string         `json:"method"`
+	Expected pgtype.Numeric `json:"expected"`
+	Counted  pgtype.Numeric `json:"counted"`
+	Variance pgtype.Numeric `json:"variance"`
+}
+
+type TableOrder struct {
+	ID       int32              `json:"id"`
+	TableID  int32              `json:"table_id"`
+	UserID   pgtype.Int4        `json:"user_id"`
+	Guests   pgtype.Int4        `json:"guests"`
+	Note     pgtype.Text        `json:"note"`
+	Status   string             `json:"status"`
+	Revision int32              `json:"revision"`
+	SaleID   pgtype.Int4        `json:"sale_id"`
+	OpenedAt pgtype.Timestamptz `json:"opened_at"`
+	ClosedAt pgtype.Timestamptz `json:"closed_at"`
+}
+
+type TableOrderItem struct {
+	ID        int32              `json:"id"`
+	OrderID   int32              `json:"order_id"`
+	ProductID int32              `json:"product_id"`
+	Qty       pgtype.Numeric     `json:"qty"`
+	UnitID    pgtype.Int4        `json:"unit_id"`
+	UnitName  pgtype.Text        `json:"unit_name"`
+	Note      pgtype.Text        `json:"note"`
+	TicketID  pgtype.Int4        `json:"ticket_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type TaxRate struct {
+	ID        int32              `json:"id"`
+	Name      string             `json:"name"`
+	Rate      pgtype.Numeric     `json:"rate"`
+	Inclusive bool               `json:"inclusive"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type User struct {
@@ -60,3 +319,28 @@ type User struct {
 	Role         string             `json:"role"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 }
+
+type Voucher struct {
+	ID               int32              `json:"id"`
+	Code             string             `json:"code"`
+	Description      pgtype.Text        `json:"description"`
+	Type             string             `json:"type"`
+	Value            pgtype.Numeric     `json:"value"`
+	MinSpend         pgtype.Numeric     `json:"min_spend"`
+	UsageLimit       pgtype.Int4        `json:"usage_limit"`
+	PerCustomerLimit pgtype.Int4        `json:"per_customer_limit"`
+	UsedCount        int32              `json:"used_count"`
+	StartsAt         pgtype.Timestamptz `json:"starts_at"`
+	ExpiresAt        pgtype.Timestamptz `json:"expires_at"`
+	Active           bool               `json:"active"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type VoucherRedemption struct {
+	ID          int32              `json:"id"`
+	VoucherID   int32              `json:"voucher_id"`
+	SaleID      int32              `json:"sale_id"`
+	CustomerRef pgtype.Text        `json:"customer_ref"`
+	Amount      pgtype.Numeric     `json:"amount"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
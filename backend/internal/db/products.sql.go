@@ -12,18 +12,21 @@ import (
 )
 
 const createProduct = `-- name: CreateProduct :one
-INSERT INTO products (sku, name, category_id, price, cost_price, unit)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, sku, name, category_id, price, cost_price, unit, created_at
+INSERT INTO products (sku, name, category_id, price, cost_price, unit, tax_rate_id, tax_exempt, qty_precision)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, sku, name, category_id, price, cost_price, unit, created_at, tax_rate_id, tax_exempt, qty_precision
 `
 
 type CreateProductParams struct {
-	Sku        pgtype.Text    `json:"sku"`
-	Name       string         `json:"name"`
-	CategoryID pgtype.Int4    `json:"category_id"`
-	Price      pgtype.Numeric `json:"price"`
-	CostPrice  pgtype.Numeric `json:"cost_price"`
-	Unit       pgtype.Text    `json:"unit"`
+	Sku          pgtype.Text    `json:"sku"`
+	Name         string         `json:"name"`
+	CategoryID   pgtype.Int4    `json:"category_id"`
+	Price        pgtype.Numeric `json:"price"`
+	CostPrice    pgtype.Numeric `json:"cost_price"`
+	Unit         pgtype.Text    `json:"unit"`
+	TaxRateID    pgtype.Int4    `json:"tax_rate_id"`
+	TaxExempt    bool           `json:"tax_exempt"`
+	QtyPrecision int16          `json:"qty_precision"`
 }
 
 func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error) {
@@ -34,6 +37,9 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		arg.Price,
 		arg.CostPrice,
 		arg.Unit,
+		arg.TaxRateID,
+		arg.TaxExempt,
+		arg.QtyPrecision,
 	)
 	var i Product
 	err := row.Scan(
@@ -45,6 +51,9 @@ func (q *Queries) CreateProduct(ctx context.Context, arg CreateProductParams) (P
 		&i.CostPrice,
 		&i.Unit,
 		&i.CreatedAt,
+		&i.TaxRateID,
+		&i.TaxExempt,
+		&i.QtyPrecision,
 	)
 	return i, err
 }
@@ -59,7 +68,7 @@ func (q *Queries) DeleteProduct(ctx context.Context, id int32) error {
 }
 
 const getProductByID = `-- name: GetProductByID :one
-SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, c.name as category_name
+SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, p.tax_rate_id, p.tax_exempt, p.qty_precision, c.name as category_name
 FROM products p
 LEFT JOIN categories c ON p.category_id = c.id
 WHERE p.id = $1 LIMIT 1
@@ -74,6 +83,9 @@ type GetProductByIDRow struct {
 	CostPrice    pgtype.Numeric     `json:"cost_price"`
 	Unit         pgtype.Text        `json:"unit"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	TaxRateID    pgtype.Int4        `json:"tax_rate_id"`
+	TaxExempt    bool               `json:"tax_exempt"`
+	QtyPrecision int16              `json:"qty_precision"`
 	CategoryName pgtype.Text        `json:"category_name"`
 }
 
@@ -89,13 +101,16 @@ func (q *Queries) GetProductByID(ctx context.Context, id int32) (GetProductByIDR
 		&i.CostPrice,
 		&i.Unit,
 		&i.CreatedAt,
+		&i.TaxRateID,
+		&i.TaxExempt,
+		&i.QtyPrecision,
 		&i.CategoryName,
 	)
 	return i, err
 }
 
 const getProductBySKU = `-- name: GetProductBySKU :one
-SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, c.name as category_name
+SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, p.tax_rate_id, p.tax_exempt, p.qty_precision, c.name as category_name
 FROM products p
 LEFT JOIN categories c ON p.category_id = c.id
 WHERE p.sku = $1 LIMIT 1
@@ -110,6 +125,9 @@ type GetProductBySKURow struct {
 	CostPrice    pgtype.Numeric     `json:"cost_price"`
 	Unit         pgtype.Text        `json:"unit"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	TaxRateID    pgtype.Int4        `json:"tax_rate_id"`
+	TaxExempt    bool               `json:"tax_exempt"`
+	QtyPrecision int16              `json:"qty_precision"`
 	CategoryName pgtype.Text        `json:"category_name"`
 }
 
@@ -125,13 +143,16 @@ func (q *Queries) GetProductBySKU(ctx context.Context, sku pgtype.Text) (GetProd
 		&i.CostPrice,
 		&i.Unit,
 		&i.CreatedAt,
+		&i.TaxRateID,
+		&i.TaxExempt,
+		&i.QtyPrecision,
 		&i.CategoryName,
 	)
 	return i, err
 }
 
 const listProducts = `-- name: ListProducts :many
-SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, c.name as category_name
+SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, p.tax_rate_id, p.tax_exempt, p.qty_precision, c.name as category_name
 FROM products p
 LEFT JOIN categories c ON p.category_id = c.id
 ORDER BY p.created_at DESC
@@ -146,6 +167,9 @@ type ListProductsRow struct {
 	CostPrice    pgtype.Numeric     `json:"cost_price"`
 	Unit         pgtype.Text        `json:"unit"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	TaxRateID    pgtype.Int4        `json:"tax_rate_id"`
+	TaxExempt    bool               `json:"tax_exempt"`
+	QtyPrecision int16              `json:"qty_precision"`
 	CategoryName pgtype.Text        `json:"category_name"`
 }
 
@@ -167,6 +191,9 @@ func (q *Queries) ListProducts(ctx context.Context) ([]ListProductsRow, error) {
 			&i.CostPrice,
 			&i.Unit,
 			&i.CreatedAt,
+			&i.TaxRateID,
+			&i.TaxExempt,
+			&i.QtyPrecision,
 			&i.CategoryName,
 		); err != nil {
 			return nil, err
@@ -180,7 +207,7 @@ func (q *Queries) ListProducts(ctx context.Context) ([]ListProductsRow, error) {
 }
 
 const listProductsWithStock = `-- name: ListProductsWithStock :many
-SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, c.name as category_name
+SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, p.tax_rate_id, p.tax_exempt, p.qty_precision, c.name as category_name
 FROM products p
 LEFT JOIN categories c ON p.category_id = c.id
 INNER JOIN inventory i ON p.id = i.product_id
@@ -197,6 +224,9 @@ type ListProductsWithStockRow struct {
 	CostPrice    pgtype.Numeric     `json:"cost_price"`
 	Unit         pgtype.Text        `json:"unit"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	TaxRateID    pgtype.Int4        `json:"tax_rate_id"`
+	TaxExempt    bool               `json:"tax_exempt"`
+	QtyPrecision int16              `json:"qty_precision"`
 	CategoryName pgtype.Text        `json:"category_name"`
 }
 
@@ -218,6 +248,9 @@ func (q *Queries) ListProductsWithStock(ctx context.Context) ([]ListProductsWith
 			&i.CostPrice,
 			&i.Unit,
 			&i.CreatedAt,
+			&i.TaxRateID,
+			&i.TaxExempt,
+			&i.QtyPrecision,
 			&i.CategoryName,
 		); err != nil {
 			return nil, err
@@ -231,7 +264,7 @@ func (q *Queries) ListProductsWithStock(ctx context.Context) ([]ListProductsWith
 }
 
 const searchProducts = `-- name: SearchProducts :many
-SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, c.name as category_name
+SELECT p.id, p.sku, p.name, p.category_id, p.price, p.cost_price, p.unit, p.created_at, p.tax_rate_id, p.tax_exempt, p.qty_precision, c.name as category_name
 FROM products p
 LEFT JOIN categories c ON p.category_id = c.id
 WHERE p.name ILIKE '%' || $1 || '%' OR p.sku ILIKE '%' || $1 || '%'
@@ -247,6 +280,9 @@ type SearchProductsRow struct {
 	CostPrice    pgtype.Numeric     `json:"cost_price"`
 	Unit         pgtype.Text        `json:"unit"`
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
+	TaxRateID    pgtype.Int4        `json:"tax_rate_id"`
+	TaxExempt    bool               `json:"tax_exempt"`
+	QtyPrecision int16              `json:"qty_precision"`
 	CategoryName pgtype.Text        `json:"category_name"`
 }
 
@@ -268,6 +304,9 @@ func (q *Queries) SearchProducts(ctx context.Context, dollar_1 pgtype.Text) ([]S
 			&i.CostPrice,
 			&i.Unit,
 			&i.CreatedAt,
+			&i.TaxRateID,
+			&i.TaxExempt,
+			&i.QtyPrecision,
 			&i.CategoryName,
 		); err != nil {
 			return nil, err
@@ -282,19 +321,22 @@ func (q *Queries) SearchProducts(ctx context.Context, dollar_1 pgtype.Text) ([]S
 
 const updateProduct = `-- name: UpdateProduct :one
 UPDATE products
-SET sku = $2, name = $3, category_id = $4, price = $5, cost_price = $6, unit = $7
+SET sku = $2, name = $3, category_id = $4, price = $5, cost_price = $6, unit = $7, tax_rate_id = $8, tax_exempt = $9, qty_precision = $10
 WHERE id = $1
-RETURNING id, sku, name, category_id, price, cost_price, unit, created_at
+RETURNING id, sku, name, category_id, price, cost_price, unit, created_at, tax_rate_id, tax_exempt, qty_precision
 `
 
 type UpdateProductParams struct {
-	ID         int32          `json:"id"`
-	Sku        pgtype.Text    `json:"sku"`
-	Name       string         `json:"name"`
-	CategoryID pgtype.Int4    `json:"category_id"`
-	Price      pgtype.Numeric `json:"price"`
-	CostPrice  pgtype.Numeric `json:"cost_price"`
-	Unit       pgtype.Text    `json:"unit"`
+	ID           int32          `json:"id"`
+	Sku          pgtype.Text    `json:"sku"`
+	Name         string         `json:"name"`
+	CategoryID   pgtype.Int4    `json:"category_id"`
+	Price        pgtype.Numeric `json:"price"`
+	CostPrice    pgtype.Numeric `json:"cost_price"`
+	Unit         pgtype.Text    `json:"unit"`
+	TaxRateID    pgtype.Int4    `json:"tax_rate_id"`
+	TaxExempt    bool           `json:"tax_exempt"`
+	QtyPrecision int16          `json:"qty_precision"`
 }
 
 func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error) {
@@ -306,6 +348,9 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (P
 		arg.Price,
 		arg.CostPrice,
 		arg.Unit,
+		arg.TaxRateID,
+		arg.TaxExempt,
+		arg.QtyPrecision,
 	)
 	var i Product
 	err := row.Scan(
@@ -317,6 +362,9 @@ func (q *Queries) UpdateProduct(ctx context.Context, arg UpdateProductParams) (P
 		&i.CostPrice,
 		&i.Unit,
 		&i.CreatedAt,
+		&i.TaxRateID,
+		&i.TaxExempt,
+		&i.QtyPrecision,
 	)
 	return i, err
 }
@@ -11,41 +11,219 @@ import (
 )
 
 type Querier interface {
+	// Reversals never take the balance below zero, even if the customer has
+	// already spent the points being reversed.
+	AddCustomerPoints(ctx context.Context, arg AddCustomerPointsParams) (Customer, error)
 	AdjustInventoryQty(ctx context.Context, arg AdjustInventoryQtyParams) (Inventory, error)
-	CreateCategory(ctx context.Context, name string) (Category, error)
+	CancelTableOrder(ctx context.Context, id int32) (TableOrder, error)
+	CloseShift(ctx context.Context, arg CloseShiftParams) (Shift, error)
+	CompleteKitchenTicket(ctx context.Context, id int32) (KitchenTicket, error)
+	// Marks a sale awaiting payment as paid. Matches nothing once the sale has
+	// left pending, so a charge is only ever applied once.
+	CompletePendingSale(ctx context.Context, id int32) (Sale, error)
+	CountSaleReturnsBySale(ctx context.Context, saleID int32) (int64, error)
+	// Counts the sales ListSales pages through, ignoring the cursor.
+	CountSales(ctx context.Context, arg CountSalesParams) (int64, error)
+	CountVoucherRedemptionsByCustomer(ctx context.Context, arg CountVoucherRedemptionsByCustomerParams) (int64, error)
+	CreateCategory(ctx context.Context, arg CreateCategoryParams) (Category, error)
+	CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error)
+	CreateCustomerPointEntry(ctx context.Context, arg CreateCustomerPointEntryParams) (CustomerPointEntry, error)
+	CreateDiningTable(ctx context.Context, arg CreateDiningTableParams) (DiningTable, error)
+	CreateGiftCard(ctx context.Context, arg CreateGiftCardParams) (GiftCard, error)
+	CreateGiftCardTransaction(ctx context.Context, arg CreateGiftCardTransactionParams) (GiftCardTransaction, error)
+	CreateHeldCart(ctx context.Context, arg CreateHeldCartParams) (HeldCart, error)
 	CreateInventory(ctx context.Context, arg CreateInventoryParams) (Inventory, error)
+	CreateKitchenTicket(ctx context.Context, arg CreateKitchenTicketParams) (KitchenTicket, error)
+	CreatePaymentCharge(ctx context.Context, arg CreatePaymentChargeParams) (PaymentCharge, error)
 	CreateProduct(ctx context.Context, arg CreateProductParams) (Product, error)
+	CreateProductUnit(ctx context.Context, arg CreateProductUnitParams) (ProductUnit, error)
+	CreatePromotion(ctx context.Context, arg CreatePromotionParams) (Promotion, error)
 	CreateSale(ctx context.Context, arg CreateSaleParams) (Sale, error)
+	CreateSaleIdempotencyKey(ctx context.Context, arg CreateSaleIdempotencyKeyParams) (SaleIdempotencyKey, error)
 	CreateSaleItem(ctx context.Context, arg CreateSaleItemParams) (SaleItem, error)
+	CreateSaleItemPromotion(ctx context.Context, arg CreateSaleItemPromotionParams) (SaleItemPromotion, error)
+	CreateSalePayment(ctx context.Context, arg CreateSalePaymentParams) (SalePayment, error)
+	CreateSaleReturn(ctx context.Context, arg CreateSaleReturnParams) (SaleReturn, error)
+	CreateSaleReturnItem(ctx context.Context, arg CreateSaleReturnItemParams) (SaleReturnItem, error)
+	CreateShift(ctx context.Context, arg CreateShiftParams) (Shift, error)
+	CreateShiftCashMovement(ctx context.Context, arg CreateShiftCashMovementParams) (ShiftCashMovement, error)
+	CreateShiftTender(ctx context.Context, arg CreateShiftTenderParams) (ShiftTender, error)
+	CreateTableOrder(ctx context.Context, arg CreateTableOrderParams) (TableOrder, error)
+	CreateTableOrderItem(ctx context.Context, arg CreateTableOrderItemParams) (TableOrderItem, error)
+	CreateTaxRate(ctx context.Context, arg CreateTaxRateParams) (TaxRate, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVoucher(ctx context.Context, arg CreateVoucherParams) (Voucher, error)
+	CreateVoucherRedemption(ctx context.Context, arg CreateVoucherRedemptionParams) (VoucherRedemption, error)
+	DecrementVoucherUsage(ctx context.Context, id int32) error
 	DeleteCategory(ctx context.Context, id int32) error
+	DeleteCustomer(ctx context.Context, id int32) error
+	DeleteExpiredHeldCarts(ctx context.Context) (int64, error)
+	DeleteHeldCart(ctx context.Context, arg DeleteHeldCartParams) (HeldCart, error)
 	DeleteProduct(ctx context.Context, id int32) error
+	DeleteProductUnit(ctx context.Context, arg DeleteProductUnitParams) (int64, error)
+	DeletePromotion(ctx context.Context, id int32) error
+	// Frees the key of a sale that failed before the customer could pay, so a
+	// retry with the same key starts a new sale.
+	DeleteSaleIdempotencyKeyBySale(ctx context.Context, saleID int32) error
+	DeleteTableOrderItem(ctx context.Context, id int32) error
+	DeleteTaxRate(ctx context.Context, id int32) error
+	DeleteVoucher(ctx context.Context, id int32) error
+	DeleteVoucherRedemptionBySale(ctx context.Context, saleID int32) (VoucherRedemption, error)
+	// Voids a sale whose payment did not go through.
+	FailPendingSale(ctx context.Context, arg FailPendingSaleParams) (Sale, error)
 	GetCategoryByID(ctx context.Context, id int32) (Category, error)
+	GetCustomerByID(ctx context.Context, id int32) (Customer, error)
+	GetCustomerSalesSummary(ctx context.Context, customerID pgtype.Int4) (GetCustomerSalesSummaryRow, error)
+	GetDiningTable(ctx context.Context, id int32) (DiningTable, error)
+	GetGiftCardByID(ctx context.Context, id int32) (GiftCard, error)
+	GetGiftCardByNumber(ctx context.Context, cardNumber string) (GiftCard, error)
+	GetGiftCardByNumberForUpdate(ctx context.Context, cardNumber string) (GiftCard, error)
+	GetGiftCardByReturn(ctx context.Context, returnID pgtype.Int4) (GiftCard, error)
+	GetGiftCardForUpdate(ctx context.Context, id int32) (GiftCard, error)
 	GetInventoryByProduct(ctx context.Context, productID pgtype.Int4) (Inventory, error)
-	GetLowStockItems(ctx context.Context, qty int32) ([]GetLowStockItemsRow, error)
+	// Locks the row until the transaction ends. Lock several products in
+	// ascending product_id order so concurrent sales cannot deadlock.
+	GetInventoryByProductForUpdate(ctx context.Context, productID pgtype.Int4) (Inventory, error)
+	GetKitchenTicket(ctx context.Context, id int32) (GetKitchenTicketRow, error)
+	GetLowStockItems(ctx context.Context, qty pgtype.Numeric) ([]GetLowStockItemsRow, error)
+	GetOpenShiftByUser(ctx context.Context, userID int32) (Shift, error)
+	// Held by a sale until it commits, so the shift cannot close while one of
+	// its sales is still being written.
+	GetOpenShiftByUserForShare(ctx context.Context, userID int32) (Shift, error)
+	GetOpenTableOrderByTable(ctx context.Context, tableID int32) (TableOrder, error)
+	GetPaymentChargeByReferenceForUpdate(ctx context.Context, arg GetPaymentChargeByReferenceForUpdateParams) (PaymentCharge, error)
+	GetPaymentChargeBySale(ctx context.Context, saleID int32) (PaymentCharge, error)
+	GetPaymentChargeBySaleForUpdate(ctx context.Context, saleID int32) (PaymentCharge, error)
 	GetProductByID(ctx context.Context, id int32) (GetProductByIDRow, error)
 	GetProductBySKU(ctx context.Context, sku pgtype.Text) (GetProductBySKURow, error)
+	// The product's own rate wins over its category's; exempt products have none.
+	GetProductTaxRate(ctx context.Context, id int32) (TaxRate, error)
+	GetProductUnit(ctx context.Context, arg GetProductUnitParams) (ProductUnit, error)
+	GetPromotionByID(ctx context.Context, id int32) (Promotion, error)
+	GetReturnableSaleItems(ctx context.Context, saleID pgtype.Int4) ([]GetReturnableSaleItemsRow, error)
 	GetSaleByID(ctx context.Context, id int32) (GetSaleByIDRow, error)
 	GetSaleByInvoice(ctx context.Context, invoiceNo string) (GetSaleByInvoiceRow, error)
+	GetSaleForUpdate(ctx context.Context, id int32) (Sale, error)
+	GetSaleIdempotencyKey(ctx context.Context, arg GetSaleIdempotencyKeyParams) (SaleIdempotencyKey, error)
+	GetSaleItemPromotionsBySaleID(ctx context.Context, saleID int32) ([]SaleItemPromotion, error)
+	GetSaleItemPromotionsBySaleIDs(ctx context.Context, saleIds []int32) ([]SaleItemPromotion, error)
 	GetSaleItemsByProductID(ctx context.Context, productID pgtype.Int4) ([]GetSaleItemsByProductIDRow, error)
 	GetSaleItemsBySaleID(ctx context.Context, saleID pgtype.Int4) ([]GetSaleItemsBySaleIDRow, error)
+	// Items of several sales at once, for building pages of sales.
+	GetSaleItemsBySaleIDs(ctx context.Context, saleIds []int32) ([]GetSaleItemsBySaleIDsRow, error)
+	GetSalePaymentsBySaleID(ctx context.Context, saleID int32) ([]SalePayment, error)
+	GetSalePaymentsBySaleIDs(ctx context.Context, saleIds []int32) ([]SalePayment, error)
+	GetSaleReturnByID(ctx context.Context, id int32) (GetSaleReturnByIDRow, error)
+	GetSaleReturnItems(ctx context.Context, returnID int32) ([]GetSaleReturnItemsRow, error)
 	GetSalesStats(ctx context.Context, arg GetSalesStatsParams) (GetSalesStatsRow, error)
+	GetShiftByID(ctx context.Context, id int32) (Shift, error)
+	GetShiftForUpdate(ctx context.Context, id int32) (Shift, error)
+	// Gift card sales and reloads the cashier took payment for while the shift
+	// was open.
+	GetShiftGiftCardSalesByMethod(ctx context.Context, arg GetShiftGiftCardSalesByMethodParams) ([]GetShiftGiftCardSalesByMethodRow, error)
+	// Tenders of the shift's paid sales that were not voided, net of change.
+	GetShiftPaymentsByMethod(ctx context.Context, shiftID pgtype.Int4) ([]GetShiftPaymentsByMethodRow, error)
+	// Refunds the cashier paid out while the shift was open.
+	GetShiftRefundsByMethod(ctx context.Context, arg GetShiftRefundsByMethodParams) ([]GetShiftRefundsByMethodRow, error)
+	GetShiftSalesSummary(ctx context.Context, shiftID pgtype.Int4) (GetShiftSalesSummaryRow, error)
+	GetTableOrder(ctx context.Context, id int32) (GetTableOrderRow, error)
+	GetTableOrderForUpdate(ctx context.Context, id int32) (TableOrder, error)
+	GetTableOrderItem(ctx context.Context, arg GetTableOrderItemParams) (TableOrderItem, error)
+	GetTaxRateByID(ctx context.Context, id int32) (TaxRate, error)
 	GetUserByID(ctx context.Context, id int32) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetVoucherByCode(ctx context.Context, code string) (Voucher, error)
+	// Locks the voucher so concurrent checkouts cannot both take its last use.
+	GetVoucherByCodeForUpdate(ctx context.Context, code string) (Voucher, error)
+	GetVoucherByID(ctx context.Context, id int32) (Voucher, error)
+	// Links the order to a sale still awaiting a QRIS or e-wallet payment. The
+	// order stays bill_requested, and locked against changes, until the payment
+	// settles or releases it. Matches nothing under the same conditions as
+	// SettleTableOrder.
+	HoldTableOrderForPayment(ctx context.Context, arg HoldTableOrderForPaymentParams) (TableOrder, error)
+	IncrementVoucherUsage(ctx context.Context, id int32) (int64, error)
+	ListActivePromotions(ctx context.Context, at pgtype.Timestamptz) ([]Promotion, error)
 	ListCategories(ctx context.Context) ([]Category, error)
+	ListCustomerSales(ctx context.Context, arg ListCustomerSalesParams) ([]ListCustomerSalesRow, error)
+	ListCustomers(ctx context.Context, arg ListCustomersParams) ([]Customer, error)
+	// Every table with its open order, if any.
+	ListDiningTables(ctx context.Context) ([]ListDiningTablesRow, error)
+	// Cards past their expiry date that still hold a balance.
+	ListExpiredGiftCardsForUpdate(ctx context.Context, at pgtype.Timestamptz) ([]GiftCard, error)
+	ListGiftCardRedemptionsBySale(ctx context.Context, saleID pgtype.Int4) ([]GiftCardTransaction, error)
+	ListGiftCardTransactions(ctx context.Context, giftCardID int32) ([]GiftCardTransaction, error)
+	ListGiftCards(ctx context.Context, arg ListGiftCardsParams) ([]GiftCard, error)
+	ListHeldCartsByUser(ctx context.Context, userID int32) ([]HeldCart, error)
 	ListInventory(ctx context.Context) ([]ListInventoryRow, error)
+	ListKitchenTicketItems(ctx context.Context, ticketIds []int32) ([]ListKitchenTicketItemsRow, error)
+	ListKitchenTicketsByOrder(ctx context.Context, orderID int32) ([]ListKitchenTicketsByOrderRow, error)
+	// The kitchen's queue, oldest first.
+	ListKitchenTicketsByStatus(ctx context.Context, arg ListKitchenTicketsByStatusParams) ([]ListKitchenTicketsByStatusRow, error)
+	// Pending sales that no callback will settle: their charge has expired, or
+	// they are older than started_before and never got one.
+	ListOverduePendingSales(ctx context.Context, arg ListOverduePendingSalesParams) ([]ListOverduePendingSalesRow, error)
+	ListProductUnits(ctx context.Context, productID int32) ([]ProductUnit, error)
 	ListProducts(ctx context.Context) ([]ListProductsRow, error)
 	ListProductsWithStock(ctx context.Context) ([]ListProductsWithStockRow, error)
+	ListPromotions(ctx context.Context) ([]Promotion, error)
+	// One row per sale item, oldest first, for the sales export. Sales awaiting
+	// payment are left out; otherwise the filters match ListSales. Rows are read
+	// in batches keyed on (created_at, sale_id, item_id), with the cursor
+	// arguments taken from the last row of a batch.
+	ListSaleJournal(ctx context.Context, arg ListSaleJournalParams) ([]ListSaleJournalRow, error)
+	ListSaleReturns(ctx context.Context, arg ListSaleReturnsParams) ([]ListSaleReturnsRow, error)
+	ListSaleReturnsBySale(ctx context.Context, saleID int32) ([]ListSaleReturnsBySaleRow, error)
+	// Sales newest first, filtered by whichever arguments are not null. Pages
+	// are keyed on (created_at, id), so sales inserted while paging never shift
+	// or repeat rows; cursor_created_at and cursor_id are the last row seen.
 	ListSales(ctx context.Context, arg ListSalesParams) ([]ListSalesRow, error)
 	ListSalesByDateRange(ctx context.Context, arg ListSalesByDateRangeParams) ([]ListSalesByDateRangeRow, error)
+	ListShiftCashMovements(ctx context.Context, shiftID int32) ([]ShiftCashMovement, error)
+	ListShiftTenders(ctx context.Context, shiftID int32) ([]ShiftTender, error)
+	ListShifts(ctx context.Context, arg ListShiftsParams) ([]Shift, error)
+	ListTableOrderItems(ctx context.Context, orderID int32) ([]ListTableOrderItemsRow, error)
+	ListTaxRates(ctx context.Context) ([]TaxRate, error)
+	// Paid charges of voided sales: money still owed back to the customer.
+	ListUnrefundedCharges(ctx context.Context) ([]PaymentCharge, error)
 	ListUsers(ctx context.Context) ([]User, error)
+	ListVouchers(ctx context.Context) ([]Voucher, error)
+	NextInvoiceCounter(ctx context.Context, scope string) (int64, error)
+	RedeemCustomerPoints(ctx context.Context, arg RedeemCustomerPointsParams) (int64, error)
+	// Frees the order held for a sale whose payment failed, so it can be
+	// settled again.
+	ReleaseTableOrderSale(ctx context.Context, saleID pgtype.Int4) error
+	RequestTableBill(ctx context.Context, id int32) (TableOrder, error)
+	// Records a change to the order's items. Changing them after the bill was
+	// requested reopens the order.
+	ReviseTableOrder(ctx context.Context, id int32) error
 	SalesByDate(ctx context.Context, arg SalesByDateParams) ([]SalesByDateRow, error)
 	SalesByPaymentMethod(ctx context.Context, arg SalesByPaymentMethodParams) ([]SalesByPaymentMethodRow, error)
+	SearchCustomersByPhone(ctx context.Context, phone string) ([]Customer, error)
 	SearchProducts(ctx context.Context, dollar_1 pgtype.Text) ([]SearchProductsRow, error)
+	// Puts every item of the order not yet sent to the kitchen on the ticket.
+	SendTableOrderItems(ctx context.Context, arg SendTableOrderItemsParams) (int64, error)
+	SetGiftCardActive(ctx context.Context, arg SetGiftCardActiveParams) (GiftCard, error)
+	// Closes the order with the sale that paid for it. Matches nothing when the
+	// order is no longer open or its items changed since revision was read.
+	SettleTableOrder(ctx context.Context, arg SettleTableOrderParams) (TableOrder, error)
+	// Closes the order held for a sale once its payment has gone through.
+	SettleTableOrderBySale(ctx context.Context, saleID pgtype.Int4) error
+	// Tax per rate charged. taxable_amount is the base the tax was charged on
+	// (line subtotals net of tax); refunded tax is prorated from each refund.
+	TaxSummary(ctx context.Context, arg TaxSummaryParams) ([]TaxSummaryRow, error)
 	TopProducts(ctx context.Context, arg TopProductsParams) ([]TopProductsRow, error)
 	UpdateCategory(ctx context.Context, arg UpdateCategoryParams) (Category, error)
+	UpdateCustomer(ctx context.Context, arg UpdateCustomerParams) (Customer, error)
+	UpdateDiningTable(ctx context.Context, arg UpdateDiningTableParams) (DiningTable, error)
+	UpdateGiftCardBalance(ctx context.Context, arg UpdateGiftCardBalanceParams) (GiftCard, error)
 	UpdateInventoryQty(ctx context.Context, arg UpdateInventoryQtyParams) (Inventory, error)
+	UpdatePaymentChargeStatus(ctx context.Context, arg UpdatePaymentChargeStatusParams) (PaymentCharge, error)
 	UpdateProduct(ctx context.Context, arg UpdateProductParams) (Product, error)
+	UpdateProductUnit(ctx context.Context, arg UpdateProductUnitParams) (ProductUnit, error)
+	UpdatePromotion(ctx context.Context, arg UpdatePromotionParams) (Promotion, error)
+	UpdateTaxRate(ctx context.Context, arg UpdateTaxRateParams) (TaxRate, error)
+	UpdateVoucher(ctx context.Context, arg UpdateVoucherParams) (Voucher, error)
+	VoidSale(ctx context.Context, arg VoidSaleParams) (Sale, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -12,13 +12,35 @@ import (
 )
 
 const salesByDate = `-- name: SalesByDate :many
+WITH sales_by_day AS (
+  SELECT
+    DATE(s.created_at) as sale_date,
+    COUNT(*) as total_transactions,
+    SUM(s.total_amount) as gross_revenue,
+    SUM(s.rounding_amount) as total_rounding
+  FROM sales s
+  WHERE s.created_at >= $1 AND s.created_at <= $2
+    AND s.voided_at IS NULL
+    AND s.payment_status = 'paid'
+  GROUP BY DATE(s.created_at)
+),
+refunds_by_day AS (
+  SELECT
+    DATE(r.created_at) as refund_date,
+    SUM(r.refund_amount) as total_refunds
+  FROM sale_returns r
+  WHERE r.created_at >= $1 AND r.created_at <= $2
+  GROUP BY DATE(r.created_at)
+)
 SELECT 
-  DATE(s.created_at) as sale_date,
-  COUNT(*) as total_transactions,
-  COALESCE(SUM(s.total_amount), 0) as total_revenue
-FROM sales s
-WHERE s.created_at >= $1 AND s.created_at <= $2
-GROUP BY DATE(s.created_at)
+  COALESCE(sd.sale_date, rd.refund_date)::date as sale_date,
+  COALESCE(sd.total_transactions, 0)::bigint as total_transactions,
+  (COALESCE(sd.gross_revenue, 0) - COALESCE(rd.total_refunds, 0))::numeric as total_revenue,
+  COALESCE(sd.gross_revenue, 0)::numeric as gross_revenue,
+  COALESCE(rd.total_refunds, 0)::numeric as total_refunds,
+  COALESCE(sd.total_rounding, 0)::numeric as total_rounding
+FROM sales_by_day sd
+FULL OUTER JOIN refunds_by_day rd ON sd.sale_date = rd.refund_date
 ORDER BY sale_date DESC
 `
 
@@ -28,9 +50,12 @@ type SalesByDateParams struct {
 }
 
 type SalesByDateRow struct {
-	SaleDate          pgtype.Date `json:"sale_date"`
-	TotalTransactions int64       `json:"total_transactions"`
-	TotalRevenue      interface{} `json:"total_revenue"`
+	SaleDate          pgtype.Date    `json:"sale_date"`
+	TotalTransactions int64          `json:"total_transactions"`
+	TotalRevenue      pgtype.Numeric `json:"total_revenue"`
+	GrossRevenue      pgtype.Numeric `json:"gross_revenue"`
+	TotalRefunds      pgtype.Numeric `json:"total_refunds"`
+	TotalRounding     pgtype.Numeric `json:"total_rounding"`
 }
 
 func (q *Queries) SalesByDate(ctx context.Context, arg SalesByDateParams) ([]SalesByDateRow, error) {
@@ -42,7 +67,14 @@ func (q *Queries) SalesByDate(ctx context.Context, arg SalesByDateParams) ([]Sal
 	items := []SalesByDateRow{}
 	for rows.Next() {
 		var i SalesByDateRow
-		if err := rows.Scan(&i.SaleDate, &i.TotalTransactions, &i.TotalRevenue); err != nil {
+		if err := rows.Scan(
+			&i.SaleDate,
+			&i.TotalTransactions,
+			&i.TotalRevenue,
+			&i.GrossRevenue,
+			&i.TotalRefunds,
+			&i.TotalRounding,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -54,13 +86,34 @@ func (q *Queries) SalesByDate(ctx context.Context, arg SalesByDateParams) ([]Sal
 }
 
 const salesByPaymentMethod = `-- name: SalesByPaymentMethod :many
+WITH payments_by_method AS (
+  SELECT
+    sp.method,
+    COUNT(DISTINCT sp.sale_id) as transaction_count,
+    SUM(sp.amount) as total_amount
+  FROM sale_payments sp
+  JOIN sales s ON sp.sale_id = s.id
+  WHERE s.created_at >= $1 AND s.created_at <= $2
+    AND s.voided_at IS NULL
+    AND s.payment_status = 'paid'
+  GROUP BY sp.method
+),
+refunds_by_method AS (
+  SELECT
+    refund_method,
+    SUM(refund_amount) as total_refunds
+  FROM sale_returns
+  WHERE created_at >= $1 AND created_at <= $2
+  GROUP BY refund_method
+)
 SELECT 
-  payment_method,
-  COUNT(*) as transaction_count,
-  COALESCE(SUM(total_amount), 0) as total_amount
-FROM sales
-WHERE created_at >= $1 AND created_at <= $2
-GROUP BY payment_method
+  COALESCE(pm.method, rm.refund_method) as payment_method,
+  COALESCE(pm.transaction_count, 0)::bigint as transaction_count,
+  (COALESCE(pm.total_amount, 0) - COALESCE(rm.total_refunds, 0))::numeric as total_amount,
+  COALESCE(rm.total_refunds, 0)::numeric as total_refunds
+FROM payments_by_method pm
+FULL OUTER JOIN refunds_by_method rm
+  ON pm.method = COALESCE(rm.refund_method, '')
 ORDER BY total_amount DESC
 `
 
@@ -70,9 +123,10 @@ type SalesByPaymentMethodParams struct {
 }
 
 type SalesByPaymentMethodRow struct {
-	PaymentMethod    pgtype.Text `json:"payment_method"`
-	TransactionCount int64       `json:"transaction_count"`
-	TotalAmount      interface{} `json:"total_amount"`
+	PaymentMethod    pgtype.Text    `json:"payment_method"`
+	TransactionCount int64          `json:"transaction_count"`
+	TotalAmount      pgtype.Numeric `json:"total_amount"`
+	TotalRefunds     pgtype.Numeric `json:"total_refunds"`
 }
 
 func (q *Queries) SalesByPaymentMethod(ctx context.Context, arg SalesByPaymentMethodParams) ([]SalesByPaymentMethodRow, error) {
@@ -84,7 +138,97 @@ func (q *Queries) SalesByPaymentMethod(ctx context.Context, arg SalesByPaymentMe
 	items := []SalesByPaymentMethodRow{}
 	for rows.Next() {
 		var i SalesByPaymentMethodRow
-		if err := rows.Scan(&i.PaymentMethod, &i.TransactionCount, &i.TotalAmount); err != nil {
+		if err := rows.Scan(
+			&i.PaymentMethod,
+			&i.TransactionCount,
+			&i.TotalAmount,
+			&i.TotalRefunds,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const taxSummary = `-- name: TaxSummary :many
+WITH tax_by_rate AS (
+  SELECT
+    si.tax_rate,
+    si.tax_inclusive,
+    COUNT(DISTINCT s.id) as transaction_count,
+    SUM(si.subtotal - si.tax_amount) as taxable_amount,
+    SUM(si.tax_amount) as tax_amount
+  FROM sale_items si
+  JOIN sales s ON si.sale_id = s.id
+  WHERE s.created_at >= $1 AND s.created_at <= $2
+    AND s.voided_at IS NULL
+    AND s.payment_status = 'paid'
+  GROUP BY si.tax_rate, si.tax_inclusive
+),
+refunded_tax_by_rate AS (
+  SELECT
+    si.tax_rate,
+    si.tax_inclusive,
+    SUM(ROUND(ri.refund_amount * si.tax_amount / NULLIF(si.subtotal, 0), 2)) as tax_refunded
+  FROM sale_return_items ri
+  JOIN sale_returns r ON ri.return_id = r.id
+  JOIN sale_items si ON ri.sale_item_id = si.id
+  WHERE r.created_at >= $1 AND r.created_at <= $2
+  GROUP BY si.tax_rate, si.tax_inclusive
+)
+SELECT
+  COALESCE(tr.tax_rate, rt.tax_rate)::numeric as tax_rate,
+  COALESCE(tr.tax_inclusive, rt.tax_inclusive)::boolean as tax_inclusive,
+  COALESCE(tr.transaction_count, 0)::bigint as transaction_count,
+  COALESCE(tr.taxable_amount, 0)::numeric as taxable_amount,
+  COALESCE(tr.tax_amount, 0)::numeric as tax_amount,
+  COALESCE(rt.tax_refunded, 0)::numeric as tax_refunded,
+  (COALESCE(tr.tax_amount, 0) - COALESCE(rt.tax_refunded, 0))::numeric as net_tax
+FROM tax_by_rate tr
+FULL OUTER JOIN refunded_tax_by_rate rt
+  ON tr.tax_rate = rt.tax_rate AND tr.tax_inclusive = rt.tax_inclusive
+ORDER BY tax_rate DESC, tax_inclusive
+`
+
+type TaxSummaryParams struct {
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+	CreatedAt_2 pgtype.Timestamptz `json:"created_at_2"`
+}
+
+type TaxSummaryRow struct {
+	TaxRate          pgtype.Numeric `json:"tax_rate"`
+	TaxInclusive     bool           `json:"tax_inclusive"`
+	TransactionCount int64          `json:"transaction_count"`
+	TaxableAmount    pgtype.Numeric `json:"taxable_amount"`
+	TaxAmount        pgtype.Numeric `json:"tax_amount"`
+	TaxRefunded      pgtype.Numeric `json:"tax_refunded"`
+	NetTax           pgtype.Numeric `json:"net_tax"`
+}
+
+// Tax per rate charged. taxable_amount is the base the tax was charged on
+// (line subtotals net of tax); refunded tax is prorated from each refund.
+func (q *Queries) TaxSummary(ctx context.Context, arg TaxSummaryParams) ([]TaxSummaryRow, error) {
+	rows, err := q.db.Query(ctx, taxSummary, arg.CreatedAt, arg.CreatedAt_2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []TaxSummaryRow{}
+	for rows.Next() {
+		var i TaxSummaryRow
+		if err := rows.Scan(
+			&i.TaxRate,
+			&i.TaxInclusive,
+			&i.TransactionCount,
+			&i.TaxableAmount,
+			&i.TaxAmount,
+			&i.TaxRefunded,
+			&i.NetTax,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -100,12 +244,19 @@ SELECT
   p.id,
   p.name,
   p.sku,
-  SUM(si.qty) as total_qty_sold,
-  COALESCE(SUM(si.subtotal), 0) as total_revenue
+  (SUM(si.qty * si.unit_factor) - COALESCE(SUM(ri.returned_qty * si.unit_factor), 0))::numeric as total_qty_sold,
+  (COALESCE(SUM(si.subtotal), 0) - COALESCE(SUM(ri.refunded_amount), 0))::numeric as total_revenue
 FROM sale_items si
 JOIN products p ON si.product_id = p.id
 JOIN sales s ON si.sale_id = s.id
+LEFT JOIN (
+  SELECT sale_item_id, SUM(qty) as returned_qty, SUM(refund_amount) as refunded_amount
+  FROM sale_return_items
+  GROUP BY sale_item_id
+) ri ON ri.sale_item_id = si.id
 WHERE s.created_at >= $1 AND s.created_at <= $2
+  AND s.voided_at IS NULL
+  AND s.payment_status = 'paid'
 GROUP BY p.id, p.name, p.sku
 ORDER BY total_qty_sold DESC
 LIMIT $3
@@ -118,11 +269,11 @@ type TopProductsParams struct {
 }
 
 type TopProductsRow struct {
-	ID           int32       `json:"id"`
-	Name         string      `json:"name"`
-	Sku          pgtype.Text `json:"sku"`
-	TotalQtySold int64       `json:"total_qty_sold"`
-	TotalRevenue interface{} `json:"total_revenue"`
+	ID           int32          `json:"id"`
+	Name         string         `json:"name"`
+	Sku          pgtype.Text    `json:"sku"`
+	TotalQtySold pgtype.Numeric `json:"total_qty_sold"`
+	TotalRevenue pgtype.Numeric `json:"total_revenue"`
 }
 
 func (q *Queries) TopProducts(ctx context.Context, arg TopProductsParams) ([]TopProductsRow, error) {
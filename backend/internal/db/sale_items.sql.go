@@ -12,18 +12,30 @@ import (
 )
 
 const createSaleItem = `-- name: CreateSaleItem :one
-INSERT INTO sale_items (sale_id, product_id, qty, price, discount, subtotal)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, sale_id, product_id, qty, price, discount, subtotal
+INSERT INTO sale_items (sale_id, product_id, qty, price, discount, subtotal, list_price, override_price, override_by, tax_rate_id, tax_rate, tax_inclusive, tax_amount, promotion_discount, voucher_discount, unit_id, unit_name, unit_factor)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+RETURNING id, sale_id, product_id, qty, price, discount, subtotal, list_price, override_price, override_by, tax_rate_id, tax_rate, tax_inclusive, tax_amount, promotion_discount, voucher_discount, unit_id, unit_name, unit_factor
 `
 
 type CreateSaleItemParams struct {
-	SaleID    pgtype.Int4    `json:"sale_id"`
-	ProductID pgtype.Int4    `json:"product_id"`
-	Qty       int32          `json:"qty"`
-	Price     pgtype.Numeric `json:"price"`
-	Discount  pgtype.Numeric `json:"discount"`
-	Subtotal  pgtype.Numeric `json:"subtotal"`
+	SaleID            pgtype.Int4    `json:"sale_id"`
+	ProductID         pgtype.Int4    `json:"product_id"`
+	Qty               pgtype.Numeric `json:"qty"`
+	Price             pgtype.Numeric `json:"price"`
+	Discount          pgtype.Numeric `json:"discount"`
+	Subtotal          pgtype.Numeric `json:"subtotal"`
+	ListPrice         pgtype.Numeric `json:"list_price"`
+	OverridePrice     pgtype.Numeric `json:"override_price"`
+	OverrideBy        pgtype.Int4    `json:"override_by"`
+	TaxRateID         pgtype.Int4    `json:"tax_rate_id"`
+	TaxRate           pgtype.Numeric `json:"tax_rate"`
+	TaxInclusive      bool           `json:"tax_inclusive"`
+	TaxAmount         pgtype.Numeric `json:"tax_amount"`
+	PromotionDiscount pgtype.Numeric `json:"promotion_discount"`
+	VoucherDiscount   pgtype.Numeric `json:"voucher_discount"`
+	UnitID            pgtype.Int4    `json:"unit_id"`
+	UnitName          pgtype.Text    `json:"unit_name"`
+	UnitFactor        pgtype.Numeric `json:"unit_factor"`
 }
 
 func (q *Queries) CreateSaleItem(ctx context.Context, arg CreateSaleItemParams) (SaleItem, error) {
@@ -34,6 +46,18 @@ func (q *Queries) CreateSaleItem(ctx context.Context, arg CreateSaleItemParams)
 		arg.Price,
 		arg.Discount,
 		arg.Subtotal,
+		arg.ListPrice,
+		arg.OverridePrice,
+		arg.OverrideBy,
+		arg.TaxRateID,
+		arg.TaxRate,
+		arg.TaxInclusive,
+		arg.TaxAmount,
+		arg.PromotionDiscount,
+		arg.VoucherDiscount,
+		arg.UnitID,
+		arg.UnitName,
+		arg.UnitFactor,
 	)
 	var i SaleItem
 	err := row.Scan(
@@ -44,12 +68,24 @@ func (q *Queries) CreateSaleItem(ctx context.Context, arg CreateSaleItemParams)
 		&i.Price,
 		&i.Discount,
 		&i.Subtotal,
+		&i.ListPrice,
+		&i.OverridePrice,
+		&i.OverrideBy,
+		&i.TaxRateID,
+		&i.TaxRate,
+		&i.TaxInclusive,
+		&i.TaxAmount,
+		&i.PromotionDiscount,
+		&i.VoucherDiscount,
+		&i.UnitID,
+		&i.UnitName,
+		&i.UnitFactor,
 	)
 	return i, err
 }
 
 const getSaleItemsByProductID = `-- name: GetSaleItemsByProductID :many
-SELECT si.id, si.sale_id, si.product_id, si.qty, si.price, si.discount, si.subtotal, s.invoice_no, s.created_at as sale_date
+SELECT si.id, si.sale_id, si.product_id, si.qty, si.price, si.discount, si.subtotal, si.list_price, si.override_price, si.override_by, si.tax_rate_id, si.tax_rate, si.tax_inclusive, si.tax_amount, si.promotion_discount, si.voucher_discount, si.unit_id, si.unit_name, si.unit_factor, s.invoice_no, s.created_at as sale_date
 FROM sale_items si
 JOIN sales s ON si.sale_id = s.id
 WHERE si.product_id = $1
@@ -57,15 +93,27 @@ ORDER BY s.created_at DESC
 `
 
 type GetSaleItemsByProductIDRow struct {
-	ID        int32              `json:"id"`
-	SaleID    pgtype.Int4        `json:"sale_id"`
-	ProductID pgtype.Int4        `json:"product_id"`
-	Qty       int32              `json:"qty"`
-	Price     pgtype.Numeric     `json:"price"`
-	Discount  pgtype.Numeric     `json:"discount"`
-	Subtotal  pgtype.Numeric     `json:"subtotal"`
-	InvoiceNo string             `json:"invoice_no"`
-	SaleDate  pgtype.Timestamptz `json:"sale_date"`
+	ID                int32              `json:"id"`
+	SaleID            pgtype.Int4        `json:"sale_id"`
+	ProductID         pgtype.Int4        `json:"product_id"`
+	Qty               pgtype.Numeric     `json:"qty"`
+	Price             pgtype.Numeric     `json:"price"`
+	Discount          pgtype.Numeric     `json:"discount"`
+	Subtotal          pgtype.Numeric     `json:"subtotal"`
+	ListPrice         pgtype.Numeric     `json:"list_price"`
+	OverridePrice     pgtype.Numeric     `json:"override_price"`
+	OverrideBy        pgtype.Int4        `json:"override_by"`
+	TaxRateID         pgtype.Int4        `json:"tax_rate_id"`
+	TaxRate           pgtype.Numeric     `json:"tax_rate"`
+	TaxInclusive      bool               `json:"tax_inclusive"`
+	TaxAmount         pgtype.Numeric     `json:"tax_amount"`
+	PromotionDiscount pgtype.Numeric     `json:"promotion_discount"`
+	VoucherDiscount   pgtype.Numeric     `json:"voucher_discount"`
+	UnitID            pgtype.Int4        `json:"unit_id"`
+	UnitName          pgtype.Text        `json:"unit_name"`
+	UnitFactor        pgtype.Numeric     `json:"unit_factor"`
+	InvoiceNo         string             `json:"invoice_no"`
+	SaleDate          pgtype.Timestamptz `json:"sale_date"`
 }
 
 func (q *Queries) GetSaleItemsByProductID(ctx context.Context, productID pgtype.Int4) ([]GetSaleItemsByProductIDRow, error) {
@@ -85,6 +133,18 @@ func (q *Queries) GetSaleItemsByProductID(ctx context.Context, productID pgtype.
 			&i.Price,
 			&i.Discount,
 			&i.Subtotal,
+			&i.ListPrice,
+			&i.OverridePrice,
+			&i.OverrideBy,
+			&i.TaxRateID,
+			&i.TaxRate,
+			&i.TaxInclusive,
+			&i.TaxAmount,
+			&i.PromotionDiscount,
+			&i.VoucherDiscount,
+			&i.UnitID,
+			&i.UnitName,
+			&i.UnitFactor,
 			&i.InvoiceNo,
 			&i.SaleDate,
 		); err != nil {
@@ -99,7 +159,7 @@ func (q *Queries) GetSaleItemsByProductID(ctx context.Context, productID pgtype.
 }
 
 const getSaleItemsBySaleID = `-- name: GetSaleItemsBySaleID :many
-SELECT si.id, si.sale_id, si.product_id, si.qty, si.price, si.discount, si.subtotal, p.name as product_name, p.sku
+SELECT si.id, si.sale_id, si.product_id, si.qty, si.price, si.discount, si.subtotal, si.list_price, si.override_price, si.override_by, si.tax_rate_id, si.tax_rate, si.tax_inclusive, si.tax_amount, si.promotion_discount, si.voucher_discount, si.unit_id, si.unit_name, si.unit_factor, p.name as product_name, p.sku
 FROM sale_items si
 JOIN products p ON si.product_id = p.id
 WHERE si.sale_id = $1
@@ -107,15 +167,27 @@ ORDER BY si.id
 `
 
 type GetSaleItemsBySaleIDRow struct {
-	ID          int32          `json:"id"`
-	SaleID      pgtype.Int4    `json:"sale_id"`
-	ProductID   pgtype.Int4    `json:"product_id"`
-	Qty         int32          `json:"qty"`
-	Price       pgtype.Numeric `json:"price"`
-	Discount    pgtype.Numeric `json:"discount"`
-	Subtotal    pgtype.Numeric `json:"subtotal"`
-	ProductName string         `json:"product_name"`
-	Sku         pgtype.Text    `json:"sku"`
+	ID                int32          `json:"id"`
+	SaleID            pgtype.Int4    `json:"sale_id"`
+	ProductID         pgtype.Int4    `json:"product_id"`
+	Qty               pgtype.Numeric `json:"qty"`
+	Price             pgtype.Numeric `json:"price"`
+	Discount          pgtype.Numeric `json:"discount"`
+	Subtotal          pgtype.Numeric `json:"subtotal"`
+	ListPrice         pgtype.Numeric `json:"list_price"`
+	OverridePrice     pgtype.Numeric `json:"override_price"`
+	OverrideBy        pgtype.Int4    `json:"override_by"`
+	TaxRateID         pgtype.Int4    `json:"tax_rate_id"`
+	TaxRate           pgtype.Numeric `json:"tax_rate"`
+	TaxInclusive      bool           `json:"tax_inclusive"`
+	TaxAmount         pgtype.Numeric `json:"tax_amount"`
+	PromotionDiscount pgtype.Numeric `json:"promotion_discount"`
+	VoucherDiscount   pgtype.Numeric `json:"voucher_discount"`
+	UnitID            pgtype.Int4    `json:"unit_id"`
+	UnitName          pgtype.Text    `json:"unit_name"`
+	UnitFactor        pgtype.Numeric `json:"unit_factor"`
+	ProductName       string         `json:"product_name"`
+	Sku               pgtype.Text    `json:"sku"`
 }
 
 func (q *Queries) GetSaleItemsBySaleID(ctx context.Context, saleID pgtype.Int4) ([]GetSaleItemsBySaleIDRow, error) {
@@ -135,6 +207,93 @@ func (q *Queries) GetSaleItemsBySaleID(ctx context.Context, saleID pgtype.Int4)
 			&i.Price,
 			&i.Discount,
 			&i.Subtotal,
+			&i.ListPrice,
+			&i.OverridePrice,
+			&i.OverrideBy,
+			&i.TaxRateID,
+			&i.TaxRate,
+			&i.TaxInclusive,
+			&i.TaxAmount,
+			&i.PromotionDiscount,
+			&i.VoucherDiscount,
+			&i.UnitID,
+			&i.UnitName,
+			&i.UnitFactor,
+			&i.ProductName,
+			&i.Sku,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSaleItemsBySaleIDs = `-- name: GetSaleItemsBySaleIDs :many
+SELECT si.id, si.sale_id, si.product_id, si.qty, si.price, si.discount, si.subtotal, si.list_price, si.override_price, si.override_by, si.tax_rate_id, si.tax_rate, si.tax_inclusive, si.tax_amount, si.promotion_discount, si.voucher_discount, si.unit_id, si.unit_name, si.unit_factor, p.name as product_name, p.sku
+FROM sale_items si
+JOIN products p ON si.product_id = p.id
+WHERE si.sale_id = ANY($1::int[])
+ORDER BY si.sale_id, si.id
+`
+
+type GetSaleItemsBySaleIDsRow struct {
+	ID                int32          `json:"id"`
+	SaleID            pgtype.Int4    `json:"sale_id"`
+	ProductID         pgtype.Int4    `json:"product_id"`
+	Qty               pgtype.Numeric `json:"qty"`
+	Price             pgtype.Numeric `json:"price"`
+	Discount          pgtype.Numeric `json:"discount"`
+	Subtotal          pgtype.Numeric `json:"subtotal"`
+	ListPrice         pgtype.Numeric `json:"list_price"`
+	OverridePrice     pgtype.Numeric `json:"override_price"`
+	OverrideBy        pgtype.Int4    `json:"override_by"`
+	TaxRateID         pgtype.Int4    `json:"tax_rate_id"`
+	TaxRate           pgtype.Numeric `json:"tax_rate"`
+	TaxInclusive      bool           `json:"tax_inclusive"`
+	TaxAmount         pgtype.Numeric `json:"tax_amount"`
+	PromotionDiscount pgtype.Numeric `json:"promotion_discount"`
+	VoucherDiscount   pgtype.Numeric `json:"voucher_discount"`
+	UnitID            pgtype.Int4    `json:"unit_id"`
+	UnitName          pgtype.Text    `json:"unit_name"`
+	UnitFactor        pgtype.Numeric `json:"unit_factor"`
+	ProductName       string         `json:"product_name"`
+	Sku               pgtype.Text    `json:"sku"`
+}
+
+// Items of several sales at once, for building pages of sales.
+func (q *Queries) GetSaleItemsBySaleIDs(ctx context.Context, saleIds []int32) ([]GetSaleItemsBySaleIDsRow, error) {
+	rows, err := q.db.Query(ctx, getSaleItemsBySaleIDs, saleIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetSaleItemsBySaleIDsRow{}
+	for rows.Next() {
+		var i GetSaleItemsBySaleIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SaleID,
+			&i.ProductID,
+			&i.Qty,
+			&i.Price,
+			&i.Discount,
+			&i.Subtotal,
+			&i.ListPrice,
+			&i.OverridePrice,
+			&i.OverrideBy,
+			&i.TaxRateID,
+			&i.TaxRate,
+			&i.TaxInclusive,
+			&i.TaxAmount,
+			&i.PromotionDiscount,
+			&i.VoucherDiscount,
+			&i.UnitID,
+			&i.UnitName,
+			&i.UnitFactor,
 			&i.ProductName,
 			&i.Sku,
 		); err != nil {
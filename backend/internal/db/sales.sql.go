@@ -11,19 +11,114 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countSales = `-- name: CountSales :one
+SELECT COUNT(*)
+FROM sales s
+WHERE ($1::timestamptz IS NULL OR s.created_at >= $1)
+  AND ($2::timestamptz IS NULL OR s.created_at < $2)
+  AND ($3::int IS NULL OR s.user_id = $3)
+  AND ($4::text IS NULL OR EXISTS (
+    SELECT 1 FROM sale_payments p
+    WHERE p.sale_id = s.id AND lower(p.method) = lower($4)
+  ))
+  AND ($5::text IS NULL OR s.invoice_no LIKE $5 || '%')
+  AND ($6::numeric IS NULL OR s.total_amount >= $6)
+  AND ($7::numeric IS NULL OR s.total_amount <= $7)
+  AND ($8::int IS NULL OR EXISTS (
+    SELECT 1 FROM sale_items i
+    WHERE i.sale_id = s.id AND i.product_id = $8
+  ))
+`
+
+type CountSalesParams struct {
+	CreatedFrom   pgtype.Timestamptz `json:"created_from"`
+	CreatedTo     pgtype.Timestamptz `json:"created_to"`
+	CashierID     pgtype.Int4        `json:"cashier_id"`
+	PaymentMethod pgtype.Text        `json:"payment_method"`
+	InvoicePrefix pgtype.Text        `json:"invoice_prefix"`
+	MinTotal      pgtype.Numeric     `json:"min_total"`
+	MaxTotal      pgtype.Numeric     `json:"max_total"`
+	ProductID     pgtype.Int4        `json:"product_id"`
+}
+
+// Counts the sales ListSales pages through, ignoring the cursor.
+func (q *Queries) CountSales(ctx context.Context, arg CountSalesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSales,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CashierID,
+		arg.PaymentMethod,
+		arg.InvoicePrefix,
+		arg.MinTotal,
+		arg.MaxTotal,
+		arg.ProductID,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const completePendingSale = `-- name: CompletePendingSale :one
+UPDATE sales
+SET payment_status = 'paid'
+WHERE id = $1 AND payment_status = 'pending'
+RETURNING id, invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method, created_at, voided_at, voided_by, void_reason, subtotal_amount, tax_amount, voucher_code, voucher_discount, customer_id, points_earned, points_redeemed, shift_id, rounding_amount, payment_status
+`
+
+// Marks a sale awaiting payment as paid. Matches nothing once the sale has
+// left pending, so a charge is only ever applied once.
+func (q *Queries) CompletePendingSale(ctx context.Context, id int32) (Sale, error) {
+	row := q.db.QueryRow(ctx, completePendingSale, id)
+	var i Sale
+	err := row.Scan(
+		&i.ID,
+		&i.InvoiceNo,
+		&i.UserID,
+		&i.TotalAmount,
+		&i.PaidAmount,
+		&i.ChangeAmount,
+		&i.PaymentMethod,
+		&i.CreatedAt,
+		&i.VoidedAt,
+		&i.VoidedBy,
+		&i.VoidReason,
+		&i.SubtotalAmount,
+		&i.TaxAmount,
+		&i.VoucherCode,
+		&i.VoucherDiscount,
+		&i.CustomerID,
+		&i.PointsEarned,
+		&i.PointsRedeemed,
+		&i.ShiftID,
+		&i.RoundingAmount,
+		&i.PaymentStatus,
+	)
+	return i, err
+}
+
 const createSale = `-- name: CreateSale :one
-INSERT INTO sales (invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method)
-VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING id, invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method, created_at
+INSERT INTO sales (invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method, subtotal_amount, tax_amount, voucher_code, voucher_discount, customer_id, points_earned, points_redeemed, shift_id, rounding_amount, payment_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+RETURNING id, invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method, created_at, voided_at, voided_by, void_reason, subtotal_amount, tax_amount, voucher_code, voucher_discount, customer_id, points_earned, points_redeemed, shift_id, rounding_amount, payment_status
 `
 
 type CreateSaleParams struct {
-	InvoiceNo     string         `json:"invoice_no"`
-	UserID        pgtype.Int4    `json:"user_id"`
-	TotalAmount   pgtype.Numeric `json:"total_amount"`
-	PaidAmount    pgtype.Numeric `json:"paid_amount"`
-	ChangeAmount  pgtype.Numeric `json:"change_amount"`
-	PaymentMethod pgtype.Text    `json:"payment_method"`
+	InvoiceNo       string         `json:"invoice_no"`
+	UserID          pgtype.Int4    `json:"user_id"`
+	TotalAmount     pgtype.Numeric `json:"total_amount"`
+	PaidAmount      pgtype.Numeric `json:"paid_amount"`
+	ChangeAmount    pgtype.Numeric `json:"change_amount"`
+	PaymentMethod   pgtype.Text    `json:"payment_method"`
+	SubtotalAmount  pgtype.Numeric `json:"subtotal_amount"`
+	TaxAmount       pgtype.Numeric `json:"tax_amount"`
+	VoucherCode     pgtype.Text    `json:"voucher_code"`
+	VoucherDiscount pgtype.Numeric `json:"voucher_discount"`
+	CustomerID      pgtype.Int4    `json:"customer_id"`
+	PointsEarned    int64          `json:"points_earned"`
+	PointsRedeemed  int64          `json:"points_redeemed"`
+	ShiftID         pgtype.Int4    `json:"shift_id"`
+	RoundingAmount  pgtype.Numeric `json:"rounding_amount"`
+	PaymentStatus   string         `json:"payment_status"`
 }
 
 func (q *Queries) CreateSale(ctx context.Context, arg CreateSaleParams) (Sale, error) {
@@ -34,6 +129,16 @@ func (q *Queries) CreateSale(ctx context.Context, arg CreateSaleParams) (Sale, e
 		arg.PaidAmount,
 		arg.ChangeAmount,
 		arg.PaymentMethod,
+		arg.SubtotalAmount,
+		arg.TaxAmount,
+		arg.VoucherCode,
+		arg.VoucherDiscount,
+		arg.CustomerID,
+		arg.PointsEarned,
+		arg.PointsRedeemed,
+		arg.ShiftID,
+		arg.RoundingAmount,
+		arg.PaymentStatus,
 	)
 	var i Sale
 	err := row.Scan(
@@ -45,27 +150,95 @@ func (q *Queries) CreateSale(ctx context.Context, arg CreateSaleParams) (Sale, e
 		&i.ChangeAmount,
 		&i.PaymentMethod,
 		&i.CreatedAt,
+		&i.VoidedAt,
+		&i.VoidedBy,
+		&i.VoidReason,
+		&i.SubtotalAmount,
+		&i.TaxAmount,
+		&i.VoucherCode,
+		&i.VoucherDiscount,
+		&i.CustomerID,
+		&i.PointsEarned,
+		&i.PointsRedeemed,
+		&i.ShiftID,
+		&i.RoundingAmount,
+		&i.PaymentStatus,
+	)
+	return i, err
+}
+
+const failPendingSale = `-- name: FailPendingSale :one
+UPDATE sales
+SET payment_status = 'failed', voided_at = now(), void_reason = $2
+WHERE id = $1 AND payment_status = 'pending'
+RETURNING id, invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method, created_at, voided_at, voided_by, void_reason, subtotal_amount, tax_amount, voucher_code, voucher_discount, customer_id, points_earned, points_redeemed, shift_id, rounding_amount, payment_status
+`
+
+type FailPendingSaleParams struct {
+	ID         int32       `json:"id"`
+	VoidReason pgtype.Text `json:"void_reason"`
+}
+
+// Voids a sale whose payment did not go through.
+func (q *Queries) FailPendingSale(ctx context.Context, arg FailPendingSaleParams) (Sale, error) {
+	row := q.db.QueryRow(ctx, failPendingSale, arg.ID, arg.VoidReason)
+	var i Sale
+	err := row.Scan(
+		&i.ID,
+		&i.InvoiceNo,
+		&i.UserID,
+		&i.TotalAmount,
+		&i.PaidAmount,
+		&i.ChangeAmount,
+		&i.PaymentMethod,
+		&i.CreatedAt,
+		&i.VoidedAt,
+		&i.VoidedBy,
+		&i.VoidReason,
+		&i.SubtotalAmount,
+		&i.TaxAmount,
+		&i.VoucherCode,
+		&i.VoucherDiscount,
+		&i.CustomerID,
+		&i.PointsEarned,
+		&i.PointsRedeemed,
+		&i.ShiftID,
+		&i.RoundingAmount,
+		&i.PaymentStatus,
 	)
 	return i, err
 }
 
 const getSaleByID = `-- name: GetSaleByID :one
-SELECT s.id, s.invoice_no, s.user_id, s.total_amount, s.paid_amount, s.change_amount, s.payment_method, s.created_at, u.username as cashier_name
+SELECT s.id, s.invoice_no, s.user_id, s.total_amount, s.paid_amount, s.change_amount, s.payment_method, s.created_at, s.voided_at, s.voided_by, s.void_reason, s.subtotal_amount, s.tax_amount, s.voucher_code, s.voucher_discount, s.customer_id, s.points_earned, s.points_redeemed, s.shift_id, s.rounding_amount, s.payment_status, u.username as cashier_name
 FROM sales s
 LEFT JOIN users u ON s.user_id = u.id
 WHERE s.id = $1 LIMIT 1
 `
 
 type GetSaleByIDRow struct {
-	ID            int32              `json:"id"`
-	InvoiceNo     string             `json:"invoice_no"`
-	UserID        pgtype.Int4        `json:"user_id"`
-	TotalAmount   pgtype.Numeric     `json:"total_amount"`
-	PaidAmount    pgtype.Numeric     `json:"paid_amount"`
-	ChangeAmount  pgtype.Numeric     `json:"change_amount"`
-	PaymentMethod pgtype.Text        `json:"payment_method"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	CashierName   pgtype.Text        `json:"cashier_name"`
+	ID              int32              `json:"id"`
+	InvoiceNo       string             `json:"invoice_no"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	TotalAmount     pgtype.Numeric     `json:"total_amount"`
+	PaidAmount      pgtype.Numeric     `json:"paid_amount"`
+	ChangeAmount    pgtype.Numeric     `json:"change_amount"`
+	PaymentMethod   pgtype.Text        `json:"payment_method"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	VoidedAt        pgtype.Timestamptz `json:"voided_at"`
+	VoidedBy        pgtype.Int4        `json:"voided_by"`
+	VoidReason      pgtype.Text        `json:"void_reason"`
+	SubtotalAmount  pgtype.Numeric     `json:"subtotal_amount"`
+	TaxAmount       pgtype.Numeric     `json:"tax_amount"`
+	VoucherCode     pgtype.Text        `json:"voucher_code"`
+	VoucherDiscount pgtype.Numeric     `json:"voucher_discount"`
+	CustomerID      pgtype.Int4        `json:"customer_id"`
+	PointsEarned    int64              `json:"points_earned"`
+	PointsRedeemed  int64              `json:"points_redeemed"`
+	ShiftID         pgtype.Int4        `json:"shift_id"`
+	RoundingAmount  pgtype.Numeric     `json:"rounding_amount"`
+	PaymentStatus   string             `json:"payment_status"`
+	CashierName     pgtype.Text        `json:"cashier_name"`
 }
 
 func (q *Queries) GetSaleByID(ctx context.Context, id int32) (GetSaleByIDRow, error) {
@@ -80,28 +253,54 @@ func (q *Queries) GetSaleByID(ctx context.Context, id int32) (GetSaleByIDRow, er
 		&i.ChangeAmount,
 		&i.PaymentMethod,
 		&i.CreatedAt,
+		&i.VoidedAt,
+		&i.VoidedBy,
+		&i.VoidReason,
+		&i.SubtotalAmount,
+		&i.TaxAmount,
+		&i.VoucherCode,
+		&i.VoucherDiscount,
+		&i.CustomerID,
+		&i.PointsEarned,
+		&i.PointsRedeemed,
+		&i.ShiftID,
+		&i.RoundingAmount,
+		&i.PaymentStatus,
 		&i.CashierName,
 	)
 	return i, err
 }
 
 const getSaleByInvoice = `-- name: GetSaleByInvoice :one
-SELECT s.id, s.invoice_no, s.user_id, s.total_amount, s.paid_amount, s.change_amount, s.payment_method, s.created_at, u.username as cashier_name
+SELECT s.id, s.invoice_no, s.user_id, s.total_amount, s.paid_amount, s.change_amount, s.payment_method, s.created_at, s.voided_at, s.voided_by, s.void_reason, s.subtotal_amount, s.tax_amount, s.voucher_code, s.voucher_discount, s.customer_id, s.points_earned, s.points_redeemed, s.shift_id, s.rounding_amount, s.payment_status, u.username as cashier_name
 FROM sales s
 LEFT JOIN users u ON s.user_id = u.id
 WHERE s.invoice_no = $1 LIMIT 1
 `
 
 type GetSaleByInvoiceRow struct {
-	ID            int32              `json:"id"`
-	InvoiceNo     string             `json:"invoice_no"`
-	UserID        pgtype.Int4        `json:"user_id"`
-	TotalAmount   pgtype.Numeric     `json:"total_amount"`
-	PaidAmount    pgtype.Numeric     `json:"paid_amount"`
-	ChangeAmount  pgtype.Numeric     `json:"change_amount"`
-	PaymentMethod pgtype.Text        `json:"payment_method"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	CashierName   pgtype.Text        `json:"cashier_name"`
+	ID              int32              `json:"id"`
+	InvoiceNo       string             `json:"invoice_no"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	TotalAmount     pgtype.Numeric     `json:"total_amount"`
+	PaidAmount      pgtype.Numeric     `json:"paid_amount"`
+	ChangeAmount    pgtype.Numeric     `json:"change_amount"`
+	PaymentMethod   pgtype.Text        `json:"payment_method"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	VoidedAt        pgtype.Timestamptz `json:"voided_at"`
+	VoidedBy        pgtype.Int4        `json:"voided_by"`
+	VoidReason      pgtype.Text        `json:"void_reason"`
+	SubtotalAmount  pgtype.Numeric     `json:"subtotal_amount"`
+	TaxAmount       pgtype.Numeric     `json:"tax_amount"`
+	VoucherCode     pgtype.Text        `json:"voucher_code"`
+	VoucherDiscount pgtype.Numeric     `json:"voucher_discount"`
+	CustomerID      pgtype.Int4        `json:"customer_id"`
+	PointsEarned    int64              `json:"points_earned"`
+	PointsRedeemed  int64              `json:"points_redeemed"`
+	ShiftID         pgtype.Int4        `json:"shift_id"`
+	RoundingAmount  pgtype.Numeric     `json:"rounding_amount"`
+	PaymentStatus   string             `json:"payment_status"`
+	CashierName     pgtype.Text        `json:"cashier_name"`
 }
 
 func (q *Queries) GetSaleByInvoice(ctx context.Context, invoiceNo string) (GetSaleByInvoiceRow, error) {
@@ -116,18 +315,77 @@ func (q *Queries) GetSaleByInvoice(ctx context.Context, invoiceNo string) (GetSa
 		&i.ChangeAmount,
 		&i.PaymentMethod,
 		&i.CreatedAt,
+		&i.VoidedAt,
+		&i.VoidedBy,
+		&i.VoidReason,
+		&i.SubtotalAmount,
+		&i.TaxAmount,
+		&i.VoucherCode,
+		&i.VoucherDiscount,
+		&i.CustomerID,
+		&i.PointsEarned,
+		&i.PointsRedeemed,
+		&i.ShiftID,
+		&i.RoundingAmount,
+		&i.PaymentStatus,
 		&i.CashierName,
 	)
 	return i, err
 }
 
+const getSaleForUpdate = `-- name: GetSaleForUpdate :one
+SELECT id, invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method, created_at, voided_at, voided_by, void_reason, subtotal_amount, tax_amount, voucher_code, voucher_discount, customer_id, points_earned, points_redeemed, shift_id, rounding_amount, payment_status FROM sales
+WHERE id = $1 LIMIT 1
+FOR UPDATE
+`
+
+func (q *Queries) GetSaleForUpdate(ctx context.Context, id int32) (Sale, error) {
+	row := q.db.QueryRow(ctx, getSaleForUpdate, id)
+	var i Sale
+	err := row.Scan(
+		&i.ID,
+		&i.InvoiceNo,
+		&i.UserID,
+		&i.TotalAmount,
+		&i.PaidAmount,
+		&i.ChangeAmount,
+		&i.PaymentMethod,
+		&i.CreatedAt,
+		&i.VoidedAt,
+		&i.VoidedBy,
+		&i.VoidReason,
+		&i.SubtotalAmount,
+		&i.TaxAmount,
+		&i.VoucherCode,
+		&i.VoucherDiscount,
+		&i.CustomerID,
+		&i.PointsEarned,
+		&i.PointsRedeemed,
+		&i.ShiftID,
+		&i.RoundingAmount,
+		&i.PaymentStatus,
+	)
+	return i, err
+}
+
 const getSalesStats = `-- name: GetSalesStats :one
 SELECT 
   COUNT(*) as total_sales,
-  COALESCE(SUM(total_amount), 0) as total_revenue,
-  COALESCE(AVG(total_amount), 0) as avg_sale_amount
+  (COALESCE(SUM(total_amount), 0) - (
+    SELECT COALESCE(SUM(r.refund_amount), 0) FROM sale_returns r
+    WHERE r.created_at >= $1 AND r.created_at <= $2
+  ))::numeric as total_revenue,
+  COALESCE(AVG(total_amount), 0) as avg_sale_amount,
+  COALESCE(SUM(total_amount), 0)::numeric as gross_revenue,
+  (
+    SELECT COALESCE(SUM(r.refund_amount), 0) FROM sale_returns r
+    WHERE r.created_at >= $1 AND r.created_at <= $2
+  )::numeric as total_refunds,
+  COALESCE(SUM(rounding_amount), 0)::numeric as total_rounding
 FROM sales
 WHERE created_at >= $1 AND created_at <= $2
+  AND voided_at IS NULL
+  AND payment_status = 'paid'
 `
 
 type GetSalesStatsParams struct {
@@ -136,45 +394,228 @@ type GetSalesStatsParams struct {
 }
 
 type GetSalesStatsRow struct {
-	TotalSales    int64       `json:"total_sales"`
-	TotalRevenue  interface{} `json:"total_revenue"`
-	AvgSaleAmount interface{} `json:"avg_sale_amount"`
+	TotalSales    int64          `json:"total_sales"`
+	TotalRevenue  pgtype.Numeric `json:"total_revenue"`
+	AvgSaleAmount interface{}    `json:"avg_sale_amount"`
+	GrossRevenue  pgtype.Numeric `json:"gross_revenue"`
+	TotalRefunds  pgtype.Numeric `json:"total_refunds"`
+	TotalRounding pgtype.Numeric `json:"total_rounding"`
 }
 
 func (q *Queries) GetSalesStats(ctx context.Context, arg GetSalesStatsParams) (GetSalesStatsRow, error) {
 	row := q.db.QueryRow(ctx, getSalesStats, arg.CreatedAt, arg.CreatedAt_2)
 	var i GetSalesStatsRow
-	err := row.Scan(&i.TotalSales, &i.TotalRevenue, &i.AvgSaleAmount)
+	err := row.Scan(
+		&i.TotalSales,
+		&i.TotalRevenue,
+		&i.AvgSaleAmount,
+		&i.GrossRevenue,
+		&i.TotalRefunds,
+		&i.TotalRounding,
+	)
 	return i, err
 }
 
+const listSaleJournal = `-- name: ListSaleJournal :many
+SELECT s.id as sale_id, s.invoice_no, s.created_at, s.voided_at, u.username as cashier_name,
+  si.id as item_id, p.name as product_name, p.sku, si.qty,
+  COALESCE(si.unit_name, p.unit) as unit, si.price, si.discount,
+  si.promotion_discount, si.voucher_discount, si.tax_amount, si.subtotal
+FROM sales s
+JOIN sale_items si ON si.sale_id = s.id
+JOIN products p ON si.product_id = p.id
+LEFT JOIN users u ON s.user_id = u.id
+WHERE s.payment_status <> 'pending'
+  AND ($1::timestamptz IS NULL OR s.created_at >= $1)
+  AND ($2::timestamptz IS NULL OR s.created_at < $2)
+  AND ($3::int IS NULL OR s.user_id = $3)
+  AND ($4::text IS NULL OR EXISTS (
+    SELECT 1 FROM sale_payments p
+    WHERE p.sale_id = s.id AND lower(p.method) = lower($4)
+  ))
+  AND ($5::text IS NULL OR s.invoice_no LIKE $5 || '%')
+  AND ($6::numeric IS NULL OR s.total_amount >= $6)
+  AND ($7::numeric IS NULL OR s.total_amount <= $7)
+  AND ($8::int IS NULL OR EXISTS (
+    SELECT 1 FROM sale_items i
+    WHERE i.sale_id = s.id AND i.product_id = $8
+  ))
+  AND ($9::timestamptz IS NULL
+    OR (s.created_at, s.id, si.id) > ($9, $10::int, $11::int))
+ORDER BY s.created_at, s.id, si.id
+LIMIT $12
+`
+
+type ListSaleJournalParams struct {
+	CreatedFrom     pgtype.Timestamptz `json:"created_from"`
+	CreatedTo       pgtype.Timestamptz `json:"created_to"`
+	CashierID       pgtype.Int4        `json:"cashier_id"`
+	PaymentMethod   pgtype.Text        `json:"payment_method"`
+	InvoicePrefix   pgtype.Text        `json:"invoice_prefix"`
+	MinTotal        pgtype.Numeric     `json:"min_total"`
+	MaxTotal        pgtype.Numeric     `json:"max_total"`
+	ProductID       pgtype.Int4        `json:"product_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorSaleID    pgtype.Int4        `json:"cursor_sale_id"`
+	CursorItemID    pgtype.Int4        `json:"cursor_item_id"`
+	Limit           int32              `json:"limit"`
+}
+
+type ListSaleJournalRow struct {
+	SaleID            int32              `json:"sale_id"`
+	InvoiceNo         string             `json:"invoice_no"`
+	CreatedAt         pgtype.Timestamptz `json:"created_at"`
+	VoidedAt          pgtype.Timestamptz `json:"voided_at"`
+	CashierName       pgtype.Text        `json:"cashier_name"`
+	ItemID            int32              `json:"item_id"`
+	ProductName       string             `json:"product_name"`
+	Sku               pgtype.Text        `json:"sku"`
+	Qty               pgtype.Numeric     `json:"qty"`
+	Unit              pgtype.Text        `json:"unit"`
+	Price             pgtype.Numeric     `json:"price"`
+	Discount          pgtype.Numeric     `json:"discount"`
+	PromotionDiscount pgtype.Numeric     `json:"promotion_discount"`
+	VoucherDiscount   pgtype.Numeric     `json:"voucher_discount"`
+	TaxAmount         pgtype.Numeric     `json:"tax_amount"`
+	Subtotal          pgtype.Numeric     `json:"subtotal"`
+}
+
+// One row per sale item, oldest first, for the sales export. Sales awaiting
+// payment are left out; otherwise the filters match ListSales. Rows are read
+// in batches keyed on (created_at, sale_id, item_id), with the cursor
+// arguments taken from the last row of a batch.
+func (q *Queries) ListSaleJournal(ctx context.Context, arg ListSaleJournalParams) ([]ListSaleJournalRow, error) {
+	rows, err := q.db.Query(ctx, listSaleJournal,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CashierID,
+		arg.PaymentMethod,
+		arg.InvoicePrefix,
+		arg.MinTotal,
+		arg.MaxTotal,
+		arg.ProductID,
+		arg.CursorCreatedAt,
+		arg.CursorSaleID,
+		arg.CursorItemID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListSaleJournalRow{}
+	for rows.Next() {
+		var i ListSaleJournalRow
+		if err := rows.Scan(
+			&i.SaleID,
+			&i.InvoiceNo,
+			&i.CreatedAt,
+			&i.VoidedAt,
+			&i.CashierName,
+			&i.ItemID,
+			&i.ProductName,
+			&i.Sku,
+			&i.Qty,
+			&i.Unit,
+			&i.Price,
+			&i.Discount,
+			&i.PromotionDiscount,
+			&i.VoucherDiscount,
+			&i.TaxAmount,
+			&i.Subtotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSales = `-- name: ListSales :many
-SELECT s.id, s.invoice_no, s.user_id, s.total_amount, s.paid_amount, s.change_amount, s.payment_method, s.created_at, u.username as cashier_name
+SELECT s.id, s.invoice_no, s.user_id, s.total_amount, s.paid_amount, s.change_amount, s.payment_method, s.created_at, s.voided_at, s.voided_by, s.void_reason, s.subtotal_amount, s.tax_amount, s.voucher_code, s.voucher_discount, s.customer_id, s.points_earned, s.points_redeemed, s.shift_id, s.rounding_amount, s.payment_status, u.username as cashier_name
 FROM sales s
 LEFT JOIN users u ON s.user_id = u.id
-ORDER BY s.created_at DESC
-LIMIT $1 OFFSET $2
+WHERE ($1::timestamptz IS NULL OR s.created_at >= $1)
+  AND ($2::timestamptz IS NULL OR s.created_at < $2)
+  AND ($3::int IS NULL OR s.user_id = $3)
+  AND ($4::text IS NULL OR EXISTS (
+    SELECT 1 FROM sale_payments p
+    WHERE p.sale_id = s.id AND lower(p.method) = lower($4)
+  ))
+  AND ($5::text IS NULL OR s.invoice_no LIKE $5 || '%')
+  AND ($6::numeric IS NULL OR s.total_amount >= $6)
+  AND ($7::numeric IS NULL OR s.total_amount <= $7)
+  AND ($8::int IS NULL OR EXISTS (
+    SELECT 1 FROM sale_items i
+    WHERE i.sale_id = s.id AND i.product_id = $8
+  ))
+  AND ($9::timestamptz IS NULL
+    OR (s.created_at, s.id) < ($9, $10::int))
+ORDER BY s.created_at DESC, s.id DESC
+LIMIT $11 OFFSET $12
 `
 
 type ListSalesParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	CreatedFrom     pgtype.Timestamptz `json:"created_from"`
+	CreatedTo       pgtype.Timestamptz `json:"created_to"`
+	CashierID       pgtype.Int4        `json:"cashier_id"`
+	PaymentMethod   pgtype.Text        `json:"payment_method"`
+	InvoicePrefix   pgtype.Text        `json:"invoice_prefix"`
+	MinTotal        pgtype.Numeric     `json:"min_total"`
+	MaxTotal        pgtype.Numeric     `json:"max_total"`
+	ProductID       pgtype.Int4        `json:"product_id"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursor_created_at"`
+	CursorID        pgtype.Int4        `json:"cursor_id"`
+	Limit           int32              `json:"limit"`
+	Offset          int32              `json:"offset"`
 }
 
 type ListSalesRow struct {
-	ID            int32              `json:"id"`
-	InvoiceNo     string             `json:"invoice_no"`
-	UserID        pgtype.Int4        `json:"user_id"`
-	TotalAmount   pgtype.Numeric     `json:"total_amount"`
-	PaidAmount    pgtype.Numeric     `json:"paid_amount"`
-	ChangeAmount  pgtype.Numeric     `json:"change_amount"`
-	PaymentMethod pgtype.Text        `json:"payment_method"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	CashierName   pgtype.Text        `json:"cashier_name"`
+	ID              int32              `json:"id"`
+	InvoiceNo       string             `json:"invoice_no"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	TotalAmount     pgtype.Numeric     `json:"total_amount"`
+	PaidAmount      pgtype.Numeric     `json:"paid_amount"`
+	ChangeAmount    pgtype.Numeric     `json:"change_amount"`
+	PaymentMethod   pgtype.Text        `json:"payment_method"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	VoidedAt        pgtype.Timestamptz `json:"voided_at"`
+	VoidedBy        pgtype.Int4        `json:"voided_by"`
+	VoidReason      pgtype.Text        `json:"void_reason"`
+	SubtotalAmount  pgtype.Numeric     `json:"subtotal_amount"`
+	TaxAmount       pgtype.Numeric     `json:"tax_amount"`
+	VoucherCode     pgtype.Text        `json:"voucher_code"`
+	VoucherDiscount pgtype.Numeric     `json:"voucher_discount"`
+	CustomerID      pgtype.Int4        `json:"customer_id"`
+	PointsEarned    int64              `json:"points_earned"`
+	PointsRedeemed  int64              `json:"points_redeemed"`
+	ShiftID         pgtype.Int4        `json:"shift_id"`
+	RoundingAmount  pgtype.Numeric     `json:"rounding_amount"`
+	PaymentStatus   string             `json:"payment_status"`
+	CashierName     pgtype.Text        `json:"cashier_name"`
 }
 
+// Sales newest first, filtered by whichever arguments are not null. Pages
+// are keyed on (created_at, id), so sales inserted while paging never shift
+// or repeat rows; cursor_created_at and cursor_id are the last row seen.
 func (q *Queries) ListSales(ctx context.Context, arg ListSalesParams) ([]ListSalesRow, error) {
-	rows, err := q.db.Query(ctx, listSales, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listSales,
+		arg.CreatedFrom,
+		arg.CreatedTo,
+		arg.CashierID,
+		arg.PaymentMethod,
+		arg.InvoicePrefix,
+		arg.MinTotal,
+		arg.MaxTotal,
+		arg.ProductID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -191,6 +632,19 @@ func (q *Queries) ListSales(ctx context.Context, arg ListSalesParams) ([]ListSal
 			&i.ChangeAmount,
 			&i.PaymentMethod,
 			&i.CreatedAt,
+			&i.VoidedAt,
+			&i.VoidedBy,
+			&i.VoidReason,
+			&i.SubtotalAmount,
+			&i.TaxAmount,
+			&i.VoucherCode,
+			&i.VoucherDiscount,
+			&i.CustomerID,
+			&i.PointsEarned,
+			&i.PointsRedeemed,
+			&i.ShiftID,
+			&i.RoundingAmount,
+			&i.PaymentStatus,
 			&i.CashierName,
 		); err != nil {
 			return nil, err
@@ -204,7 +658,7 @@ func (q *Queries) ListSales(ctx context.Context, arg ListSalesParams) ([]ListSal
 }
 
 const listSalesByDateRange = `-- name: ListSalesByDateRange :many
-SELECT s.id, s.invoice_no, s.user_id, s.total_amount, s.paid_amount, s.change_amount, s.payment_method, s.created_at, u.username as cashier_name
+SELECT s.id, s.invoice_no, s.user_id, s.total_amount, s.paid_amount, s.change_amount, s.payment_method, s.created_at, s.voided_at, s.voided_by, s.void_reason, s.subtotal_amount, s.tax_amount, s.voucher_code, s.voucher_discount, s.customer_id, s.points_earned, s.points_redeemed, s.shift_id, s.rounding_amount, s.payment_status, u.username as cashier_name
 FROM sales s
 LEFT JOIN users u ON s.user_id = u.id
 WHERE s.created_at >= $1 AND s.created_at <= $2
@@ -217,15 +671,28 @@ type ListSalesByDateRangeParams struct {
 }
 
 type ListSalesByDateRangeRow struct {
-	ID            int32              `json:"id"`
-	InvoiceNo     string             `json:"invoice_no"`
-	UserID        pgtype.Int4        `json:"user_id"`
-	TotalAmount   pgtype.Numeric     `json:"total_amount"`
-	PaidAmount    pgtype.Numeric     `json:"paid_amount"`
-	ChangeAmount  pgtype.Numeric     `json:"change_amount"`
-	PaymentMethod pgtype.Text        `json:"payment_method"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	CashierName   pgtype.Text        `json:"cashier_name"`
+	ID              int32              `json:"id"`
+	InvoiceNo       string             `json:"invoice_no"`
+	UserID          pgtype.Int4        `json:"user_id"`
+	TotalAmount     pgtype.Numeric     `json:"total_amount"`
+	PaidAmount      pgtype.Numeric     `json:"paid_amount"`
+	ChangeAmount    pgtype.Numeric     `json:"change_amount"`
+	PaymentMethod   pgtype.Text        `json:"payment_method"`
+	CreatedAt       pgtype.Timestamptz `json:"created_at"`
+	VoidedAt        pgtype.Timestamptz `json:"voided_at"`
+	VoidedBy        pgtype.Int4        `json:"voided_by"`
+	VoidReason      pgtype.Text        `json:"void_reason"`
+	SubtotalAmount  pgtype.Numeric     `json:"subtotal_amount"`
+	TaxAmount       pgtype.Numeric     `json:"tax_amount"`
+	VoucherCode     pgtype.Text        `json:"voucher_code"`
+	VoucherDiscount pgtype.Numeric     `json:"voucher_discount"`
+	CustomerID      pgtype.Int4        `json:"customer_id"`
+	PointsEarned    int64              `json:"points_earned"`
+	PointsRedeemed  int64              `json:"points_redeemed"`
+	ShiftID         pgtype.Int4        `json:"shift_id"`
+	RoundingAmount  pgtype.Numeric     `json:"rounding_amount"`
+	PaymentStatus   string             `json:"payment_status"`
+	CashierName     pgtype.Text        `json:"cashier_name"`
 }
 
 func (q *Queries) ListSalesByDateRange(ctx context.Context, arg ListSalesByDateRangeParams) ([]ListSalesByDateRangeRow, error) {
@@ -246,6 +713,19 @@ func (q *Queries) ListSalesByDateRange(ctx context.Context, arg ListSalesByDateR
 			&i.ChangeAmount,
 			&i.PaymentMethod,
 			&i.CreatedAt,
+			&i.VoidedAt,
+			&i.VoidedBy,
+			&i.VoidReason,
+			&i.SubtotalAmount,
+			&i.TaxAmount,
+			&i.VoucherCode,
+			&i.VoucherDiscount,
+			&i.CustomerID,
+			&i.PointsEarned,
+			&i.PointsRedeemed,
+			&i.ShiftID,
+			&i.RoundingAmount,
+			&i.PaymentStatus,
 			&i.CashierName,
 		); err != nil {
 			return nil, err
@@ -257,3 +737,45 @@ func (q *Queries) ListSalesByDateRange(ctx context.Context, arg ListSalesByDateR
 	}
 	return items, nil
 }
+
+const voidSale = `-- name: VoidSale :one
+UPDATE sales
+SET voided_at = now(), voided_by = $2, void_reason = $3
+WHERE id = $1 AND voided_at IS NULL
+RETURNING id, invoice_no, user_id, total_amount, paid_amount, change_amount, payment_method, created_at, voided_at, voided_by, void_reason, subtotal_amount, tax_amount, voucher_code, voucher_discount, customer_id, points_earned, points_redeemed, shift_id, rounding_amount, payment_status
+`
+
+type VoidSaleParams struct {
+	ID         int32       `json:"id"`
+	VoidedBy   pgtype.Int4 `json:"voided_by"`
+	VoidReason pgtype.Text `json:"void_reason"`
+}
+
+func (q *Queries) VoidSale(ctx context.Context, arg VoidSaleParams) (Sale, error) {
+	row := q.db.QueryRow(ctx, voidSale, arg.ID, arg.VoidedBy, arg.VoidReason)
+	var i Sale
+	err := row.Scan(
+		&i.ID,
+		&i.InvoiceNo,
+		&i.UserID,
+		&i.TotalAmount,
+		&i.PaidAmount,
+		&i.ChangeAmount,
+		&i.PaymentMethod,
+		&i.CreatedAt,
+		&i.VoidedAt,
+		&i.VoidedBy,
+		&i.VoidReason,
+		&i.SubtotalAmount,
+		&i.TaxAmount,
+		&i.VoucherCode,
+		&i.VoucherDiscount,
+		&i.CustomerID,
+		&i.PointsEarned,
+		&i.PointsRedeemed,
+		&i.ShiftID,
+		&i.RoundingAmount,
+		&i.PaymentStatus,
+	)
+	return i, err
+}
@@ -0,0 +1,116 @@
+// Package export writes the sales journal as a spreadsheet, one row per sold
+// item. Rows are written as they are read, so exports of any size stream to
+// the client without being held in memory.
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"pos-system/internal/sale"
+	"strings"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// csvTimeLayout is how timestamps are written to CSV, in the store's time
+// zone, so spreadsheet applications read them as dates.
+const csvTimeLayout = "2006-01-02 15:04:05"
+
+type column struct {
+	title string
+	// width is the XLSX column width in characters
+	width float64
+}
+
+var columns = []column{
+	{"Invoice", 22},
+	{"Date", 20},
+	{"Cashier", 16},
+	{"Product", 32},
+	{"SKU", 16},
+	{"Qty", 8},
+	{"Unit", 8},
+	{"Price", 14},
+	{"Discount", 14},
+	{"Tax", 14},
+	{"Subtotal", 14},
+	{"Voided", 8},
+}
+
+// journalWriter writes the header when created and one row per call to
+// Write. Close must be called to complete the file.
+type journalWriter interface {
+	Write(row sale.JournalRow) error
+	Close() error
+}
+
+// newWriter starts a journal in the given format on w.
+func newWriter(format string, w io.Writer) (journalWriter, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, errors.New("export format must be csv or xlsx")
+	}
+}
+
+func voided(row sale.JournalRow) string {
+	if row.Voided {
+		return "yes"
+	}
+	return ""
+}
+
+// csvText keeps a text cell from being read as a formula when the CSV is
+// opened in a spreadsheet, by prefixing cells that start like one with a
+// quote. Product and cashier names are typed in by staff.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.title
+	}
+	if err := cw.w.Write(header); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(row sale.JournalRow) error {
+	return cw.w.Write([]string{
+		csvText(row.InvoiceNo),
+		row.CreatedAt.Format(csvTimeLayout),
+		csvText(row.Cashier),
+		csvText(row.ProductName),
+		csvText(row.SKU),
+		row.Qty.String(),
+		csvText(row.Unit),
+		row.Price.String(),
+		row.Discount.String(),
+		row.Tax.String(),
+		row.Subtotal.String(),
+		voided(row),
+	})
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
@@ -0,0 +1,246 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"pos-system/internal/sale"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var wib = time.FixedZone("WIB", 7*60*60)
+
+// journal is two rows: a plain one with a product name that needs escaping,
+// and a voided one whose names start like spreadsheet formulas.
+var journal = []sale.JournalRow{
+	{
+		InvoiceNo:   "INV-20260115-00001",
+		CreatedAt:   time.Date(2026, 1, 15, 12, 0, 0, 0, wib),
+		Cashier:     "Budi",
+		ProductName: `Teh <manis> & "dingin"`,
+		SKU:         "TEH-01",
+		Qty:         quantity.New(2),
+		Unit:        "gelas",
+		Price:       money.New(8000),
+		Discount:    money.New(1000),
+		Tax:         money.New(1650),
+		Subtotal:    money.New(16650),
+	},
+	{
+		InvoiceNo:   "INV-20260115-00002",
+		CreatedAt:   time.Date(2026, 1, 15, 18, 30, 15, 0, wib),
+		Cashier:     "@admin",
+		ProductName: `=HYPERLINK("http://example.com","Kopi")`,
+		SKU:         "-KOPI",
+		Qty:         quantity.MustParse("1.25"),
+		Unit:        "kg",
+		Price:       money.MustParse("120000.50"),
+		Tax:         money.New(0),
+		Subtotal:    money.MustParse("150000.63"),
+		Voided:      true,
+	},
+}
+
+func titles() []string {
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.title
+	}
+	return header
+}
+
+func write(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := newWriter(format, &buf)
+	if err != nil {
+		t.Fatalf("newWriter(%s): %v", format, err)
+	}
+	for _, row := range journal {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSV(t *testing.T) {
+	records, err := csv.NewReader(bytes.NewReader(write(t, FormatCSV))).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV back: %v", err)
+	}
+
+	want := [][]string{
+		titles(),
+		{"INV-20260115-00001", "2026-01-15 12:00:00", "Budi", `Teh <manis> & "dingin"`, "TEH-01", "2", "gelas", "8000.00", "1000.00", "1650.00", "16650.00", ""},
+		{"INV-20260115-00002", "2026-01-15 18:30:15", "'@admin", `'=HYPERLINK("http://example.com","Kopi")`, "'-KOPI", "1.25", "kg", "120000.50", "0.00", "0.00", "150000.63", "yes"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("CSV rows\n%q\nwant\n%q", records, want)
+	}
+}
+
+func TestCSVText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Kopi", "Kopi"},
+		{"Kopi = enak", "Kopi = enak"},
+		{"=1+1", "'=1+1"},
+		{"+62812", "'+62812"},
+		{"-5", "'-5"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+	}
+
+	for _, tt := range tests {
+		if got := csvText(tt.in); got != tt.want {
+			t.Errorf("csvText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// worksheet decodes the parts of sheet1.xml the writer fills in.
+type worksheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R    string `xml:"r,attr"`
+			S    int    `xml:"s,attr"`
+			T    string `xml:"t,attr"`
+			V    string `xml:"v"`
+			Text string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+func TestXLSX(t *testing.T) {
+	out := write(t, FormatXLSX)
+	zr, err := zip.NewReader(bytes.NewReader(out), int64(len(out)))
+	if err != nil {
+		t.Fatalf("opening XLSX: %v", err)
+	}
+
+	names := map[string]bool{}
+	var raw []byte
+	var sheet worksheet
+	for _, f := range zr.File {
+		names[f.Name] = true
+		if f.Name != "xl/worksheets/sheet1.xml" {
+			continue
+		}
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("opening %s: %v", f.Name, err)
+		}
+		raw, err = io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf("reading %s: %v", f.Name, err)
+		}
+		if err := xml.Unmarshal(raw, &sheet); err != nil {
+			t.Fatalf("decoding %s: %v", f.Name, err)
+		}
+	}
+	for _, part := range xlsxStaticParts {
+		if !names[part.name] {
+			t.Errorf("XLSX has no %s part", part.name)
+		}
+	}
+
+	if escaped := "Teh &lt;manis&gt; &amp; &#34;dingin&#34;"; !bytes.Contains(raw, []byte(escaped)) {
+		t.Errorf("sheet does not contain the escaped product name %s", escaped)
+	}
+
+	if len(sheet.Rows) != 1+len(journal) {
+		t.Fatalf("%d sheet rows, want a header and %d", len(sheet.Rows), len(journal))
+	}
+
+	// Cell values and styles by reference; empty text cells are left out
+	cells := map[string]string{}
+	styles := map[string]int{}
+	for i, row := range sheet.Rows {
+		if row.R != i+1 {
+			t.Errorf("row %d numbered %d", i+1, row.R)
+		}
+		for _, c := range row.Cells {
+			if c.T == "inlineStr" {
+				cells[c.R] = c.Text
+			} else {
+				cells[c.R] = c.V
+			}
+			styles[c.R] = c.S
+		}
+	}
+
+	refs := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L"}
+	for i, title := range titles() {
+		if got := cells[refs[i]+"1"]; got != title {
+			t.Errorf("header %s1 = %q, want %q", refs[i], got, title)
+		}
+	}
+
+	want := map[string]string{
+		"A2": "INV-20260115-00001",
+		"B2": "46037.5",
+		"C2": "Budi",
+		"D2": `Teh <manis> & "dingin"`,
+		"E2": "TEH-01",
+		"F2": "2",
+		"G2": "gelas",
+		"H2": "8000.00",
+		"I2": "1000.00",
+		"J2": "1650.00",
+		"K2": "16650.00",
+		"C3": "@admin",
+		// Inline strings are never formulas, so they are written as they are
+		"D3": `=HYPERLINK("http://example.com","Kopi")`,
+		"F3": "1.25",
+		"L3": "yes",
+	}
+	for ref, v := range want {
+		if got := cells[ref]; got != v {
+			t.Errorf("cell %s = %q, want %q", ref, got, v)
+		}
+	}
+	for ref, style := range map[string]int{"A1": styleHeader, "A2": styleDefault, "B2": styleDate, "F2": styleDefault, "K2": styleAmount} {
+		if styles[ref] != style {
+			t.Errorf("cell %s has style %d, want %d", ref, styles[ref], style)
+		}
+	}
+	if v, ok := cells["L2"]; ok {
+		t.Errorf("cell L2 = %q for a sale that is not voided, want it left empty", v)
+	}
+}
+
+func TestExcelTime(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		want float64
+	}{
+		{time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC), 2},
+		{time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), 46023},
+		{time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC), 46037.5},
+		// The wall clock is kept, whatever the zone
+		{time.Date(2026, 1, 15, 12, 0, 0, 0, wib), 46037.5},
+		{time.Date(2026, 1, 15, 18, 0, 0, 0, wib), 46037.75},
+		// Fractions of a second are dropped
+		{time.Date(2026, 1, 15, 6, 0, 0, 999, wib), 46037.25},
+	}
+
+	for _, tt := range tests {
+		if got := excelTime(tt.at); got != tt.want {
+			t.Errorf("excelTime(%s) = %v, want %v", tt.at.Format(time.RFC3339Nano), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package export
+
+import (
+	"fmt"
+	"net/http"
+	"pos-system/internal/sale"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	sales  *sale.Service
+	logger *zap.Logger
+}
+
+func NewHandler(sales *sale.Service, logger *zap.Logger) *Handler {
+	return &Handler{sales: sales, logger: logger}
+}
+
+var contentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Sales serves the sales journal as a CSV or XLSX download. It takes the
+// same filters as the sales listing. The file is started when the first row
+// is read, so a failing query still gets a JSON error; a failure after that
+// can only cut the download short, and is logged.
+func (h *Handler) Sales(c *gin.Context) {
+	format := c.DefaultQuery("format", FormatCSV)
+	contentType, ok := contentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "export format must be csv or xlsx"})
+		return
+	}
+
+	filter, err := sale.FilterFromQuery(c)
+	if err == nil {
+		err = filter.Validate()
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var w journalWriter
+	started := false
+	start := func() error {
+		started = true
+		filename := fmt.Sprintf("sales-journal-%s.%s", time.Now().Format("20060102-150405"), format)
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Status(http.StatusOK)
+		var err error
+		w, err = newWriter(format, c.Writer)
+		return err
+	}
+
+	err = h.sales.Journal(c.Request.Context(), filter, func(row sale.JournalRow) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		return w.Write(row)
+	})
+	if err == nil && !started {
+		// No matching sales: an empty journal with just the header
+		err = start()
+	}
+	if err != nil {
+		if !started {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.Error("sales export failed", zap.Error(err))
+		return
+	}
+
+	if err := w.Close(); err != nil {
+		h.logger.Error("sales export failed", zap.Error(err))
+	}
+}
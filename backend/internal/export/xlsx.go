@@ -0,0 +1,180 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"pos-system/internal/sale"
+	"strconv"
+	"time"
+)
+
+// The XLSX writer emits the minimal set of Office Open XML parts by hand.
+// The worksheet is the last entry in the zip and is written row by row, so
+// nothing but the current row is buffered.
+
+const (
+	styleDefault = 0
+	styleDate    = 1
+	styleAmount  = 2
+	styleHeader  = 3
+)
+
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sales" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`},
+	// Cell styles, in the order of the style constants
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<numFmts count="1"><numFmt numFmtId="164" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+		`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+		`<cellXfs count="4">` +
+		`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="4" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+		`</cellXfs>` +
+		`</styleSheet>`},
+}
+
+type xlsxWriter struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	row   int
+	cell  int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{zip: zw, sheet: bufio.NewWriter(f)}
+
+	// Freeze the header row and size the columns
+	xw.sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	xw.sheet.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews><cols>`)
+	for i, col := range columns {
+		fmt.Fprintf(xw.sheet, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, col.width)
+	}
+	xw.sheet.WriteString(`</cols><sheetData>`)
+
+	xw.startRow()
+	for _, col := range columns {
+		xw.text(col.title, styleHeader)
+	}
+	xw.endRow()
+
+	return xw, nil
+}
+
+func (xw *xlsxWriter) Write(row sale.JournalRow) error {
+	xw.startRow()
+	xw.text(row.InvoiceNo, styleDefault)
+	xw.number(strconv.FormatFloat(excelTime(row.CreatedAt), 'f', -1, 64), styleDate)
+	xw.text(row.Cashier, styleDefault)
+	xw.text(row.ProductName, styleDefault)
+	xw.text(row.SKU, styleDefault)
+	xw.number(row.Qty.String(), styleDefault)
+	xw.text(row.Unit, styleDefault)
+	xw.number(row.Price.String(), styleAmount)
+	xw.number(row.Discount.String(), styleAmount)
+	xw.number(row.Tax.String(), styleAmount)
+	xw.number(row.Subtotal.String(), styleAmount)
+	xw.text(voided(row), styleDefault)
+	return xw.endRow()
+}
+
+func (xw *xlsxWriter) Close() error {
+	xw.sheet.WriteString(`</sheetData></worksheet>`)
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zip.Close()
+}
+
+func (xw *xlsxWriter) startRow() {
+	xw.row++
+	xw.cell = 0
+	fmt.Fprintf(xw.sheet, `<row r="%d">`, xw.row)
+}
+
+// endRow closes the row and reports any error writing it. The buffered
+// writer keeps the first error, so checking once per row is enough.
+func (xw *xlsxWriter) endRow() error {
+	_, err := xw.sheet.WriteString(`</row>`)
+	return err
+}
+
+// ref is the A1-style reference of the next cell in the row.
+func (xw *xlsxWriter) ref() string {
+	xw.cell++
+	var letters string
+	for n := xw.cell; n > 0; n = (n - 1) / 26 {
+		letters = string(rune('A'+(n-1)%26)) + letters
+	}
+	return letters + strconv.Itoa(xw.row)
+}
+
+func (xw *xlsxWriter) text(s string, style int) {
+	ref := xw.ref()
+	if s == "" {
+		return
+	}
+	fmt.Fprintf(xw.sheet, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+	xml.EscapeText(xw.sheet, []byte(s))
+	xw.sheet.WriteString(`</t></is></c>`)
+}
+
+func (xw *xlsxWriter) number(v string, style int) {
+	fmt.Fprintf(xw.sheet, `<c r="%s" s="%d"><v>%s</v></c>`, xw.ref(), style, v)
+}
+
+// excelEpoch is day zero of the spreadsheet date system.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// excelTime converts t to a spreadsheet serial date, keeping its wall clock
+// time; spreadsheets have no time zones.
+func excelTime(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	return wall.Sub(excelEpoch).Seconds() / 86400
+}
@@ -0,0 +1,194 @@
+package giftcard
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func errorStatus(err error) int {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "gift card not found", errMsg == "customer not found":
+		return http.StatusNotFound
+	case errMsg == "card number already exists", errMsg == "cashier has no open shift":
+		return http.StatusConflict
+	case strings.HasPrefix(errMsg, "gift card"),
+		strings.HasPrefix(errMsg, "card type"),
+		strings.HasPrefix(errMsg, "store credit"),
+		errMsg == "amount must be greater than zero",
+		errMsg == "expires_at must be in the future":
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) List(c *gin.Context) {
+	limitStr := c.DefaultQuery("limit", "50")
+	offsetStr := c.DefaultQuery("offset", "0")
+
+	limit, _ := strconv.ParseInt(limitStr, 10, 32)
+	offset, _ := strconv.ParseInt(offsetStr, 10, 32)
+
+	cards, err := h.service.List(c.Request.Context(), int32(limit), int32(offset))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cards)
+}
+
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid gift card id"})
+		return
+	}
+
+	card, err := h.service.GetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// GetByNumber serves an admin's lookup of a card and its history by number.
+func (h *Handler) GetByNumber(c *gin.Context) {
+	card, err := h.service.GetByNumber(c.Request.Context(), c.Param("number"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// Balance serves a cashier's balance check, by the number on the card.
+func (h *Handler) Balance(c *gin.Context) {
+	number := c.Query("card_number")
+	if number == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query parameter 'card_number' is required"})
+		return
+	}
+
+	card, err := h.service.Balance(c.Request.Context(), number)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *Handler) Issue(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req IssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Cashiers sell gift cards; only admins hand out store credit outside
+	// of a return
+	if role, _ := c.Get("role"); req.Type == TypeStoreCredit && role != "admin" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "only admins can issue store credit"})
+		return
+	}
+
+	card, err := h.service.Issue(c.Request.Context(), userID.(int32), req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, card)
+}
+
+func (h *Handler) Reload(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid gift card id"})
+		return
+	}
+
+	var req ReloadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	card, err := h.service.Reload(c.Request.Context(), int32(id), userID.(int32), req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+func (h *Handler) Activate(c *gin.Context) {
+	h.setActive(c, true)
+}
+
+func (h *Handler) Deactivate(c *gin.Context) {
+	h.setActive(c, false)
+}
+
+func (h *Handler) setActive(c *gin.Context, active bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid gift card id"})
+		return
+	}
+
+	card, err := h.service.SetActive(c.Request.Context(), int32(id), active)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, card)
+}
+
+// Expire runs the expiry sweep. It is safe to call repeatedly, e.g. from a
+// nightly job.
+func (h *Handler) Expire(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	expired, err := h.service.Expire(c.Request.Context(), userID.(int32))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expired": expired})
+}
@@ -0,0 +1,265 @@
+package giftcard
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The functions in this file change balances inside a caller's transaction,
+// so sales and returns can draw on or credit a card atomically with the rest
+// of their writes. Every change locks the card row first.
+
+const (
+	TypeGiftCard    = "gift_card"
+	TypeStoreCredit = "store_credit"
+)
+
+// Ledger entry types
+const (
+	EntryIssue  = "issue"
+	EntryReload = "reload"
+	EntryRedeem = "redeem"
+	EntryVoid   = "void"
+	EntryExpire = "expire"
+)
+
+// numberLength is the number of digits in a generated card number.
+const numberLength = 16
+
+// NormalizeNumber is how card numbers are stored and looked up, so a number
+// typed with spaces or dashes finds the same card.
+func NormalizeNumber(number string) string {
+	number = strings.NewReplacer(" ", "", "-", "").Replace(number)
+	return strings.ToUpper(strings.TrimSpace(number))
+}
+
+// newNumber generates a random numeric card number.
+func newNumber() (string, error) {
+	var b strings.Builder
+	ten := big.NewInt(10)
+	for i := 0; i < numberLength; i++ {
+		d, err := rand.Int(rand.Reader, ten)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(byte('0' + d.Int64()))
+	}
+	return b.String(), nil
+}
+
+func isDuplicateNumber(err error) bool {
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "duplicate key") && strings.Contains(errMsg, "gift_cards_card_number_key")
+}
+
+// create inserts a card with a zero balance. Without a number one is
+// generated, retrying on the rare collision.
+func create(ctx context.Context, q *db.Queries, params db.CreateGiftCardParams) (db.GiftCard, error) {
+	if params.CardNumber != "" {
+		card, err := q.CreateGiftCard(ctx, params)
+		if err != nil && isDuplicateNumber(err) {
+			return card, errors.New("card number already exists")
+		}
+		return card, err
+	}
+
+	for attempt := 0; ; attempt++ {
+		number, err := newNumber()
+		if err != nil {
+			return db.GiftCard{}, err
+		}
+		params.CardNumber = number
+		card, err := q.CreateGiftCard(ctx, params)
+		if err == nil || !isDuplicateNumber(err) || attempt == 2 {
+			return card, err
+		}
+	}
+}
+
+// lockByNumber loads and locks the card with the given number.
+func lockByNumber(ctx context.Context, q *db.Queries, number string) (db.GiftCard, error) {
+	number = NormalizeNumber(number)
+	if number == "" {
+		return db.GiftCard{}, errors.New("gift card number is required")
+	}
+
+	card, err := q.GetGiftCardByNumberForUpdate(ctx, number)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return card, errors.New("gift card not found")
+		}
+		return card, err
+	}
+	return card, nil
+}
+
+func isExpired(card db.GiftCard, at time.Time) bool {
+	return card.ExpiresAt.Valid && !at.Before(card.ExpiresAt.Time)
+}
+
+// usable reports why card cannot be paid with or topped up at time at.
+func usable(card db.GiftCard, at time.Time) error {
+	if !card.Active {
+		return errors.New("gift card is not active")
+	}
+	if isExpired(card, at) {
+		return errors.New("gift card has expired")
+	}
+	return nil
+}
+
+// post adds amount, which may be negative, to the balance of card and writes
+// the ledger entry. card must be locked by q's transaction.
+func post(ctx context.Context, q *db.Queries, card db.GiftCard, amount money.Amount, entry db.CreateGiftCardTransactionParams) (db.GiftCard, error) {
+	balance, err := money.FromNumeric(card.Balance)
+	if err != nil {
+		return card, err
+	}
+	after := balance + amount
+	if after < 0 {
+		return card, fmt.Errorf("gift card has insufficient balance (available: %s)", balance)
+	}
+
+	updated, err := q.UpdateGiftCardBalance(ctx, db.UpdateGiftCardBalanceParams{
+		ID:      card.ID,
+		Balance: after.Numeric(),
+	})
+	if err != nil {
+		return card, err
+	}
+
+	entry.GiftCardID = card.ID
+	entry.Amount = amount.Numeric()
+	entry.BalanceAfter = after.Numeric()
+	if _, err := q.CreateGiftCardTransaction(ctx, entry); err != nil {
+		return card, err
+	}
+	return updated, nil
+}
+
+// Redeem takes amount off the card with the given number to pay for saleID.
+// It must run in the same transaction as the insert of the sale.
+func Redeem(ctx context.Context, q *db.Queries, number string, amount money.Amount, saleID, userID int32) error {
+	card, err := lockByNumber(ctx, q, number)
+	if err != nil {
+		return err
+	}
+	if err := usable(card, time.Now()); err != nil {
+		return err
+	}
+
+	_, err = post(ctx, q, card, -amount, db.CreateGiftCardTransactionParams{
+		Type:   EntryRedeem,
+		SaleID: pgtype.Int4{Int32: saleID, Valid: true},
+		UserID: pgtype.Int4{Int32: userID, Valid: true},
+	})
+	return err
+}
+
+// ReverseSale puts back everything saleID drew from gift cards, for a voided
+// sale. Cards that have expired or been deactivated since still get their
+// balance back; the expiry sweep picks them up again.
+func ReverseSale(ctx context.Context, q *db.Queries, saleID, userID int32) error {
+	saleIDPg := pgtype.Int4{Int32: saleID, Valid: true}
+	redemptions, err := q.ListGiftCardRedemptionsBySale(ctx, saleIDPg)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range redemptions {
+		card, err := q.GetGiftCardForUpdate(ctx, r.GiftCardID)
+		if err != nil {
+			return err
+		}
+		redeemed, err := money.FromNumeric(r.Amount)
+		if err != nil {
+			return err
+		}
+		if _, err := post(ctx, q, card, -redeemed, db.CreateGiftCardTransactionParams{
+			Type:   EntryVoid,
+			SaleID: saleIDPg,
+			UserID: pgtype.Int4{Int32: userID, Valid: true},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RedeemedCard is the number of the card saleID was paid from, for refunding
+// to it. It fails unless the sale drew on exactly one card.
+func RedeemedCard(ctx context.Context, q *db.Queries, saleID int32) (string, error) {
+	redemptions, err := q.ListGiftCardRedemptionsBySale(ctx, pgtype.Int4{Int32: saleID, Valid: true})
+	if err != nil {
+		return "", err
+	}
+
+	var cardID int32
+	for _, r := range redemptions {
+		if cardID != 0 && r.GiftCardID != cardID {
+			return "", errors.New("card number is required: the sale was paid from more than one gift card")
+		}
+		cardID = r.GiftCardID
+	}
+	if cardID == 0 {
+		return "", errors.New("card number is required: the sale was not paid by gift card")
+	}
+
+	card, err := q.GetGiftCardByID(ctx, cardID)
+	if err != nil {
+		return "", err
+	}
+	return card.CardNumber, nil
+}
+
+// CreditParams describes a refund onto stored value. With a CardNumber the
+// existing card is credited; without one a new store credit card is issued
+// to CustomerID.
+type CreditParams struct {
+	CardNumber string
+	CustomerID pgtype.Int4
+	Amount     money.Amount
+	ReturnID   int32
+	UserID     int32
+}
+
+// Credit refunds a return onto a card instead of paying out cash, and
+// returns the card credited. It must run in the return's transaction.
+func Credit(ctx context.Context, q *db.Queries, p CreditParams) (db.GiftCard, error) {
+	entry := db.CreateGiftCardTransactionParams{
+		ReturnID: pgtype.Int4{Int32: p.ReturnID, Valid: true},
+		UserID:   pgtype.Int4{Int32: p.UserID, Valid: true},
+	}
+
+	if p.CardNumber == "" {
+		card, err := create(ctx, q, db.CreateGiftCardParams{
+			Type:       TypeStoreCredit,
+			CustomerID: p.CustomerID,
+		})
+		if err != nil {
+			return card, err
+		}
+		entry.Type = EntryIssue
+		return post(ctx, q, card, p.Amount, entry)
+	}
+
+	card, err := lockByNumber(ctx, q, p.CardNumber)
+	if err != nil {
+		return card, err
+	}
+	if err := usable(card, time.Now()); err != nil {
+		return card, err
+	}
+	entry.Type = EntryReload
+	return post(ctx, q, card, p.Amount, entry)
+}
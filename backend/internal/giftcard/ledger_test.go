@@ -0,0 +1,248 @@
+package giftcard
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestIsExpired(t *testing.T) {
+	expiry := time.Date(2026, 6, 30, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		expiresAt pgtype.Timestamptz
+		at        time.Time
+		want      bool
+	}{
+		{"no expiry", pgtype.Timestamptz{}, expiry.AddDate(10, 0, 0), false},
+		{"before expiry", pgtype.Timestamptz{Time: expiry, Valid: true}, expiry.Add(-time.Nanosecond), false},
+		// A card is no longer good at the moment it expires
+		{"at expiry", pgtype.Timestamptz{Time: expiry, Valid: true}, expiry, true},
+		{"after expiry", pgtype.Timestamptz{Time: expiry, Valid: true}, expiry.Add(time.Second), true},
+		{"same instant in another zone", pgtype.Timestamptz{Time: expiry, Valid: true}, expiry.In(time.FixedZone("WIB", 7*60*60)), true},
+	}
+
+	for _, tt := range tests {
+		card := db.GiftCard{ExpiresAt: tt.expiresAt}
+		if got := isExpired(card, tt.at); got != tt.want {
+			t.Errorf("%s: isExpired at %s = %v, want %v", tt.name, tt.at.Format(time.RFC3339Nano), got, tt.want)
+		}
+	}
+}
+
+func TestUsable(t *testing.T) {
+	now := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	future := pgtype.Timestamptz{Time: now.AddDate(0, 1, 0), Valid: true}
+	past := pgtype.Timestamptz{Time: now.AddDate(0, -1, 0), Valid: true}
+
+	tests := []struct {
+		name string
+		card db.GiftCard
+		want string
+	}{
+		{"active without expiry", db.GiftCard{Active: true}, ""},
+		{"active before expiry", db.GiftCard{Active: true, ExpiresAt: future}, ""},
+		{"expired", db.GiftCard{Active: true, ExpiresAt: past}, "gift card has expired"},
+		{"inactive", db.GiftCard{ExpiresAt: future}, "gift card is not active"},
+		// Deactivation is reported before expiry
+		{"inactive and expired", db.GiftCard{ExpiresAt: past}, "gift card is not active"},
+	}
+
+	for _, tt := range tests {
+		err := usable(tt.card, now)
+		var got string
+		if err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%s: usable = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// amount reads a NUMERIC column written by the ledger.
+func amount(t *testing.T, n pgtype.Numeric) money.Amount {
+	t.Helper()
+	a, err := money.FromNumeric(n)
+	if err != nil {
+		t.Fatalf("FromNumeric: %v", err)
+	}
+	return a
+}
+
+// TestLedger takes a card through issue, redemption, a void, a refund onto it
+// and expiry, and checks every ledger entry keeps a running balance. It runs
+// when TEST_DATABASE_URL points at a migrated Postgres database.
+func TestLedger(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Close()
+	queries := db.New(pool)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	user, err := queries.CreateUser(ctx, db.CreateUserParams{
+		Username:     "giftcard-test-" + suffix,
+		PasswordHash: "-",
+		Role:         "cashier",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	shift, err := queries.CreateShift(ctx, db.CreateShiftParams{
+		UserID:       user.ID,
+		OpeningFloat: money.Amount(0).Numeric(),
+	})
+	if err != nil {
+		t.Fatalf("CreateShift: %v", err)
+	}
+	var saleID, returnID int32
+	if err := pool.QueryRow(ctx, `
+		INSERT INTO sales (invoice_no, user_id, shift_id, total_amount, paid_amount)
+		VALUES ($1, $2, $3, 500, 500) RETURNING id`,
+		"GCT-"+suffix, user.ID, shift.ID).Scan(&saleID); err != nil {
+		t.Fatalf("insert sale: %v", err)
+	}
+	if err := pool.QueryRow(ctx, `
+		INSERT INTO sale_returns (return_no, sale_id, user_id, refund_amount, refund_method)
+		VALUES ($1, $2, $3, 50, 'store_credit') RETURNING id`,
+		"GCT-"+suffix, saleID, user.ID).Scan(&returnID); err != nil {
+		t.Fatalf("insert return: %v", err)
+	}
+
+	var cardIDs []int32
+	t.Cleanup(func() {
+		pool.Exec(ctx, "DELETE FROM gift_cards WHERE id = ANY($1)", cardIDs)
+		pool.Exec(ctx, "DELETE FROM sale_returns WHERE id = $1", returnID)
+		for _, q := range []string{
+			"DELETE FROM sales WHERE shift_id = $1",
+			"DELETE FROM shifts WHERE id = $1",
+		} {
+			pool.Exec(ctx, q, shift.ID)
+		}
+		pool.Exec(ctx, "DELETE FROM users WHERE id = $1", user.ID)
+	})
+
+	svc := NewService(queries, pool)
+	expiresAt := time.Now().Add(time.Hour)
+	issued, err := svc.Issue(ctx, user.ID, IssueRequest{Amount: money.New(100), ExpiresAt: &expiresAt})
+	if err != nil {
+		t.Fatalf("Issue: %v", err)
+	}
+	cardIDs = append(cardIDs, issued.ID)
+
+	// inTx runs fn in a transaction and commits it if fn succeeds.
+	inTx := func(fn func(q *db.Queries) error) error {
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+		if err := fn(queries.WithTx(tx)); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+	redeem := func(units int64) error {
+		return inTx(func(q *db.Queries) error {
+			return Redeem(ctx, q, issued.CardNumber, money.New(units), saleID, user.ID)
+		})
+	}
+
+	if err := redeem(30); err != nil {
+		t.Fatalf("Redeem: %v", err)
+	}
+	if err := redeem(20); err != nil {
+		t.Fatalf("Redeem: %v", err)
+	}
+	if err := redeem(51); err == nil || err.Error() != "gift card has insufficient balance (available: 50.00)" {
+		t.Errorf("Redeem more than the balance: err = %v", err)
+	}
+
+	// The void puts back both redemptions, and only those
+	if err := inTx(func(q *db.Queries) error { return ReverseSale(ctx, q, saleID, user.ID) }); err != nil {
+		t.Fatalf("ReverseSale: %v", err)
+	}
+
+	// A return refunded onto the card, and one issued as new store credit
+	if err := inTx(func(q *db.Queries) error {
+		_, err := Credit(ctx, q, CreditParams{CardNumber: issued.CardNumber, Amount: money.New(25), ReturnID: returnID, UserID: user.ID})
+		return err
+	}); err != nil {
+		t.Fatalf("Credit onto the card: %v", err)
+	}
+	var credit db.GiftCard
+	if err := inTx(func(q *db.Queries) error {
+		var err error
+		credit, err = Credit(ctx, q, CreditParams{Amount: money.New(25), ReturnID: returnID, UserID: user.ID})
+		return err
+	}); err != nil {
+		t.Fatalf("Credit as store credit: %v", err)
+	}
+	cardIDs = append(cardIDs, credit.ID)
+	if credit.Type != TypeStoreCredit || amount(t, credit.Balance) != money.New(25) {
+		t.Errorf("store credit issued as %s with balance %s, want %s with 25.00", credit.Type, amount(t, credit.Balance), TypeStoreCredit)
+	}
+
+	// Once past its expiry the card cannot be spent and the sweep writes it off
+	if _, err := pool.Exec(ctx, "UPDATE gift_cards SET expires_at = now() - interval '1 second' WHERE id = $1", issued.ID); err != nil {
+		t.Fatalf("backdate expiry: %v", err)
+	}
+	if err := redeem(10); err == nil || err.Error() != "gift card has expired" {
+		t.Errorf("Redeem after expiry: err = %v", err)
+	}
+	if n, err := svc.Expire(ctx, user.ID); err != nil || n < 1 {
+		t.Fatalf("Expire = %d, %v; want at least the test card", n, err)
+	}
+
+	entries, err := queries.ListGiftCardTransactions(ctx, issued.ID)
+	if err != nil {
+		t.Fatalf("ListGiftCardTransactions: %v", err)
+	}
+	want := []struct {
+		entry   string
+		amount  int64
+		balance int64
+	}{
+		{EntryIssue, 100, 100},
+		{EntryRedeem, -30, 70},
+		{EntryRedeem, -20, 50},
+		{EntryVoid, 30, 80},
+		{EntryVoid, 20, 100},
+		{EntryReload, 25, 125},
+		{EntryExpire, -125, 0},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("%d ledger entries, want %d", len(entries), len(want))
+	}
+	for i, e := range entries {
+		got, balance := amount(t, e.Amount), amount(t, e.BalanceAfter)
+		if e.Type != want[i].entry || got != money.New(want[i].amount) || balance != money.New(want[i].balance) {
+			t.Errorf("entry %d = %s %s, balance %s; want %s %s, balance %s", i, e.Type, got, balance,
+				want[i].entry, money.New(want[i].amount), money.New(want[i].balance))
+		}
+	}
+
+	card, err := queries.GetGiftCardByID(ctx, issued.ID)
+	if err != nil {
+		t.Fatalf("GetGiftCardByID: %v", err)
+	}
+	if balance := amount(t, card.Balance); balance != 0 {
+		t.Errorf("expired card balance %s, want 0", balance)
+	}
+}
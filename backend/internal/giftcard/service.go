@@ -0,0 +1,404 @@
+// Package giftcard manages stored value: gift cards customers buy and store
+// credit issued in place of a cash refund. Balances only change through the
+// ledger in gift_card_transactions.
+package giftcard
+
+import (
+	"context"
+	"errors"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// methodCash is the tender a gift card is paid for when none is given
+const methodCash = "cash"
+
+type Service struct {
+	queries *db.Queries
+	db      *pgxpool.Pool
+}
+
+func NewService(queries *db.Queries, db *pgxpool.Pool) *Service {
+	return &Service{queries: queries, db: db}
+}
+
+// IssueRequest issues a card loaded with Amount. Type defaults to gift_card.
+// A gift card is sold: PaymentMethod (default cash) is what the customer paid
+// with, and the issuing cashier needs an open shift to take it. Store credit
+// is not paid for and takes no payment method. CardNumber registers a
+// pre-printed card; without it a number is generated.
+type IssueRequest struct {
+	Type          string       `json:"type"`
+	Amount        money.Amount `json:"amount" binding:"required"`
+	CardNumber    string       `json:"card_number"`
+	CustomerID    *int32       `json:"customer_id"`
+	ExpiresAt     *time.Time   `json:"expires_at"`
+	PaymentMethod string       `json:"payment_method"`
+	Note          string       `json:"note"`
+}
+
+// ReloadRequest tops up a card. The customer pays for it like a new card.
+type ReloadRequest struct {
+	Amount        money.Amount `json:"amount" binding:"required"`
+	PaymentMethod string       `json:"payment_method"`
+	Note          string       `json:"note"`
+}
+
+type GiftCardResponse struct {
+	ID           int32                 `json:"id"`
+	CardNumber   string                `json:"card_number"`
+	Type         string                `json:"type"`
+	Balance      string                `json:"balance"`
+	CustomerID   *int32                `json:"customer_id"`
+	ExpiresAt    *string               `json:"expires_at"`
+	Expired      bool                  `json:"expired"`
+	Active       bool                  `json:"active"`
+	CreatedAt    string                `json:"created_at"`
+	Transactions []TransactionResponse `json:"transactions,omitempty"`
+}
+
+// TransactionResponse is one ledger entry. Amount is signed: positive
+// entries added to the balance.
+type TransactionResponse struct {
+	ID            int32   `json:"id"`
+	Type          string  `json:"type"`
+	Amount        string  `json:"amount"`
+	BalanceAfter  string  `json:"balance_after"`
+	SaleID        *int32  `json:"sale_id"`
+	ReturnID      *int32  `json:"return_id"`
+	PaymentMethod *string `json:"payment_method"`
+	UserID        *int32  `json:"user_id"`
+	Note          *string `json:"note"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+// numericToString formats a NUMERIC money column with two decimals
+func numericToString(n pgtype.Numeric) string {
+	amount, err := money.FromNumeric(n)
+	if err != nil {
+		return "0.00"
+	}
+	return amount.String()
+}
+
+func optInt4(v pgtype.Int4) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
+
+func optText(v pgtype.Text) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+func toResponse(card db.GiftCard) GiftCardResponse {
+	resp := GiftCardResponse{
+		ID:         card.ID,
+		CardNumber: card.CardNumber,
+		Type:       card.Type,
+		Balance:    numericToString(card.Balance),
+		CustomerID: optInt4(card.CustomerID),
+		Expired:    isExpired(card, time.Now()),
+		Active:     card.Active,
+	}
+	if card.ExpiresAt.Valid {
+		v := card.ExpiresAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		resp.ExpiresAt = &v
+	}
+	if card.CreatedAt.Valid {
+		resp.CreatedAt = card.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+func transactionResponse(t db.GiftCardTransaction) TransactionResponse {
+	resp := TransactionResponse{
+		ID:            t.ID,
+		Type:          t.Type,
+		Amount:        numericToString(t.Amount),
+		BalanceAfter:  numericToString(t.BalanceAfter),
+		SaleID:        optInt4(t.SaleID),
+		ReturnID:      optInt4(t.ReturnID),
+		PaymentMethod: optText(t.PaymentMethod),
+		UserID:        optInt4(t.UserID),
+		Note:          optText(t.Note),
+	}
+	if t.CreatedAt.Valid {
+		resp.CreatedAt = t.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+// withHistory adds the card's ledger to its response.
+func (s *Service) withHistory(ctx context.Context, card db.GiftCard) (*GiftCardResponse, error) {
+	txns, err := s.queries.ListGiftCardTransactions(ctx, card.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toResponse(card)
+	resp.Transactions = make([]TransactionResponse, len(txns))
+	for i, t := range txns {
+		resp.Transactions[i] = transactionResponse(t)
+	}
+	return &resp, nil
+}
+
+// paymentEntry checks that the cashier taking payment for a card has an open
+// shift, so the money is counted in their drawer, and returns the ledger
+// fields that attribute it to them.
+func paymentEntry(ctx context.Context, q *db.Queries, userID int32, method, note string) (db.CreateGiftCardTransactionParams, error) {
+	method = strings.ToLower(strings.TrimSpace(method))
+	if method == "" {
+		method = methodCash
+	}
+
+	if _, err := q.GetOpenShiftByUserForShare(ctx, userID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.CreateGiftCardTransactionParams{}, errors.New("cashier has no open shift")
+		}
+		return db.CreateGiftCardTransactionParams{}, err
+	}
+
+	entry := db.CreateGiftCardTransactionParams{
+		PaymentMethod: pgtype.Text{String: method, Valid: true},
+		UserID:        pgtype.Int4{Int32: userID, Valid: true},
+	}
+	if note = strings.TrimSpace(note); note != "" {
+		entry.Note = pgtype.Text{String: note, Valid: true}
+	}
+	return entry, nil
+}
+
+func (s *Service) Issue(ctx context.Context, userID int32, req IssueRequest) (*GiftCardResponse, error) {
+	if req.Type == "" {
+		req.Type = TypeGiftCard
+	}
+	if req.Type != TypeGiftCard && req.Type != TypeStoreCredit {
+		return nil, errors.New("card type must be gift_card or store_credit")
+	}
+	if req.Amount <= 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	var entry db.CreateGiftCardTransactionParams
+	if req.Type == TypeGiftCard {
+		entry, err = paymentEntry(ctx, qtx, userID, req.PaymentMethod, req.Note)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if req.PaymentMethod != "" {
+			return nil, errors.New("store credit is not paid for and takes no payment method")
+		}
+		entry.UserID = pgtype.Int4{Int32: userID, Valid: true}
+		if note := strings.TrimSpace(req.Note); note != "" {
+			entry.Note = pgtype.Text{String: note, Valid: true}
+		}
+	}
+
+	params := db.CreateGiftCardParams{
+		CardNumber: NormalizeNumber(req.CardNumber),
+		Type:       req.Type,
+	}
+	if req.CustomerID != nil {
+		if _, err := qtx.GetCustomerByID(ctx, *req.CustomerID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, errors.New("customer not found")
+			}
+			return nil, err
+		}
+		params.CustomerID = pgtype.Int4{Int32: *req.CustomerID, Valid: true}
+	}
+	if req.ExpiresAt != nil {
+		params.ExpiresAt = pgtype.Timestamptz{Time: *req.ExpiresAt, Valid: true}
+	}
+
+	card, err := create(ctx, qtx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Type = EntryIssue
+	card, err = post(ctx, qtx, card, req.Amount, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.withHistory(ctx, card)
+}
+
+func (s *Service) Reload(ctx context.Context, id, userID int32, req ReloadRequest) (*GiftCardResponse, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	card, err := qtx.GetGiftCardForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("gift card not found")
+		}
+		return nil, err
+	}
+	if err := usable(card, time.Now()); err != nil {
+		return nil, err
+	}
+
+	entry, err := paymentEntry(ctx, qtx, userID, req.PaymentMethod, req.Note)
+	if err != nil {
+		return nil, err
+	}
+	entry.Type = EntryReload
+	card, err = post(ctx, qtx, card, req.Amount, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.withHistory(ctx, card)
+}
+
+// Balance looks a card up by number for a balance check at the till. It
+// leaves out the history.
+func (s *Service) Balance(ctx context.Context, number string) (*GiftCardResponse, error) {
+	card, err := s.queries.GetGiftCardByNumber(ctx, NormalizeNumber(number))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("gift card not found")
+		}
+		return nil, err
+	}
+
+	resp := toResponse(card)
+	return &resp, nil
+}
+
+// GetByNumber looks a card up by number, with its history.
+func (s *Service) GetByNumber(ctx context.Context, number string) (*GiftCardResponse, error) {
+	card, err := s.queries.GetGiftCardByNumber(ctx, NormalizeNumber(number))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("gift card not found")
+		}
+		return nil, err
+	}
+
+	return s.withHistory(ctx, card)
+}
+
+// GetByID returns the card with its history.
+func (s *Service) GetByID(ctx context.Context, id int32) (*GiftCardResponse, error) {
+	card, err := s.queries.GetGiftCardByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("gift card not found")
+		}
+		return nil, err
+	}
+
+	return s.withHistory(ctx, card)
+}
+
+func (s *Service) List(ctx context.Context, limit, offset int32) ([]GiftCardResponse, error) {
+	cards, err := s.queries.ListGiftCards(ctx, db.ListGiftCardsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]GiftCardResponse, len(cards))
+	for i, card := range cards {
+		result[i] = toResponse(card)
+	}
+	return result, nil
+}
+
+// SetActive blocks a card, e.g. one reported lost, or unblocks it. The
+// balance is kept either way.
+func (s *Service) SetActive(ctx context.Context, id int32, active bool) (*GiftCardResponse, error) {
+	card, err := s.queries.SetGiftCardActive(ctx, db.SetGiftCardActiveParams{
+		ID:     id,
+		Active: active,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("gift card not found")
+		}
+		return nil, err
+	}
+
+	return s.withHistory(ctx, card)
+}
+
+// Expire writes off the balance of every card past its expiry date, with an
+// expire entry in each card's ledger, and returns how many were expired.
+func (s *Service) Expire(ctx context.Context, userID int32) (int, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	cards, err := qtx.ListExpiredGiftCardsForUpdate(ctx, pgtype.Timestamptz{Time: time.Now(), Valid: true})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, card := range cards {
+		balance, err := money.FromNumeric(card.Balance)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := post(ctx, qtx, card, -balance, db.CreateGiftCardTransactionParams{
+			Type:   EntryExpire,
+			UserID: pgtype.Int4{Int32: userID, Valid: true},
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(cards), nil
+}
@@ -3,6 +3,7 @@ package inventory
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -40,6 +41,10 @@ func (h *Handler) Adjust(c *gin.Context) {
 
 	inv, err := h.service.Adjust(c.Request.Context(), req)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "delta ") || strings.HasPrefix(err.Error(), "unit ") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"pos-system/internal/db"
+	"pos-system/internal/quantity"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -17,19 +20,32 @@ func NewService(queries *db.Queries) *Service {
 	return &Service{queries: queries}
 }
 
+// numericToQty reads a NUMERIC quantity column
+func numericToQty(n pgtype.Numeric) quantity.Quantity {
+	qty, err := quantity.FromNumeric(n)
+	if err != nil {
+		return 0
+	}
+	return qty
+}
+
 type InventoryResponse struct {
 	ID        int32  `json:"id"`
 	ProductID int32  `json:"product_id"`
 	ProductName string `json:"product_name"`
 	SKU       *string `json:"sku"`
-	Qty       int32  `json:"qty"`
+	Qty       quantity.Quantity `json:"qty"`
 	Unit      string `json:"unit"`
 	UpdatedAt string `json:"updated_at"`
 }
 
 type AdjustInventoryRequest struct {
 	ProductID int32  `json:"product_id" binding:"required"`
-	Delta     int32  `json:"delta" binding:"required"`
+	// Delta may have as many decimals as the product's qty_precision
+	Delta     quantity.Quantity `json:"delta" binding:"required"`
+	// UnitID counts Delta in one of the product's packaging units, e.g. a
+	// receipt of 5 cartons; stock itself is always kept in the base unit
+	UnitID    *int32 `json:"unit_id"`
 	Reason    string `json:"reason"`
 }
 
@@ -71,22 +87,46 @@ func (s *Service) GetByProductID(ctx context.Context, productID int32) (*Invento
 		ProductID:   invProductID,
 		ProductName: product.Name,
 		SKU:         sku,
-		Qty:         inv.Qty,
+		Qty:         numericToQty(inv.Qty),
 		Unit:        unit,
 		UpdatedAt:   updatedAt,
 	}, nil
 }
 
 func (s *Service) Adjust(ctx context.Context, req AdjustInventoryRequest) (*InventoryResponse, error) {
+	product, err := s.queries.GetProductByID(ctx, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := req.Delta
+	if req.UnitID != nil {
+		unit, err := s.queries.GetProductUnit(ctx, db.GetProductUnitParams{ID: *req.UnitID, ProductID: product.ID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("unit %d not found for product: %s", *req.UnitID, product.Name)
+			}
+			return nil, err
+		}
+		factor, err := quantity.FromNumeric(unit.Factor)
+		if err != nil {
+			return nil, err
+		}
+		delta = req.Delta.Mul(factor)
+	}
+	if !delta.Fits(int(product.QtyPrecision)) {
+		return nil, fmt.Errorf("delta %s for product: %s allows at most %d decimal places", delta, product.Name, product.QtyPrecision)
+	}
+
 	productIDPg := pgtype.Int4{Int32: req.ProductID, Valid: true}
 	// Check if inventory exists
-	_, err := s.queries.GetInventoryByProduct(ctx, productIDPg)
+	_, err = s.queries.GetInventoryByProduct(ctx, productIDPg)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			// Create inventory if it doesn't exist
 			_, err = s.queries.CreateInventory(ctx, db.CreateInventoryParams{
 				ProductID: productIDPg,
-				Qty:       0,
+				Qty:       quantity.Quantity(0).Numeric(),
 			})
 			if err != nil {
 				return nil, err
@@ -98,14 +138,12 @@ func (s *Service) Adjust(ctx context.Context, req AdjustInventoryRequest) (*Inve
 
 	inv, err := s.queries.AdjustInventoryQty(ctx, db.AdjustInventoryQtyParams{
 		ProductID: productIDPg,
-		Qty:       req.Delta,
+		Qty:       delta.Numeric(),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	product, _ := s.queries.GetProductByID(ctx, req.ProductID)
-	
 	var invProductID int32
 	if inv.ProductID.Valid {
 		invProductID = inv.ProductID.Int32
@@ -131,7 +169,7 @@ func (s *Service) Adjust(ctx context.Context, req AdjustInventoryRequest) (*Inve
 		ProductID:   invProductID,
 		ProductName: product.Name,
 		SKU:         sku,
-		Qty:         inv.Qty,
+		Qty:         numericToQty(inv.Qty),
 		Unit:        unit,
 		UpdatedAt:   updatedAt,
 	}, nil
@@ -170,7 +208,7 @@ func (s *Service) List(ctx context.Context) ([]InventoryResponse, error) {
 			ProductID:   productID,
 			ProductName: item.ProductName,
 			SKU:         sku,
-			Qty:         item.Qty,
+			Qty:         numericToQty(item.Qty),
 			Unit:        unit,
 			UpdatedAt:   updatedAt,
 		}
@@ -0,0 +1,59 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"pos-system/internal/customer"
+	"pos-system/internal/sale"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	sales     *sale.Service
+	customers *customer.Service
+	cfg       Config
+}
+
+func NewHandler(sales *sale.Service, customers *customer.Service, cfg Config) *Handler {
+	return &Handler{sales: sales, customers: customers, cfg: cfg}
+}
+
+// PDF serves the A4 invoice for a sale, billed to the sale's customer.
+func (h *Handler) PDF(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sale id"})
+		return
+	}
+
+	s, err := h.sales.GetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err.Error() == "sale not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var billTo *customer.CustomerResponse
+	if s.CustomerID != nil {
+		billTo, err = h.customers.GetByID(c.Request.Context(), *s.CustomerID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, s, billTo, h.cfg); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", s.InvoiceNo+".pdf"))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
@@ -0,0 +1,378 @@
+// Package invoice renders a sale as a formal A4 PDF invoice for business
+// customers. It uses gofpdf's built-in fonts, so it needs no external
+// binaries or font files.
+package invoice
+
+import (
+	"fmt"
+	"io"
+	"pos-system/internal/customer"
+	"pos-system/internal/money"
+	"pos-system/internal/sale"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Config is the store identity and the terms printed on every invoice.
+type Config struct {
+	StoreName    string
+	StoreAddress string
+	StorePhone   string
+	StoreEmail   string
+	// TaxID is the store's NPWP, printed under its name when set
+	TaxID string
+	// Terms is printed at the foot of the invoice, e.g. payment terms
+	Terms string
+}
+
+// Page geometry in millimetres
+const (
+	margin       = 15.0
+	bottomMargin = 20.0
+	pageWidth    = 210.0
+	pageHeight   = 297.0
+	contentW     = pageWidth - 2*margin
+	lineHeight   = 5.0
+	rowHeight    = 6.0
+	totalsLabel  = 55.0
+	totalsValue  = 35.0
+)
+
+type column struct {
+	title string
+	width float64
+	align string
+}
+
+var columns = []column{
+	{"No", 8, "C"},
+	{"Description", 66, "L"},
+	{"Qty", 14, "R"},
+	{"Unit price", 24, "R"},
+	{"Discount", 22, "R"},
+	{"Tax", 20, "R"},
+	{"Amount", 26, "R"},
+}
+
+// taxGroup is one line of the tax breakdown: every item taxed at the same
+// rate in the same way.
+type taxGroup struct {
+	rate      string
+	inclusive bool
+	label     string
+	base      money.Amount
+	tax       money.Amount
+}
+
+// taxLabel names a tax rate the way the item rows and breakdown show it.
+func taxLabel(rate money.Amount, inclusive bool) string {
+	label := rate.Display() + "%"
+	if inclusive {
+		label += " incl."
+	}
+	return label
+}
+
+// taxGroups sums the taxable base and tax of the items per rate and pricing,
+// in the order the groups first appear. Untaxed items are left out.
+func taxGroups(items []sale.SaleItemResponse) ([]*taxGroup, error) {
+	var groups []*taxGroup
+	for _, item := range items {
+		rate, err := money.Parse(item.TaxRate)
+		if err != nil {
+			return nil, err
+		}
+		if rate == 0 {
+			continue
+		}
+		subtotal, err := money.Parse(item.Subtotal)
+		if err != nil {
+			return nil, err
+		}
+		tax, err := money.Parse(item.TaxAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		var group *taxGroup
+		for _, g := range groups {
+			if g.rate == item.TaxRate && g.inclusive == item.TaxInclusive {
+				group = g
+			}
+		}
+		if group == nil {
+			group = &taxGroup{rate: item.TaxRate, inclusive: item.TaxInclusive, label: taxLabel(rate, item.TaxInclusive)}
+			groups = append(groups, group)
+		}
+		group.base += subtotal - tax
+		group.tax += tax
+	}
+	return groups, nil
+}
+
+// Render writes the invoice for s to w. c is the customer billed, or nil for
+// a walk-in sale. Line amounts include their tax; the breakdown lists the
+// taxable base and tax per rate, and they add up to the sale total.
+func Render(w io.Writer, s *sale.SaleResponse, c *customer.CustomerResponse, cfg Config) error {
+	createdAt, err := time.Parse(time.RFC3339, s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("invalid sale date %q", s.CreatedAt)
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, bottomMargin)
+	pdf.SetTitle("Invoice "+s.InvoiceNo, true)
+	pdf.SetAuthor(cfg.StoreName, true)
+	pdf.SetCreationDate(createdAt)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.CellFormat(contentW/2, lineHeight, tr(s.InvoiceNo), "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentW/2, lineHeight, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// Store identity on the left, invoice details on the right
+	top := pdf.GetY()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(110, 8, tr(cfg.StoreName), "", 2, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	for _, text := range []string{cfg.StoreAddress, cfg.StorePhone, cfg.StoreEmail} {
+		if text != "" {
+			pdf.MultiCell(110, lineHeight-0.5, tr(text), "", "L", false)
+		}
+	}
+	if cfg.TaxID != "" {
+		pdf.CellFormat(110, lineHeight-0.5, tr("NPWP: "+cfg.TaxID), "", 2, "L", false, 0, "")
+	}
+	storeBottom := pdf.GetY()
+
+	pdf.SetXY(margin+110, top)
+	pdf.SetFont("Helvetica", "B", 20)
+	pdf.CellFormat(contentW-110, 10, "INVOICE", "", 2, "R", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	details := [][2]string{
+		{"Invoice no", s.InvoiceNo},
+		{"Date", createdAt.Format("02 Jan 2006 15:04")},
+	}
+	if s.CashierName != nil {
+		details = append(details, [2]string{"Cashier", *s.CashierName})
+	}
+	for _, d := range details {
+		pdf.SetX(margin + 110)
+		pdf.CellFormat(25, lineHeight, tr(d[0]), "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentW-135, lineHeight, tr(d[1]), "", 1, "R", false, 0, "")
+	}
+	if pdf.GetY() < storeBottom {
+		pdf.SetY(storeBottom)
+	}
+
+	if s.VoidedAt != nil {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.SetTextColor(200, 0, 0)
+		status := "VOID"
+		if s.VoidReason != nil {
+			status += " - " + *s.VoidReason
+		}
+		pdf.CellFormat(contentW, 8, tr(status), "", 1, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	}
+	if s.PaymentStatus == "pending" {
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.SetTextColor(200, 0, 0)
+		pdf.CellFormat(contentW, 8, "AWAITING PAYMENT", "", 1, "L", false, 0, "")
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	// Bill to
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.CellFormat(contentW, lineHeight, "Bill to", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	if c == nil {
+		pdf.CellFormat(contentW, lineHeight, "Walk-in customer", "", 1, "L", false, 0, "")
+	} else {
+		pdf.CellFormat(contentW, lineHeight, tr(c.Name), "", 1, "L", false, 0, "")
+		if c.Phone != nil {
+			pdf.CellFormat(contentW, lineHeight, tr(*c.Phone), "", 1, "L", false, 0, "")
+		}
+		if c.Email != nil {
+			pdf.CellFormat(contentW, lineHeight, tr(*c.Email), "", 1, "L", false, 0, "")
+		}
+	}
+	pdf.Ln(4)
+
+	// Line items
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(235, 235, 235)
+		for _, col := range columns {
+			pdf.CellFormat(col.width, rowHeight+1, col.title, "1", 0, col.align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	header()
+
+	groups, err := taxGroups(s.Items)
+	if err != nil {
+		return err
+	}
+	for i, item := range s.Items {
+		price, err := money.Parse(item.Price)
+		if err != nil {
+			return err
+		}
+		subtotal, err := money.Parse(item.Subtotal)
+		if err != nil {
+			return err
+		}
+		rate, err := money.Parse(item.TaxRate)
+		if err != nil {
+			return err
+		}
+
+		// Everything taken off the catalogue price: manual, promotion and voucher
+		var discount money.Amount
+		for _, d := range []string{item.Discount, item.PromotionDiscount, item.VoucherDiscount} {
+			amount, err := money.Parse(d)
+			if err != nil {
+				return err
+			}
+			discount += amount
+		}
+
+		taxText := "-"
+		if rate != 0 {
+			taxText = taxLabel(rate, item.TaxInclusive)
+		}
+
+		// SplitLines works on the translated bytes; SplitText expects UTF-8
+		name := item.ProductName
+		if item.Unit != nil {
+			name += " (" + *item.Unit + ")"
+		}
+		description := pdf.SplitLines([]byte(tr(name)), columns[1].width-2)
+		if len(description) == 0 {
+			description = [][]byte{nil}
+		}
+		height := rowHeight * float64(len(description))
+		// Keep each row on one page, repeating the header on the next
+		if pdf.GetY()+height > pageHeight-bottomMargin {
+			pdf.AddPage()
+			header()
+		}
+
+		cells := []string{
+			fmt.Sprintf("%d", i+1),
+			"",
+			item.Qty.Display(),
+			price.Display(),
+			discount.Display(),
+			taxText,
+			subtotal.Display(),
+		}
+		x, y := pdf.GetXY()
+		for j, col := range columns {
+			if j == 1 {
+				pdf.Rect(x, y, col.width, height, "D")
+				for k, text := range description {
+					pdf.SetXY(x, y+rowHeight*float64(k))
+					pdf.CellFormat(col.width, rowHeight, string(text), "", 0, "L", false, 0, "")
+				}
+				pdf.SetXY(x+col.width, y)
+			} else {
+				pdf.CellFormat(col.width, height, cells[j], "1", 0, col.align, false, 0, "")
+			}
+			x += col.width
+		}
+		pdf.SetXY(margin, y+height)
+	}
+
+	// Totals, right-aligned under the table
+	total, err := money.Parse(s.TotalAmount)
+	if err != nil {
+		return err
+	}
+	subtotal, err := money.Parse(s.SubtotalAmount)
+	if err != nil {
+		return err
+	}
+	paid, err := money.Parse(s.PaidAmount)
+	if err != nil {
+		return err
+	}
+	change, err := money.Parse(s.ChangeAmount)
+	if err != nil {
+		return err
+	}
+
+	totalsRow := func(label, value string, bold bool) {
+		style := ""
+		if bold {
+			style = "B"
+		}
+		pdf.SetFont("Helvetica", style, 9)
+		pdf.SetX(margin + contentW - totalsLabel - totalsValue)
+		pdf.CellFormat(totalsLabel, rowHeight, tr(label), "", 0, "L", false, 0, "")
+		pdf.CellFormat(totalsValue, rowHeight, value, "", 1, "R", false, 0, "")
+	}
+
+	pdf.Ln(3)
+	totalsRow("Subtotal (excl. tax)", subtotal.Display(), false)
+	for _, g := range groups {
+		totalsRow(fmt.Sprintf("Tax %s on %s", g.label, g.base.Display()), g.tax.Display(), false)
+	}
+	totalsRow("Total", total.Display(), true)
+	if s.RoundingAmount != "" {
+		rounding, err := money.Parse(s.RoundingAmount)
+		if err != nil {
+			return err
+		}
+		if rounding != 0 {
+			totalsRow("Cash rounding", rounding.Display(), false)
+			totalsRow("Total due", (total + rounding).Display(), true)
+		}
+	}
+	for _, p := range s.Payments {
+		amount, err := money.Parse(p.Amount)
+		if err != nil {
+			return err
+		}
+		totalsRow("Paid by "+p.Method, amount.Display(), false)
+	}
+	if len(s.Payments) == 0 {
+		totalsRow("Paid", paid.Display(), false)
+	}
+	if change != 0 {
+		totalsRow("Change", change.Display(), false)
+	}
+
+	if s.VoucherCode != nil {
+		voucherDiscount, err := money.Parse(s.VoucherDiscount)
+		if err != nil {
+			return err
+		}
+		pdf.Ln(2)
+		pdf.SetFont("Helvetica", "I", 8)
+		note := fmt.Sprintf("Discounts include voucher %s (%s).", *s.VoucherCode, voucherDiscount.Display())
+		pdf.CellFormat(contentW, lineHeight, tr(note), "", 1, "L", false, 0, "")
+	}
+
+	if cfg.Terms != "" {
+		pdf.Ln(8)
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.CellFormat(contentW, lineHeight, "Terms", "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.MultiCell(contentW, lineHeight-0.5, tr(cfg.Terms), "", "L", false)
+	}
+
+	return pdf.Output(w)
+}
@@ -0,0 +1,144 @@
+package invoice
+
+import (
+	"bytes"
+	"fmt"
+	"pos-system/internal/customer"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"pos-system/internal/sale"
+	"testing"
+)
+
+var testConfig = Config{
+	StoreName:    "Toko Maju",
+	StoreAddress: "Jl. Merdeka 1, Bandung",
+	StorePhone:   "022-123456",
+	TaxID:        "01.234.567.8-901.000",
+	Terms:        "Payment due within 14 days.",
+}
+
+func item(name, price, qty, subtotal, rate, tax string, inclusive bool) sale.SaleItemResponse {
+	return sale.SaleItemResponse{
+		ProductName:       name,
+		Qty:               quantity.MustParse(qty),
+		UnitFactor:        quantity.New(1),
+		Price:             price,
+		Discount:          "0.00",
+		Subtotal:          subtotal,
+		ListPrice:         price,
+		TaxRate:           rate,
+		TaxInclusive:      inclusive,
+		TaxAmount:         tax,
+		PromotionDiscount: "0.00",
+		VoucherDiscount:   "0.00",
+	}
+}
+
+// testSale mixes tax-exclusive, tax-inclusive and untaxed lines. Line
+// subtotals include their tax.
+func testSale() *sale.SaleResponse {
+	cashier := "Budi"
+	return &sale.SaleResponse{
+		ID:             1,
+		InvoiceNo:      "INV-20260115-00001",
+		CashierName:    &cashier,
+		SubtotalAmount: "40000.00",
+		TaxAmount:      "3850.00",
+		TotalAmount:    "43850.00",
+		PaidAmount:     "50000.00",
+		ChangeAmount:   "6150.00",
+		RoundingAmount: "0.00",
+		PaymentStatus:  "paid",
+		Payments:       []sale.SalePaymentResponse{{ID: 1, Method: "cash", Amount: "43850.00"}},
+		Items: []sale.SaleItemResponse{
+			item("Kopi susu", "10000.00", "2", "22200.00", "11.00", "2200.00", false),
+			item("Teh <manis> & \"dingin\"", "11100.00", "1", "11100.00", "11.00", "1100.00", true),
+			item("Nasi putih", "5000.00", "1", "5000.00", "0.00", "0.00", false),
+			item("Keripik", "5000.00", "1", "5550.00", "11.00", "550.00", false),
+		},
+		CreatedAt: "2026-01-15T10:30:00Z",
+	}
+}
+
+// pages counts the page objects of a rendered PDF.
+func pages(pdf []byte) int {
+	return bytes.Count(pdf, []byte("<</Type /Page\n"))
+}
+
+func TestRender(t *testing.T) {
+	email := "finance@example.com"
+	billed := &customer.CustomerResponse{ID: 1, Name: "PT Sinar Jaya", Email: &email}
+
+	tests := []struct {
+		name     string
+		customer *customer.CustomerResponse
+	}{
+		{"walk-in", nil},
+		{"billed customer", billed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Render(&buf, testSale(), tt.customer, testConfig); err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")) {
+				t.Fatalf("output starts with %q, want %%PDF-", buf.Bytes()[:min(8, buf.Len())])
+			}
+			if got := pages(buf.Bytes()); got != 1 {
+				t.Errorf("%d pages, want 1", got)
+			}
+		})
+	}
+}
+
+func TestRenderSpillsOntoNextPage(t *testing.T) {
+	s := testSale()
+	s.Items = nil
+	for i := 0; i < 60; i++ {
+		s.Items = append(s.Items, item(fmt.Sprintf("Item %d", i+1), "1000.00", "1", "1000.00", "0.00", "0.00", false))
+	}
+	s.SubtotalAmount, s.TaxAmount, s.TotalAmount = "60000.00", "0.00", "60000.00"
+
+	var buf bytes.Buffer
+	if err := Render(&buf, s, nil, testConfig); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if got := pages(buf.Bytes()); got < 2 {
+		t.Errorf("%d lines rendered on %d page, want them to spill onto a second", len(s.Items), got)
+	}
+}
+
+func TestTaxGroupsAddUpToSaleTax(t *testing.T) {
+	s := testSale()
+	groups, err := taxGroups(s.Items)
+	if err != nil {
+		t.Fatalf("taxGroups: %v", err)
+	}
+
+	want := []taxGroup{
+		{rate: "11.00", inclusive: false, label: "11%", base: money.New(25000), tax: money.New(2750)},
+		{rate: "11.00", inclusive: true, label: "11% incl.", base: money.New(10000), tax: money.New(1100)},
+	}
+	if len(groups) != len(want) {
+		t.Fatalf("%d tax groups, want %d", len(groups), len(want))
+	}
+
+	var tax, base money.Amount
+	for i, g := range groups {
+		if *g != want[i] {
+			t.Errorf("group %d = %+v, want %+v", i, *g, want[i])
+		}
+		tax += g.tax
+		base += g.base
+	}
+	if total := money.MustParse(s.TaxAmount); tax != total {
+		t.Errorf("tax groups add up to %s, want the sale's tax %s", tax.Display(), total.Display())
+	}
+	// The untaxed line is the rest of the subtotal
+	if subtotal := money.MustParse(s.SubtotalAmount); base+money.New(5000) != subtotal {
+		t.Errorf("taxable bases %s and untaxed 5.000 do not make the subtotal %s", base.Display(), subtotal.Display())
+	}
+}
@@ -0,0 +1,247 @@
+// Package money provides an exact decimal type for prices, totals and
+// payments, so amounts never pass through float64 on their way between JSON
+// and the NUMERIC(12,2)/NUMERIC(14,2) columns.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Amount is a monetary value with two decimal places, held as a whole number
+// of cents (sen). Amounts add, subtract and compare with the usual operators.
+type Amount int64
+
+// maxIntegerDigits keeps parsed amounts well inside int64 cents while still
+// covering NUMERIC(14,2).
+const maxIntegerDigits = 15
+
+// New returns an amount of whole currency units.
+func New(units int64) Amount {
+	return Amount(units * 100)
+}
+
+// FromCents returns the amount of cents given.
+func FromCents(cents int64) Amount {
+	return Amount(cents)
+}
+
+// Parse reads a decimal string such as "15000", "15000.5" or "-2500.75".
+// More than two decimal places is an error rather than a silent rounding.
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	digits := s
+	negative := false
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("amount %q has more than two decimal places", s)
+	}
+	if len(whole) > maxIntegerDigits {
+		return 0, fmt.Errorf("amount %q is too large", s)
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+
+	var cents int64
+	for _, c := range whole + (frac + "00")[:2] {
+		cents = cents*10 + int64(c-'0')
+	}
+	if negative {
+		cents = -cents
+	}
+	return Amount(cents), nil
+}
+
+// MustParse is like Parse but panics on error. It is meant for constants and
+// tests.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FromNumeric converts a NUMERIC column. NULL is zero, and values with more
+// than two decimal places (averages, for instance) are rounded half away from
+// zero.
+func FromNumeric(n pgtype.Numeric) (Amount, error) {
+	if !n.Valid {
+		return 0, nil
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return 0, errors.New("numeric is not a finite amount")
+	}
+
+	cents := new(big.Int).Set(n.Int)
+	if exp := n.Exp + 2; exp >= 0 {
+		cents.Mul(cents, pow10(exp))
+	} else {
+		cents = divRound(cents, pow10(-exp))
+	}
+
+	if !cents.IsInt64() {
+		return 0, errors.New("numeric is out of range for an amount")
+	}
+	return Amount(cents.Int64()), nil
+}
+
+// Numeric converts the amount for a NUMERIC column.
+func (a Amount) Numeric() pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(int64(a)), Exp: -2, Valid: true}
+}
+
+// Cents returns the amount as a whole number of cents.
+func (a Amount) Cents() int64 {
+	return int64(a)
+}
+
+// Mul multiplies the amount by a whole quantity.
+func (a Amount) Mul(qty int64) Amount {
+	return a * Amount(qty)
+}
+
+// MulDiv returns a*num/den rounded half away from zero, e.g. to prorate a
+// line subtotal over part of its quantity.
+func (a Amount) MulDiv(num, den int64) Amount {
+	v := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(num))
+	return Amount(divRound(v, big.NewInt(den)).Int64())
+}
+
+// Allocate splits a into parts proportional to weights, rounding each part
+// and giving the rounding remainder to the last non-zero weight so the parts
+// always sum to a. Non-positive weights get nothing.
+func (a Amount) Allocate(weights []Amount) []Amount {
+	parts := make([]Amount, len(weights))
+
+	var total Amount
+	last := -1
+	for i, w := range weights {
+		if w > 0 {
+			total += w
+			last = i
+		}
+	}
+	if last < 0 {
+		return parts
+	}
+
+	left := a
+	for i, w := range weights {
+		if w <= 0 {
+			continue
+		}
+		if i == last {
+			parts[i] = left
+			break
+		}
+		parts[i] = a.MulDiv(w.Cents(), total.Cents())
+		left -= parts[i]
+	}
+	return parts
+}
+
+// String formats the amount with exactly two decimals, e.g. "15000.50".
+func (a Amount) String() string {
+	cents := int64(a)
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// Display formats the amount the way rupiah are printed on receipts and
+// invoices: dots between thousands, and a decimal comma only when there are
+// cents, e.g. "15.000" or "15.000,50".
+func (a Amount) Display() string {
+	cents := int64(a)
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	digits := strconv.FormatInt(cents/100, 10)
+	var grouped strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(d)
+	}
+
+	if cents%100 != 0 {
+		return fmt.Sprintf("%s%s,%02d", sign, grouped.String(), cents%100)
+	}
+	return sign + grouped.String()
+}
+
+// MarshalJSON writes the amount as a string so clients never see a float.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(a.String())), nil
+}
+
+// UnmarshalJSON accepts a decimal string ("15000.50") and, for older
+// clients, a plain JSON number (15000.5). Numbers are parsed from their
+// literal text, never through float64.
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return fmt.Errorf("invalid amount %s", data)
+		}
+	}
+
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// divRound divides v by d, rounding half away from zero.
+func divRound(v, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(v, d, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(new(big.Int).Abs(d)) >= 0 {
+		if v.Sign()*d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
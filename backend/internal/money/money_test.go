@@ -0,0 +1,262 @@
+package money
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Amount
+	}{
+		{"15000", 1500000},
+		{"15000.5", 1500050},
+		{"15000.50", 1500050},
+		{"0.01", 1},
+		{".5", 50},
+		{"-2500.75", -250075},
+		{"+10", 1000},
+		{" 7.00 ", 700},
+		{"999999999999.99", 99999999999999},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d cents, want %d", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "-", ".", "1.234", "1e3", "12a", "1.2.3", "1234567890123456"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) should fail", in)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := map[Amount]string{
+		0:        "0.00",
+		5:        "0.05",
+		1500050:  "15000.50",
+		-250075:  "-2500.75",
+		New(100): "100.00",
+	}
+
+	for amount, want := range tests {
+		if got := amount.String(); got != want {
+			t.Errorf("Amount(%d).String() = %q, want %q", int64(amount), got, want)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	tests := map[Amount]string{
+		0:             "0",
+		50:            "0,50",
+		New(1000):     "1.000",
+		2500050:       "25.000,50",
+		New(-1500):    "-1.500",
+		New(12345678): "12.345.678",
+	}
+
+	for amount, want := range tests {
+		if got := amount.Display(); got != want {
+			t.Errorf("Amount(%d).Display() = %q, want %q", int64(amount), got, want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var req struct {
+		Price    Amount  `json:"price"`
+		Discount Amount  `json:"discount"`
+		Cost     *Amount `json:"cost"`
+	}
+	if err := json.Unmarshal([]byte(`{"price":"15999.99","discount":2500.5,"cost":null}`), &req); err != nil {
+		t.Fatalf("Failed to unmarshal: %v", err)
+	}
+	if req.Price != 1599999 || req.Discount != 250050 || req.Cost != nil {
+		t.Errorf("Unexpected amounts: price %d, discount %d, cost %v", req.Price, req.Discount, req.Cost)
+	}
+
+	if err := json.Unmarshal([]byte(`{"price":"0.001"}`), &req); err == nil {
+		t.Error("Expected an error for an amount with three decimals")
+	}
+
+	out, err := json.Marshal(map[string]Amount{"total": 1234567})
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if string(out) != `{"total":"12345.67"}` {
+		t.Errorf("Expected amounts to marshal as strings, got %s", out)
+	}
+}
+
+func TestNumeric(t *testing.T) {
+	amount := MustParse("123456789012.34")
+	got, err := FromNumeric(amount.Numeric())
+	if err != nil {
+		t.Fatalf("Failed to convert numeric: %v", err)
+	}
+	if got != amount {
+		t.Errorf("Round trip through numeric gave %s, want %s", got, amount)
+	}
+
+	tests := []struct {
+		n    pgtype.Numeric
+		want Amount
+	}{
+		{pgtype.Numeric{}, 0},
+		{pgtype.Numeric{Int: big.NewInt(15), Exp: 3, Valid: true}, New(15000)},
+		{pgtype.Numeric{Int: big.NewInt(123456789), Exp: -5, Valid: true}, 123457},
+		{pgtype.Numeric{Int: big.NewInt(-123455), Exp: -3, Valid: true}, -12346},
+		{pgtype.Numeric{Int: big.NewInt(1234), Exp: -3, Valid: true}, 123},
+	}
+	for _, tt := range tests {
+		got, err := FromNumeric(tt.n)
+		if err != nil {
+			t.Errorf("FromNumeric(%v) returned error: %v", tt.n, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("FromNumeric(%v e%d) = %s, want %s", tt.n.Int, tt.n.Exp, got, tt.want)
+		}
+	}
+
+	if _, err := FromNumeric(pgtype.Numeric{NaN: true, Valid: true}); err == nil {
+		t.Error("Expected an error for NaN")
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	subtotal := MustParse("100000.00")
+	if got := subtotal.MulDiv(1, 3); got != MustParse("33333.33") {
+		t.Errorf("Expected 33333.33, got %s", got)
+	}
+	if got := subtotal.MulDiv(2, 3); got != MustParse("66666.67") {
+		t.Errorf("Expected 66666.67, got %s", got)
+	}
+	if got := MustParse("-0.05").MulDiv(1, 2); got != MustParse("-0.03") {
+		t.Errorf("Expected -0.03, got %s", got)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	parts := MustParse("15.00").Allocate([]Amount{MustParse("20.00"), 0, MustParse("60.00"), MustParse("30.00")})
+	want := []Amount{MustParse("2.73"), 0, MustParse("8.18"), MustParse("4.09")}
+	for i := range want {
+		if parts[i] != want[i] {
+			t.Errorf("part %d: expected %s, got %s", i, want[i], parts[i])
+		}
+	}
+
+	parts = MustParse("10.00").Allocate([]Amount{0, 0})
+	if parts[0] != 0 || parts[1] != 0 {
+		t.Errorf("Expected nothing allocated without weights, got %v", parts)
+	}
+}
+
+// saleLines are priced like large IDR sales, where summing float64 line
+// totals drifts away from what the NUMERIC columns hold.
+var saleLines = []struct {
+	price    string
+	qty      int64
+	discount string
+}{
+	{"15999999.99", 3, "0.03"},
+	{"0.10", 7, "0"},
+	{"0.20", 9, "0.01"},
+	{"7665000.00", 1, "0"},
+	{"1234567.89", 11, "12.34"},
+	{"333333.33", 3, "0.99"},
+	{"45000.05", 17, "5.05"},
+}
+
+// numericSum computes the sale total the way Postgres does for NUMERIC(14,2):
+// exact decimal arithmetic.
+func numericSum() *big.Rat {
+	total := new(big.Rat)
+	for _, l := range saleLines {
+		price, _ := new(big.Rat).SetString(l.price)
+		discount, _ := new(big.Rat).SetString(l.discount)
+		line := new(big.Rat).Mul(price, new(big.Rat).SetInt64(l.qty))
+		total.Add(total, line.Sub(line, discount))
+	}
+	return total
+}
+
+func TestTotalMatchesNumericSum(t *testing.T) {
+	var total Amount
+	for _, l := range saleLines {
+		total += MustParse(l.price).Mul(l.qty) - MustParse(l.discount)
+	}
+
+	want := numericSum()
+	if want.FloatString(2) != total.String() {
+		t.Errorf("Total %s does not match numeric sum %s", total, want.FloatString(2))
+	}
+}
+
+// TestTotalMatchesDatabaseSum checks the same lines against a real
+// NUMERIC(14,2) sum. It runs when TEST_DATABASE_URL points at a Postgres
+// database.
+func TestTotalMatchesDatabaseSum(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close(ctx)
+
+	prices := make([]string, len(saleLines))
+	qtys := make([]int64, len(saleLines))
+	discounts := make([]string, len(saleLines))
+	var total Amount
+	for i, l := range saleLines {
+		prices[i], qtys[i], discounts[i] = l.price, l.qty, l.discount
+		total += MustParse(l.price).Mul(l.qty) - MustParse(l.discount)
+	}
+
+	var sum pgtype.Numeric
+	err = conn.QueryRow(ctx, `
+		SELECT SUM((p::numeric(12,2) * q - d::numeric(12,2))::numeric(14,2))::numeric(14,2)
+		FROM unnest($1::text[], $2::bigint[], $3::text[]) AS l(p, q, d)`,
+		prices, qtys, discounts).Scan(&sum)
+	if err != nil {
+		t.Fatalf("Failed to sum in the database: %v", err)
+	}
+
+	dbTotal, err := FromNumeric(sum)
+	if err != nil {
+		t.Fatalf("Failed to convert database sum: %v", err)
+	}
+	if dbTotal != total {
+		t.Errorf("Total %s does not match database sum %s", total, dbTotal)
+	}
+
+	// And the amount written back comes out unchanged
+	var stored pgtype.Numeric
+	if err := conn.QueryRow(ctx, `SELECT $1::numeric(14,2)`, total.Numeric()).Scan(&stored); err != nil {
+		t.Fatalf("Failed to round trip total: %v", err)
+	}
+	if got, _ := FromNumeric(stored); got != total {
+		t.Errorf("Stored total %s, want %s", got, total)
+	}
+}
@@ -0,0 +1,163 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"pos-system/internal/money"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Mock is an in-memory provider for development and tests. Charges stay
+// pending until Settle is called, which returns the signed callback a real
+// provider would send. Charges are lost when the process exits.
+type Mock struct {
+	secret string
+
+	mu       sync.Mutex
+	charges  map[string]Charge
+	refunded map[string]money.Amount
+}
+
+// mockCallback is the body of the callbacks Mock signs.
+type mockCallback struct {
+	Reference string       `json:"reference"`
+	Status    string       `json:"status"`
+	Amount    money.Amount `json:"amount"`
+	PaidAt    *time.Time   `json:"paid_at,omitempty"`
+}
+
+func NewMock(callbackSecret string) *Mock {
+	return &Mock{secret: callbackSecret, charges: make(map[string]Charge), refunded: make(map[string]money.Amount)}
+}
+
+func (m *Mock) Name() string {
+	return "mock"
+}
+
+func (m *Mock) CreateCharge(_ context.Context, req ChargeRequest) (Charge, error) {
+	if req.Amount <= 0 {
+		return Charge{}, errors.New("charge amount must be greater than zero")
+	}
+
+	// References are random so they stay unique across restarts
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return Charge{}, err
+	}
+	reference := "MOCK-" + strings.ToUpper(hex.EncodeToString(b))
+
+	charge := Charge{
+		Reference: reference,
+		Status:    StatusPending,
+		Amount:    req.Amount,
+		ExpiresAt: req.ExpiresAt,
+	}
+	if strings.EqualFold(req.Method, MethodEWallet) {
+		charge.CheckoutURL = "https://pay.mock.invalid/" + reference
+	} else {
+		charge.QRString = fmt.Sprintf("MOCKQRIS|%s|%s|%s", reference, req.OrderID, req.Amount)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.charges[reference] = charge
+	return charge, nil
+}
+
+func (m *Mock) Status(_ context.Context, reference string) (Charge, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	charge, ok := m.charges[reference]
+	if !ok {
+		return Charge{}, errors.New("payment charge not found")
+	}
+	if charge.Status == StatusPending && !charge.ExpiresAt.IsZero() && time.Now().After(charge.ExpiresAt) {
+		charge.Status = StatusExpired
+		m.charges[reference] = charge
+	}
+	return charge, nil
+}
+
+func (m *Mock) Refund(_ context.Context, reference string, amount money.Amount) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	charge, ok := m.charges[reference]
+	if !ok {
+		return errors.New("payment charge not found")
+	}
+	if charge.Status != StatusPaid {
+		return errors.New("only a paid charge can be refunded")
+	}
+	// A charge can be refunded in parts, as items are returned
+	refunded := m.refunded[reference] + amount
+	if amount <= 0 || refunded > charge.Amount {
+		return errors.New("refund amount must be between zero and what is left of the charge")
+	}
+	m.refunded[reference] = refunded
+	if refunded == charge.Amount {
+		charge.Status = StatusRefunded
+		m.charges[reference] = charge
+	}
+	return nil
+}
+
+func (m *Mock) VerifyCallback(header http.Header, body []byte) (Charge, error) {
+	if err := verifySignature(m.secret, header, body); err != nil {
+		return Charge{}, err
+	}
+
+	var cb mockCallback
+	if err := json.Unmarshal(body, &cb); err != nil || cb.Reference == "" {
+		return Charge{}, errors.New("invalid callback body")
+	}
+	charge := Charge{Reference: cb.Reference, Status: cb.Status, Amount: cb.Amount}
+	if cb.PaidAt != nil {
+		charge.PaidAt = *cb.PaidAt
+	}
+	return charge, nil
+}
+
+// Settle ends a pending charge with status paid, failed or expired, as if
+// the customer had paid or walked away, and returns the callback body and
+// signature the provider would post.
+func (m *Mock) Settle(reference, status string) ([]byte, string, error) {
+	switch status {
+	case StatusPaid, StatusFailed, StatusExpired:
+	default:
+		return nil, "", errors.New("status must be paid, failed or expired")
+	}
+
+	m.mu.Lock()
+	charge, ok := m.charges[reference]
+	if !ok {
+		m.mu.Unlock()
+		return nil, "", errors.New("payment charge not found")
+	}
+	if charge.Status != StatusPending {
+		m.mu.Unlock()
+		return nil, "", errors.New("payment charge is not pending")
+	}
+	charge.Status = status
+	cb := mockCallback{Reference: reference, Status: status, Amount: charge.Amount}
+	if status == StatusPaid {
+		charge.PaidAt = time.Now()
+		cb.PaidAt = &charge.PaidAt
+	}
+	m.charges[reference] = charge
+	m.mu.Unlock()
+
+	body, err := json.Marshal(cb)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, Sign(m.secret, body), nil
+}
@@ -0,0 +1,87 @@
+package payment
+
+import (
+	"context"
+	"net/http"
+	"pos-system/internal/money"
+	"testing"
+	"time"
+)
+
+func TestMockCallbackRoundTrip(t *testing.T) {
+	m := NewMock("secret")
+	ctx := context.Background()
+
+	charge, err := m.CreateCharge(ctx, ChargeRequest{
+		OrderID:   "INV-20260101-00001",
+		Method:    MethodQRIS,
+		Amount:    money.MustParse("25000"),
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateCharge: %v", err)
+	}
+	if charge.Status != StatusPending || charge.QRString == "" {
+		t.Fatalf("new charge = %+v, want a pending charge with a QR string", charge)
+	}
+
+	body, signature, err := m.Settle(charge.Reference, StatusPaid)
+	if err != nil {
+		t.Fatalf("Settle: %v", err)
+	}
+
+	header := http.Header{}
+	header.Set(SignatureHeader, signature)
+	reported, err := m.VerifyCallback(header, body)
+	if err != nil {
+		t.Fatalf("VerifyCallback: %v", err)
+	}
+	if reported.Reference != charge.Reference || reported.Status != StatusPaid || reported.Amount != charge.Amount {
+		t.Errorf("callback reported %+v, want %s paid for %s", reported, charge.Reference, charge.Amount)
+	}
+	if reported.PaidAt.IsZero() {
+		t.Error("paid callback has no paid_at")
+	}
+
+	if _, _, err := m.Settle(charge.Reference, StatusFailed); err == nil {
+		t.Error("settling a paid charge again should fail")
+	}
+	// Refunds can come in parts but never add up to more than the charge
+	part := charge.Amount.MulDiv(1, 4)
+	if err := m.Refund(ctx, charge.Reference, part); err != nil {
+		t.Errorf("Refund: %v", err)
+	}
+	if err := m.Refund(ctx, charge.Reference, charge.Amount); err == nil {
+		t.Error("refunding more than is left of the charge should fail")
+	}
+	if err := m.Refund(ctx, charge.Reference, charge.Amount-part); err != nil {
+		t.Errorf("Refund: %v", err)
+	}
+	if current, _ := m.Status(ctx, charge.Reference); current.Status != StatusRefunded {
+		t.Errorf("fully refunded charge has status %s, want %s", current.Status, StatusRefunded)
+	}
+}
+
+func TestMockRejectsBadSignature(t *testing.T) {
+	m := NewMock("secret")
+	charge, err := m.CreateCharge(context.Background(), ChargeRequest{Method: MethodEWallet, Amount: money.MustParse("1000")})
+	if err != nil {
+		t.Fatalf("CreateCharge: %v", err)
+	}
+	if charge.CheckoutURL == "" {
+		t.Errorf("e-wallet charge has no checkout URL")
+	}
+
+	body, _, err := m.Settle(charge.Reference, StatusPaid)
+	if err != nil {
+		t.Fatalf("Settle: %v", err)
+	}
+
+	for _, signature := range []string{"", "not-hex", Sign("other secret", body)} {
+		header := http.Header{}
+		header.Set(SignatureHeader, signature)
+		if _, err := m.VerifyCallback(header, body); err == nil || err.Error() != "invalid callback signature" {
+			t.Errorf("VerifyCallback with signature %q = %v, want invalid callback signature", signature, err)
+		}
+	}
+}
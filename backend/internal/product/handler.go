@@ -3,6 +3,7 @@ package product
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,6 +16,14 @@ func NewHandler(service *Service) *Handler {
 	return &Handler{service: service}
 }
 
+// isValidationError reports errors caused by the request: a category or tax
+// rate that does not exist, or quantities the product cannot be sold in.
+func isValidationError(err error) bool {
+	errMsg := err.Error()
+	return errMsg == "category not found" || errMsg == "tax rate not found" ||
+		strings.HasPrefix(errMsg, "qty precision") || strings.HasPrefix(errMsg, "initial stock")
+}
+
 func (h *Handler) Create(c *gin.Context) {
 	var req CreateProductRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -24,8 +33,7 @@ func (h *Handler) Create(c *gin.Context) {
 
 	product, err := h.service.Create(c.Request.Context(), req)
 	if err != nil {
-		// Check if it's a validation error (category not found)
-		if err.Error() == "category not found" {
+		if isValidationError(err) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -81,6 +89,25 @@ func (h *Handler) Search(c *gin.Context) {
 	c.JSON(http.StatusOK, products)
 }
 
+// Scan resolves a scanned barcode, decoding scale labels into product and
+// weight.
+func (h *Handler) Scan(c *gin.Context) {
+	scan, err := h.service.Scan(c.Request.Context(), c.Param("code"))
+	if err != nil {
+		switch errMsg := err.Error(); {
+		case errMsg == "product not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": errMsg})
+		case strings.HasPrefix(errMsg, "barcode"), strings.HasPrefix(errMsg, "scale label"), strings.HasPrefix(errMsg, "product "):
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, scan)
+}
+
 func (h *Handler) Update(c *gin.Context) {
 	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
 	if err != nil {
@@ -96,8 +123,11 @@ func (h *Handler) Update(c *gin.Context) {
 
 	product, err := h.service.Update(c.Request.Context(), int32(id), req)
 	if err != nil {
-		// Check if it's a validation error (category not found)
-		if err.Error() == "category not found" {
+		if err.Error() == "product not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if isValidationError(err) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
@@ -123,3 +153,106 @@ func (h *Handler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "product deleted"})
 }
 
+
+// unitErrorStatus maps the errors of the unit endpoints to HTTP statuses.
+func unitErrorStatus(err error) int {
+	switch errMsg := err.Error(); {
+	case errMsg == "product not found", errMsg == "unit not found":
+		return http.StatusNotFound
+	case errMsg == "unit name already exists for this product":
+		return http.StatusConflict
+	case strings.HasPrefix(errMsg, "unit "):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// unitParams reads the product id and, when the route has one, the unit id.
+func unitParams(c *gin.Context) (productID, unitID int32, ok bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid product id"})
+		return 0, 0, false
+	}
+	if c.Param("unit_id") == "" {
+		return int32(id), 0, true
+	}
+	uid, err := strconv.ParseInt(c.Param("unit_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unit id"})
+		return 0, 0, false
+	}
+	return int32(id), int32(uid), true
+}
+
+func (h *Handler) ListUnits(c *gin.Context) {
+	productID, _, ok := unitParams(c)
+	if !ok {
+		return
+	}
+
+	units, err := h.service.ListUnits(c.Request.Context(), productID)
+	if err != nil {
+		c.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, units)
+}
+
+func (h *Handler) CreateUnit(c *gin.Context) {
+	productID, _, ok := unitParams(c)
+	if !ok {
+		return
+	}
+
+	var req UnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unit, err := h.service.CreateUnit(c.Request.Context(), productID, req)
+	if err != nil {
+		c.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, unit)
+}
+
+func (h *Handler) UpdateUnit(c *gin.Context) {
+	productID, unitID, ok := unitParams(c)
+	if !ok {
+		return
+	}
+
+	var req UnitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unit, err := h.service.UpdateUnit(c.Request.Context(), productID, unitID, req)
+	if err != nil {
+		c.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, unit)
+}
+
+func (h *Handler) DeleteUnit(c *gin.Context) {
+	productID, unitID, ok := unitParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteUnit(c.Request.Context(), productID, unitID); err != nil {
+		c.JSON(unitErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "unit deleted"})
+}
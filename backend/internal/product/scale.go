@@ -0,0 +1,100 @@
+package product
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ScaleModeWeight labels carry the quantity weighed, in the smallest
+	// step of the product's qty_precision (grams for a kg product with 3)
+	ScaleModeWeight = "weight"
+	// ScaleModePrice labels carry the price to pay, in whole rupiah
+	ScaleModePrice = "price"
+)
+
+// ScaleConfig describes the EAN-13 labels the store's scales print:
+// a prefix, the product's SKU in ItemDigits digits, the weight or price in
+// the digits that remain, and the check digit.
+type ScaleConfig struct {
+	Prefixes   []string
+	ItemDigits int
+	Mode       string
+}
+
+// scaleLabel is a decoded scale barcode
+type scaleLabel struct {
+	ItemCode string
+	Value    int64
+}
+
+// Validate rejects layouts that leave no room for the value.
+func (c ScaleConfig) Validate() error {
+	switch c.Mode {
+	case ScaleModeWeight, ScaleModePrice:
+	default:
+		return fmt.Errorf("scale barcode mode must be %s or %s", ScaleModeWeight, ScaleModePrice)
+	}
+	if c.ItemDigits < 1 {
+		return errors.New("scale barcode item digits must be at least 1")
+	}
+	for _, prefix := range c.Prefixes {
+		if prefix == "" || !isDigits(prefix) {
+			return fmt.Errorf("invalid scale barcode prefix %q", prefix)
+		}
+		if len(prefix)+c.ItemDigits > 11 {
+			return fmt.Errorf("scale barcode prefix %q and %d item digits leave no digits for the value", prefix, c.ItemDigits)
+		}
+	}
+	return nil
+}
+
+// decode splits a scale barcode into its item code and value. ok is false
+// when code is not a scale label, so it can be looked up as a plain SKU.
+func (c ScaleConfig) decode(code string) (label scaleLabel, ok bool, err error) {
+	if len(code) != 13 || !isDigits(code) {
+		return scaleLabel{}, false, nil
+	}
+
+	for _, prefix := range c.Prefixes {
+		if !strings.HasPrefix(code, prefix) {
+			continue
+		}
+		if !validEAN13(code) {
+			return scaleLabel{}, false, errors.New("barcode check digit does not match")
+		}
+
+		itemEnd := len(prefix) + c.ItemDigits
+		value, err := strconv.ParseInt(code[itemEnd:12], 10, 64)
+		if err != nil {
+			return scaleLabel{}, false, err
+		}
+		return scaleLabel{ItemCode: code[len(prefix):itemEnd], Value: value}, true, nil
+	}
+	return scaleLabel{}, false, nil
+}
+
+// validEAN13 checks the last digit of a 13-digit code against the others:
+// weights alternate 1 and 3 from the left.
+func validEAN13(code string) bool {
+	sum := 0
+	for i, c := range code[:12] {
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return (10-sum%10)%10 == int(code[12]-'0')
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package product
+
+import "testing"
+
+func TestScaleDecode(t *testing.T) {
+	cfg := ScaleConfig{Prefixes: []string{"20", "29"}, ItemDigits: 5, Mode: ScaleModeWeight}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	tests := []struct {
+		code    string
+		isScale bool
+		want    scaleLabel
+	}{
+		{"2012345012509", true, scaleLabel{ItemCode: "12345", Value: 1250}},
+		{"2900042001509", true, scaleLabel{ItemCode: "00042", Value: 150}},
+		// Not a scale prefix: looked up as a plain SKU
+		{"4800000000019", false, scaleLabel{}},
+		{"12345", false, scaleLabel{}},
+	}
+
+	for _, tt := range tests {
+		got, ok, err := cfg.decode(tt.code)
+		if err != nil {
+			t.Errorf("decode(%s) returned error: %v", tt.code, err)
+			continue
+		}
+		if ok != tt.isScale || got != tt.want {
+			t.Errorf("decode(%s) = %+v, %v, want %+v, %v", tt.code, got, ok, tt.want, tt.isScale)
+		}
+	}
+
+	if _, _, err := cfg.decode("2012345012508"); err == nil {
+		t.Error("decode should reject a bad check digit")
+	}
+}
+
+func TestScaleConfigValidate(t *testing.T) {
+	for _, cfg := range []ScaleConfig{
+		{Prefixes: []string{"20"}, ItemDigits: 5, Mode: "volume"},
+		{Prefixes: []string{"20"}, ItemDigits: 0, Mode: ScaleModeWeight},
+		{Prefixes: []string{"2a"}, ItemDigits: 5, Mode: ScaleModeWeight},
+		{Prefixes: []string{"2000"}, ItemDigits: 8, Mode: ScaleModePrice},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate(%+v) should fail", cfg)
+		}
+	}
+}
@@ -6,51 +6,71 @@ import (
 	"errors"
 	"fmt"
 	"pos-system/internal/db"
-	"strconv"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
 	"strings"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// numericToString converts pgtype.Numeric to string
+// numericToString formats a NUMERIC money column with two decimals
 func numericToString(n pgtype.Numeric) string {
-	if !n.Valid {
-		return "0"
-	}
-	val, err := n.Value()
+	amount, err := money.FromNumeric(n)
 	if err != nil {
-		return "0"
+		return "0.00"
 	}
-	return fmt.Sprintf("%v", val)
+	return amount.String()
 }
 
 type Service struct {
 	queries *db.Queries
 	db      *pgxpool.Pool
+	scale   ScaleConfig
 }
 
-func NewService(queries *db.Queries, db *pgxpool.Pool) *Service {
-	return &Service{queries: queries, db: db}
+func NewService(queries *db.Queries, db *pgxpool.Pool, scale ScaleConfig) *Service {
+	return &Service{queries: queries, db: db, scale: scale}
 }
 
 type CreateProductRequest struct {
 	SKU         string  `json:"sku"`
 	Name        string  `json:"name" binding:"required"`
 	CategoryID  *int32  `json:"category_id"`
-	Price       float64 `json:"price" binding:"required"`
-	CostPrice   *float64 `json:"cost_price"`
+	Price       money.Amount  `json:"price" binding:"required"`
+	CostPrice   *money.Amount `json:"cost_price"`
 	Unit        string  `json:"unit"`
-	InitialStock *int32  `json:"initial_stock"`
+	InitialStock *quantity.Quantity `json:"initial_stock"`
+	// TaxRateID overrides the category's tax rate; TaxExempt disables tax
+	TaxRateID *int32 `json:"tax_rate_id"`
+	TaxExempt bool   `json:"tax_exempt"`
+	// QtyPrecision is how many decimals the product is sold in: 0 for
+	// pieces, up to 3 for goods weighed in kg
+	QtyPrecision int16 `json:"qty_precision"`
 }
 
 type UpdateProductRequest struct {
 	SKU        string  `json:"sku"`
 	Name       string  `json:"name" binding:"required"`
 	CategoryID *int32  `json:"category_id"`
-	Price      float64 `json:"price" binding:"required"`
-	CostPrice  *float64 `json:"cost_price"`
+	Price      money.Amount  `json:"price" binding:"required"`
+	CostPrice  *money.Amount `json:"cost_price"`
 	Unit       string  `json:"unit"`
+	TaxRateID  *int32 `json:"tax_rate_id"`
+	// TaxExempt and QtyPrecision are left as they are when omitted
+	TaxExempt  *bool  `json:"tax_exempt"`
+	QtyPrecision *int16 `json:"qty_precision"`
+}
+
+// ScanResponse is what a scanned barcode adds to the cart. Scale labels
+// carry their own quantity; any other barcode is one unit of the product
+// whose SKU it is. Amount is the line's price before promotions and tax.
+type ScanResponse struct {
+	Product ProductResponse   `json:"product"`
+	Qty     quantity.Quantity `json:"qty"`
+	Amount  string            `json:"amount"`
+	Scale   bool              `json:"scale"`
 }
 
 type ProductResponse struct {
@@ -62,22 +82,40 @@ type ProductResponse struct {
 	Price        string  `json:"price"`
 	CostPrice    *string `json:"cost_price"`
 	Unit         string  `json:"unit"`
+	TaxRateID    *int32  `json:"tax_rate_id"`
+	TaxExempt    bool    `json:"tax_exempt"`
+	QtyPrecision int16   `json:"qty_precision"`
 	CreatedAt    string  `json:"created_at"`
 }
 
+// taxRateID validates an optional tax rate assignment
+func (s *Service) taxRateID(ctx context.Context, id *int32) (pgtype.Int4, error) {
+	if id == nil {
+		return pgtype.Int4{}, nil
+	}
+	if _, err := s.queries.GetTaxRateByID(ctx, *id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.Int4{}, errors.New("tax rate not found")
+		}
+		return pgtype.Int4{}, fmt.Errorf("failed to validate tax rate: %w", err)
+	}
+	return pgtype.Int4{Int32: *id, Valid: true}, nil
+}
+
+// validateQtyPrecision rejects precisions quantities cannot be stored with
+func validateQtyPrecision(precision int16) error {
+	if precision < 0 || precision > quantity.MaxPrecision {
+		return fmt.Errorf("qty precision must be between 0 and %d", quantity.MaxPrecision)
+	}
+	return nil
+}
+
 func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*ProductResponse, error) {
 	var sku *string
 	if req.SKU != "" {
 		sku = &req.SKU
 	}
 
-	price := strconv.FormatFloat(req.Price, 'f', 2, 64)
-	var costPrice *string
-	if req.CostPrice != nil {
-		cp := strconv.FormatFloat(*req.CostPrice, 'f', 2, 64)
-		costPrice = &cp
-	}
-
 	unit := req.Unit
 	if unit == "" {
 		unit = "pcs"
@@ -101,28 +139,35 @@ func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*Produc
 		categoryIDPg = pgtype.Int4{Int32: *req.CategoryID, Valid: true}
 	}
 
-	var pricePg pgtype.Numeric
-	if err := pricePg.Scan(price); err != nil {
-		return nil, err
-	}
+	pricePg := req.Price.Numeric()
 
 	var costPricePg pgtype.Numeric
-	if costPrice != nil {
-		if err := costPricePg.Scan(*costPrice); err != nil {
-			return nil, err
-		}
+	if req.CostPrice != nil {
+		costPricePg = req.CostPrice.Numeric()
 	}
 
 	unitPg := pgtype.Text{String: unit, Valid: true}
 
+	taxRateIDPg, err := s.taxRateID(ctx, req.TaxRateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateQtyPrecision(req.QtyPrecision); err != nil {
+		return nil, err
+	}
+
 	// ALWAYS create product and inventory in a single transaction
 	// Determine initial qty: use initial_stock if provided, otherwise 0
-	var initialQty int32
+	var initialQty quantity.Quantity
 	if req.InitialStock != nil {
 		initialQty = *req.InitialStock
 	} else {
 		initialQty = 0
 	}
+	if !initialQty.Fits(int(req.QtyPrecision)) {
+		return nil, fmt.Errorf("initial stock %s allows at most %d decimal places", initialQty, req.QtyPrecision)
+	}
 
 	// Start transaction
 	tx, err := s.db.Begin(ctx)
@@ -141,6 +186,9 @@ func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*Produc
 		Price:      pricePg,
 		CostPrice:  costPricePg,
 		Unit:       unitPg,
+		TaxRateID:  taxRateIDPg,
+		TaxExempt:  req.TaxExempt,
+		QtyPrecision: req.QtyPrecision,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create product: %w", err)
@@ -150,7 +198,7 @@ func (s *Service) Create(ctx context.Context, req CreateProductRequest) (*Produc
 	productIDPg := pgtype.Int4{Int32: product.ID, Valid: true}
 	_, err = qtx.CreateInventory(ctx, db.CreateInventoryParams{
 		ProductID: productIDPg,
-		Qty:       initialQty,
+		Qty:       initialQty.Numeric(),
 	})
 	if err != nil {
 		// Check if it's a duplicate inventory error (UNIQUE constraint)
@@ -250,19 +298,92 @@ func (s *Service) Search(ctx context.Context, query string) ([]ProductResponse,
 	return result, nil
 }
 
+// Scan looks up a barcode. A price-embedded EAN-13 label from the store's
+// scales is decoded into its product and quantity: the weight it carries, or
+// the quantity its price buys at the product's unit price, rounded to the
+// product's qty_precision.
+func (s *Service) Scan(ctx context.Context, code string) (*ScanResponse, error) {
+	code = strings.TrimSpace(code)
+	label, isScale, err := s.scale.decode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	sku := code
+	if isScale {
+		sku = label.ItemCode
+	}
+	product, err := s.queries.GetProductBySKU(ctx, pgtype.Text{String: sku, Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	price, err := money.FromNumeric(product.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	qty := quantity.New(1)
+	if isScale {
+		switch s.scale.Mode {
+		case ScaleModePrice:
+			if price <= 0 {
+				return nil, fmt.Errorf("product %s has no price to weigh by", product.Name)
+			}
+			// The thousandths of a unit the label's price buys
+			labelPrice := money.New(label.Value)
+			qty = quantity.FromThousandths(labelPrice.MulDiv(1000, price.Cents()).Cents())
+		default:
+			// The label counts steps of the product's precision, e.g. grams
+			qty = quantity.FromThousandths(label.Value)
+			for i := product.QtyPrecision; i < quantity.MaxPrecision; i++ {
+				qty *= 10
+			}
+		}
+		qty = qty.Round(int(product.QtyPrecision))
+		if qty <= 0 {
+			return nil, errors.New("scale label quantity must be greater than zero")
+		}
+	}
+
+	var categoryName *string
+	if product.CategoryName.Valid {
+		categoryName = &product.CategoryName.String
+	}
+	return &ScanResponse{
+		Product: *s.toResponseFromRow(&product, categoryName),
+		Qty:     qty,
+		Amount:  qty.Cost(price).String(),
+		Scale:   isScale,
+	}, nil
+}
+
 func (s *Service) Update(ctx context.Context, id int32, req UpdateProductRequest) (*ProductResponse, error) {
+	current, err := s.queries.GetProductByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	taxExempt := current.TaxExempt
+	if req.TaxExempt != nil {
+		taxExempt = *req.TaxExempt
+	}
+	qtyPrecision := current.QtyPrecision
+	if req.QtyPrecision != nil {
+		qtyPrecision = *req.QtyPrecision
+	}
+
 	var sku *string
 	if req.SKU != "" {
 		sku = &req.SKU
 	}
 
-	price := strconv.FormatFloat(req.Price, 'f', 2, 64)
-	var costPrice *string
-	if req.CostPrice != nil {
-		cp := strconv.FormatFloat(*req.CostPrice, 'f', 2, 64)
-		costPrice = &cp
-	}
-
 	unit := req.Unit
 	if unit == "" {
 		unit = "pcs"
@@ -286,20 +407,24 @@ func (s *Service) Update(ctx context.Context, id int32, req UpdateProductRequest
 		categoryIDPg = pgtype.Int4{Int32: *req.CategoryID, Valid: true}
 	}
 
-	var pricePg pgtype.Numeric
-	if err := pricePg.Scan(price); err != nil {
-		return nil, err
-	}
+	pricePg := req.Price.Numeric()
 
 	var costPricePg pgtype.Numeric
-	if costPrice != nil {
-		if err := costPricePg.Scan(*costPrice); err != nil {
-			return nil, err
-		}
+	if req.CostPrice != nil {
+		costPricePg = req.CostPrice.Numeric()
 	}
 
 	unitPg := pgtype.Text{String: unit, Valid: true}
 
+	taxRateIDPg, err := s.taxRateID(ctx, req.TaxRateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateQtyPrecision(qtyPrecision); err != nil {
+		return nil, err
+	}
+
 	product, err := s.queries.UpdateProduct(ctx, db.UpdateProductParams{
 		ID:         id,
 		Sku:        skuPg,
@@ -308,6 +433,9 @@ func (s *Service) Update(ctx context.Context, id int32, req UpdateProductRequest
 		Price:      pricePg,
 		CostPrice:  costPricePg,
 		Unit:       unitPg,
+		TaxRateID:  taxRateIDPg,
+		TaxExempt:  taxExempt,
+		QtyPrecision: qtyPrecision,
 	})
 	if err != nil {
 		return nil, err
@@ -354,6 +482,11 @@ func (s *Service) toResponseFromProduct(p *db.Product, categoryName *string) *Pr
 		unit = p.Unit.String
 	}
 
+	var taxRateID *int32
+	if p.TaxRateID.Valid {
+		taxRateID = &p.TaxRateID.Int32
+	}
+
 	var createdAt string
 	if p.CreatedAt.Valid {
 		createdAt = p.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
@@ -368,6 +501,9 @@ func (s *Service) toResponseFromProduct(p *db.Product, categoryName *string) *Pr
 		Price:        price,
 		CostPrice:    costPrice,
 		Unit:         unit,
+		TaxRateID:    taxRateID,
+		TaxExempt:    p.TaxExempt,
+		QtyPrecision: p.QtyPrecision,
 		CreatedAt:    createdAt,
 	}
 }
@@ -376,6 +512,8 @@ func (s *Service) toResponseFromRow(p interface{}, categoryName *string) *Produc
 	switch row := p.(type) {
 	case *db.GetProductByIDRow:
 		return s.toResponseFromGetProductByIDRow(row, categoryName)
+	case *db.GetProductBySKURow:
+		return s.toResponseFromGetProductBySKURow(row, categoryName)
 	case *db.ListProductsRow:
 		return s.toResponseFromListProductsRow(row, categoryName)
 	case *db.ListProductsWithStockRow:
@@ -414,6 +552,11 @@ func (s *Service) toResponseFromGetProductByIDRow(p *db.GetProductByIDRow, categ
 		unit = p.Unit.String
 	}
 
+	var taxRateID *int32
+	if p.TaxRateID.Valid {
+		taxRateID = &p.TaxRateID.Int32
+	}
+
 	var createdAt string
 	if p.CreatedAt.Valid {
 		createdAt = p.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
@@ -428,6 +571,62 @@ func (s *Service) toResponseFromGetProductByIDRow(p *db.GetProductByIDRow, categ
 		Price:        price,
 		CostPrice:    costPrice,
 		Unit:         unit,
+		TaxRateID:    taxRateID,
+		TaxExempt:    p.TaxExempt,
+		QtyPrecision: p.QtyPrecision,
+		CreatedAt:    createdAt,
+	}
+}
+
+func (s *Service) toResponseFromGetProductBySKURow(p *db.GetProductBySKURow, categoryName *string) *ProductResponse {
+	var sku *string
+	if p.Sku.Valid {
+		sku = &p.Sku.String
+	}
+
+	var categoryID *int32
+	if p.CategoryID.Valid {
+		categoryID = &p.CategoryID.Int32
+	}
+
+	var price string
+	if p.Price.Valid {
+		price = numericToString(p.Price)
+	}
+
+	var costPrice *string
+	if p.CostPrice.Valid {
+		cp := numericToString(p.CostPrice)
+		costPrice = &cp
+	}
+
+	var unit string
+	if p.Unit.Valid {
+		unit = p.Unit.String
+	}
+
+	var taxRateID *int32
+	if p.TaxRateID.Valid {
+		taxRateID = &p.TaxRateID.Int32
+	}
+
+	var createdAt string
+	if p.CreatedAt.Valid {
+		createdAt = p.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return &ProductResponse{
+		ID:           p.ID,
+		SKU:          sku,
+		Name:         p.Name,
+		CategoryID:   categoryID,
+		CategoryName: categoryName,
+		Price:        price,
+		CostPrice:    costPrice,
+		Unit:         unit,
+		TaxRateID:    taxRateID,
+		TaxExempt:    p.TaxExempt,
+		QtyPrecision: p.QtyPrecision,
 		CreatedAt:    createdAt,
 	}
 }
@@ -459,6 +658,11 @@ func (s *Service) toResponseFromListProductsWithStockRow(p *db.ListProductsWithS
 		unit = p.Unit.String
 	}
 
+	var taxRateID *int32
+	if p.TaxRateID.Valid {
+		taxRateID = &p.TaxRateID.Int32
+	}
+
 	var createdAt string
 	if p.CreatedAt.Valid {
 		createdAt = p.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
@@ -473,6 +677,9 @@ func (s *Service) toResponseFromListProductsWithStockRow(p *db.ListProductsWithS
 		Price:        price,
 		CostPrice:    costPrice,
 		Unit:         unit,
+		TaxRateID:    taxRateID,
+		TaxExempt:    p.TaxExempt,
+		QtyPrecision: p.QtyPrecision,
 		CreatedAt:    createdAt,
 	}
 }
@@ -504,6 +711,11 @@ func (s *Service) toResponseFromListProductsRow(p *db.ListProductsRow, categoryN
 		unit = p.Unit.String
 	}
 
+	var taxRateID *int32
+	if p.TaxRateID.Valid {
+		taxRateID = &p.TaxRateID.Int32
+	}
+
 	var createdAt string
 	if p.CreatedAt.Valid {
 		createdAt = p.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
@@ -518,6 +730,9 @@ func (s *Service) toResponseFromListProductsRow(p *db.ListProductsRow, categoryN
 		Price:        price,
 		CostPrice:    costPrice,
 		Unit:         unit,
+		TaxRateID:    taxRateID,
+		TaxExempt:    p.TaxExempt,
+		QtyPrecision: p.QtyPrecision,
 		CreatedAt:    createdAt,
 	}
 }
@@ -549,6 +764,11 @@ func (s *Service) toResponseFromSearchProductsRow(p *db.SearchProductsRow, categ
 		unit = p.Unit.String
 	}
 
+	var taxRateID *int32
+	if p.TaxRateID.Valid {
+		taxRateID = &p.TaxRateID.Int32
+	}
+
 	var createdAt string
 	if p.CreatedAt.Valid {
 		createdAt = p.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
@@ -563,6 +783,9 @@ func (s *Service) toResponseFromSearchProductsRow(p *db.SearchProductsRow, categ
 		Price:        price,
 		CostPrice:    costPrice,
 		Unit:         unit,
+		TaxRateID:    taxRateID,
+		TaxExempt:    p.TaxExempt,
+		QtyPrecision: p.QtyPrecision,
 		CreatedAt:    createdAt,
 	}
 }
@@ -0,0 +1,189 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// UnitRequest defines a packaging unit of a product: Factor base units in
+// one, e.g. a "dus" of 24 bottles. Price is what one unit sells for; left
+// out, the unit sells for Factor times the product's price.
+type UnitRequest struct {
+	Name   string            `json:"name" binding:"required"`
+	Factor quantity.Quantity `json:"factor" binding:"required"`
+	Price  *money.Amount     `json:"price"`
+}
+
+type UnitResponse struct {
+	ID        int32             `json:"id"`
+	ProductID int32             `json:"product_id"`
+	Name      string            `json:"name"`
+	Factor    quantity.Quantity `json:"factor"`
+	// Price is the unit's own price, null when it follows the product's;
+	// UnitPrice is what a sale charges for one unit either way
+	Price     *string `json:"price"`
+	UnitPrice string  `json:"unit_price"`
+	CreatedAt string  `json:"created_at"`
+}
+
+func validateUnit(req *UnitRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("unit name is required")
+	}
+	if req.Factor <= 0 {
+		return errors.New("unit factor must be greater than zero")
+	}
+	if req.Price != nil && *req.Price < 0 {
+		return errors.New("unit price cannot be negative")
+	}
+	return nil
+}
+
+func isDuplicateUnit(err error) bool {
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "duplicate key") && strings.Contains(errMsg, "product_units_product_id_name_key")
+}
+
+func optPrice(price *money.Amount) pgtype.Numeric {
+	if price == nil {
+		return pgtype.Numeric{}
+	}
+	return price.Numeric()
+}
+
+// unitProduct fetches the product a unit belongs to.
+func (s *Service) unitProduct(ctx context.Context, productID int32) (db.GetProductByIDRow, error) {
+	product, err := s.queries.GetProductByID(ctx, productID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return product, errors.New("product not found")
+		}
+		return product, err
+	}
+	return product, nil
+}
+
+func unitResponse(u db.ProductUnit, basePrice pgtype.Numeric) UnitResponse {
+	factor, _ := quantity.FromNumeric(u.Factor)
+
+	var price *string
+	unitPrice := numericToString(u.Price)
+	if u.Price.Valid {
+		price = &unitPrice
+	} else {
+		base, _ := money.FromNumeric(basePrice)
+		unitPrice = factor.Cost(base).String()
+	}
+
+	var createdAt string
+	if u.CreatedAt.Valid {
+		createdAt = u.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return UnitResponse{
+		ID:        u.ID,
+		ProductID: u.ProductID,
+		Name:      u.Name,
+		Factor:    factor,
+		Price:     price,
+		UnitPrice: unitPrice,
+		CreatedAt: createdAt,
+	}
+}
+
+// ListUnits returns a product's packaging units, smallest first.
+func (s *Service) ListUnits(ctx context.Context, productID int32) ([]UnitResponse, error) {
+	product, err := s.unitProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	units, err := s.queries.ListProductUnits(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]UnitResponse, len(units))
+	for i, u := range units {
+		result[i] = unitResponse(u, product.Price)
+	}
+	return result, nil
+}
+
+func (s *Service) CreateUnit(ctx context.Context, productID int32, req UnitRequest) (*UnitResponse, error) {
+	if err := validateUnit(&req); err != nil {
+		return nil, err
+	}
+	product, err := s.unitProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	unit, err := s.queries.CreateProductUnit(ctx, db.CreateProductUnitParams{
+		ProductID: productID,
+		Name:      req.Name,
+		Factor:    req.Factor.Numeric(),
+		Price:     optPrice(req.Price),
+	})
+	if err != nil {
+		if isDuplicateUnit(err) {
+			return nil, errors.New("unit name already exists for this product")
+		}
+		return nil, fmt.Errorf("failed to create unit: %w", err)
+	}
+
+	result := unitResponse(unit, product.Price)
+	return &result, nil
+}
+
+// UpdateUnit changes a unit. Sales already made keep the name and factor
+// they were sold with.
+func (s *Service) UpdateUnit(ctx context.Context, productID, unitID int32, req UnitRequest) (*UnitResponse, error) {
+	if err := validateUnit(&req); err != nil {
+		return nil, err
+	}
+	product, err := s.unitProduct(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	unit, err := s.queries.UpdateProductUnit(ctx, db.UpdateProductUnitParams{
+		ID:        unitID,
+		ProductID: productID,
+		Name:      req.Name,
+		Factor:    req.Factor.Numeric(),
+		Price:     optPrice(req.Price),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("unit not found")
+		}
+		if isDuplicateUnit(err) {
+			return nil, errors.New("unit name already exists for this product")
+		}
+		return nil, fmt.Errorf("failed to update unit: %w", err)
+	}
+
+	result := unitResponse(unit, product.Price)
+	return &result, nil
+}
+
+func (s *Service) DeleteUnit(ctx context.Context, productID, unitID int32) error {
+	deleted, err := s.queries.DeleteProductUnit(ctx, db.DeleteProductUnitParams{ID: unitID, ProductID: productID})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return errors.New("unit not found")
+	}
+	return nil
+}
@@ -0,0 +1,323 @@
+package promotion
+
+import (
+	"context"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	TypeCategoryPercent = "category_percent"
+	TypeProductAmount   = "product_amount"
+	TypeBuyXGetY        = "buy_x_get_y"
+	TypeBundlePrice     = "bundle_price"
+	TypeMinSpend        = "min_spend"
+)
+
+// Rule is a promotion ready to be evaluated against a cart. Percent is in
+// percent (money.New(10) is 10%), matching how tax rates are stored.
+type Rule struct {
+	ID         int32
+	Name       string
+	Type       string
+	CategoryID int32
+	ProductID  int32
+	Percent    money.Amount
+	Amount     money.Amount
+	BuyQty     int64
+	GetQty     int64
+	MinSpend   money.Amount
+}
+
+// Line is one cart line as the engine sees it. Amount is what is left to
+// discount after any manual discount, before tax. Buy-x-get-y and bundle
+// rules count the whole units of a product across all its lines, so the
+// same item scanned twice counts the same as one line of qty 2.
+type Line struct {
+	ProductID  int32
+	CategoryID int32
+	Qty        quantity.Quantity
+	UnitPrice  money.Amount
+	Amount     money.Amount
+}
+
+// Discount is the part of a promotion's value that lands on one line.
+type Discount struct {
+	Line        int
+	PromotionID int32
+	Name        string
+	Amount      money.Amount
+}
+
+// RuleFromDB converts a stored promotion; NULL columns become zero values.
+func RuleFromDB(p db.Promotion) (Rule, error) {
+	rule := Rule{
+		ID:         p.ID,
+		Name:       p.Name,
+		Type:       p.Type,
+		CategoryID: p.CategoryID.Int32,
+		ProductID:  p.ProductID.Int32,
+		BuyQty:     int64(p.BuyQty.Int32),
+		GetQty:     int64(p.GetQty.Int32),
+	}
+
+	for _, f := range []struct {
+		dst *money.Amount
+		src pgtype.Numeric
+	}{
+		{&rule.Percent, p.Percent},
+		{&rule.Amount, p.Amount},
+		{&rule.MinSpend, p.MinSpend},
+	} {
+		if !f.src.Valid {
+			continue
+		}
+		amount, err := money.FromNumeric(f.src)
+		if err != nil {
+			return Rule{}, err
+		}
+		*f.dst = amount
+	}
+
+	return rule, nil
+}
+
+// ActiveRules loads the promotions running at the given time.
+func ActiveRules(ctx context.Context, q *db.Queries, at time.Time) ([]Rule, error) {
+	promotions, err := q.ListActivePromotions(ctx, pgtype.Timestamptz{Time: at, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(promotions))
+	for _, p := range promotions {
+		rule, err := RuleFromDB(p)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// lineDiscount is what a category or product rule takes off a single line,
+// uncapped.
+func (r Rule) lineDiscount(line Line) money.Amount {
+	switch r.Type {
+	case TypeCategoryPercent:
+		if line.CategoryID != 0 && line.CategoryID == r.CategoryID {
+			return line.Amount.MulDiv(r.Percent.Cents(), money.New(100).Cents())
+		}
+	case TypeProductAmount:
+		if line.ProductID == r.ProductID {
+			return line.Qty.Cost(r.Amount)
+		}
+	}
+	return 0
+}
+
+// productDiscount is what a buy-x-get-y or bundle rule takes off the lines
+// of one product together, uncapped. Free and bundled units are valued at
+// the lowest unit price among the lines.
+func (r Rule) productDiscount(lines []Line) money.Amount {
+	if len(lines) == 0 || lines[0].ProductID != r.ProductID || r.BuyQty <= 0 {
+		return 0
+	}
+
+	var qty quantity.Quantity
+	unitPrice := lines[0].UnitPrice
+	for _, line := range lines {
+		qty += line.Qty
+		if line.UnitPrice < unitPrice {
+			unitPrice = line.UnitPrice
+		}
+	}
+	units := qty.Whole()
+
+	switch r.Type {
+	case TypeBuyXGetY:
+		if r.GetQty > 0 {
+			free := units / (r.BuyQty + r.GetQty) * r.GetQty
+			return unitPrice.Mul(free)
+		}
+	case TypeBundlePrice:
+		saving := unitPrice.Mul(r.BuyQty) - r.Amount
+		if saving > 0 {
+			return saving.Mul(units / r.BuyQty)
+		}
+	}
+	return 0
+}
+
+// cartDiscount is what a minimum-spend rule takes off a cart totalling total.
+func (r Rule) cartDiscount(total money.Amount) money.Amount {
+	if r.Type != TypeMinSpend || total <= 0 || total < r.MinSpend {
+		return 0
+	}
+	discount := r.Amount
+	if r.Percent > 0 {
+		discount = total.MulDiv(r.Percent.Cents(), money.New(100).Cents())
+	}
+	if discount > total {
+		discount = total
+	}
+	return discount
+}
+
+// Apply picks the promotions that give the cart the largest discount. Each
+// product gets at most one line promotion: either the best category or
+// product rule for each of its lines, or one buy-x-get-y or bundle rule over
+// all its lines, whichever takes off more. The cart gets at most one
+// minimum-spend promotion, evaluated on what the line promotions leave.
+// Because line promotions can drop a cart below a spend threshold, the cart
+// promotion alone is also tried and the larger total wins. Ties go to the
+// promotion with the lower ID, so rules must be in ID order as ActiveRules
+// returns them.
+func Apply(rules []Rule, lines []Line) []Discount {
+	withLines := applyLineRules(rules, lines)
+	withLines = append(withLines, applyCartRule(rules, lines, withLines)...)
+
+	cartOnly := applyCartRule(rules, lines, nil)
+	if total(cartOnly) > total(withLines) {
+		return cartOnly
+	}
+	return withLines
+}
+
+func applyLineRules(rules []Rule, lines []Line) []Discount {
+	var discounts []Discount
+	for _, group := range productGroups(lines) {
+		best := bestPerLine(rules, lines, group)
+		for _, rule := range rules {
+			if d := productRuleDiscounts(rule, lines, group); total(d) > total(best) {
+				best = d
+			}
+		}
+		discounts = append(discounts, best...)
+	}
+
+	sort.SliceStable(discounts, func(i, j int) bool { return discounts[i].Line < discounts[j].Line })
+	return discounts
+}
+
+// productGroups returns the indexes of the lines of each product, in the
+// order the products first appear.
+func productGroups(lines []Line) [][]int {
+	var groups [][]int
+	byProduct := make(map[int32]int)
+	for i, line := range lines {
+		g, ok := byProduct[line.ProductID]
+		if !ok {
+			g = len(groups)
+			byProduct[line.ProductID] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// bestPerLine gives each of the lines its best category or product rule.
+func bestPerLine(rules []Rule, lines []Line, group []int) []Discount {
+	var discounts []Discount
+	for _, i := range group {
+		line := lines[i]
+		best := Discount{Line: i}
+		for _, rule := range rules {
+			amount := rule.lineDiscount(line)
+			if amount > line.Amount {
+				amount = line.Amount
+			}
+			if amount > best.Amount {
+				best.PromotionID = rule.ID
+				best.Name = rule.Name
+				best.Amount = amount
+			}
+		}
+		if best.Amount > 0 {
+			discounts = append(discounts, best)
+		}
+	}
+	return discounts
+}
+
+// productRuleDiscounts applies a buy-x-get-y or bundle rule to the lines of
+// one product, spreading its discount over them in proportion to their
+// amounts.
+func productRuleDiscounts(rule Rule, lines []Line, group []int) []Discount {
+	groupLines := make([]Line, len(group))
+	weights := make([]money.Amount, len(group))
+	var groupTotal money.Amount
+	for k, i := range group {
+		groupLines[k] = lines[i]
+		weights[k] = lines[i].Amount
+		groupTotal += lines[i].Amount
+	}
+
+	amount := rule.productDiscount(groupLines)
+	if amount > groupTotal {
+		amount = groupTotal
+	}
+	if amount <= 0 {
+		return nil
+	}
+
+	var discounts []Discount
+	for k, share := range amount.Allocate(weights) {
+		if share > 0 {
+			discounts = append(discounts, Discount{Line: group[k], PromotionID: rule.ID, Name: rule.Name, Amount: share})
+		}
+	}
+	return discounts
+}
+
+// applyCartRule applies the best minimum-spend rule to what remains of each
+// line after the given discounts, spreading it over the lines in proportion
+// to their remaining amounts.
+func applyCartRule(rules []Rule, lines []Line, applied []Discount) []Discount {
+	remaining := make([]money.Amount, len(lines))
+	for i, line := range lines {
+		remaining[i] = line.Amount
+	}
+	for _, d := range applied {
+		remaining[d.Line] -= d.Amount
+	}
+
+	var cartTotal money.Amount
+	for _, amount := range remaining {
+		cartTotal += amount
+	}
+
+	var best *Rule
+	var bestAmount money.Amount
+	for i := range rules {
+		if amount := rules[i].cartDiscount(cartTotal); amount > bestAmount {
+			best = &rules[i]
+			bestAmount = amount
+		}
+	}
+	if best == nil {
+		return nil
+	}
+
+	var discounts []Discount
+	for i, share := range bestAmount.Allocate(remaining) {
+		if share > 0 {
+			discounts = append(discounts, Discount{Line: i, PromotionID: best.ID, Name: best.Name, Amount: share})
+		}
+	}
+	return discounts
+}
+
+func total(discounts []Discount) money.Amount {
+	var sum money.Amount
+	for _, d := range discounts {
+		sum += d.Amount
+	}
+	return sum
+}
@@ -0,0 +1,289 @@
+package promotion
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	coffee = 1
+	tea    = 2
+	rice   = 3
+	drinks = 10
+)
+
+// line is a cart line of qty units at price each, with no manual discount.
+func line(productID, categoryID int32, qty string, price int64) Line {
+	q := quantity.MustParse(qty)
+	return Line{
+		ProductID:  productID,
+		CategoryID: categoryID,
+		Qty:        q,
+		UnitPrice:  money.New(price),
+		Amount:     q.Cost(money.New(price)),
+	}
+}
+
+func TestApply(t *testing.T) {
+	drinks10 := Rule{ID: 1, Name: "Drinks 10%", Type: TypeCategoryPercent, CategoryID: drinks, Percent: money.New(10)}
+	coffee1000 := Rule{ID: 2, Name: "Coffee -1000", Type: TypeProductAmount, ProductID: coffee, Amount: money.New(1000)}
+	coffee2get1 := Rule{ID: 3, Name: "Coffee 2+1", Type: TypeBuyXGetY, ProductID: coffee, BuyQty: 2, GetQty: 1}
+	tea3for20 := Rule{ID: 4, Name: "Tea 3 for 20000", Type: TypeBundlePrice, ProductID: tea, BuyQty: 3, Amount: money.New(20000)}
+	spend100k := Rule{ID: 5, Name: "Spend 100k get 20%", Type: TypeMinSpend, MinSpend: money.New(100000), Percent: money.New(20)}
+	spend50k := Rule{ID: 6, Name: "Spend 50k get 5000", Type: TypeMinSpend, MinSpend: money.New(50000), Amount: money.New(5000)}
+
+	tests := []struct {
+		name  string
+		rules []Rule
+		lines []Line
+		want  []Discount
+	}{
+		{
+			name:  "no rules",
+			lines: []Line{line(coffee, drinks, "1", 20000)},
+		},
+		{
+			name:  "category percent",
+			rules: []Rule{drinks10},
+			lines: []Line{line(coffee, drinks, "2", 20000), line(rice, 0, "1", 30000)},
+			want:  []Discount{{Line: 0, PromotionID: 1, Name: "Drinks 10%", Amount: money.New(4000)}},
+		},
+		{
+			name:  "product amount per unit",
+			rules: []Rule{coffee1000},
+			lines: []Line{line(coffee, drinks, "3", 20000)},
+			want:  []Discount{{Line: 0, PromotionID: 2, Name: "Coffee -1000", Amount: money.New(3000)}},
+		},
+		{
+			name:  "product amount capped at the line",
+			rules: []Rule{{ID: 2, Name: "Coffee -1000", Type: TypeProductAmount, ProductID: coffee, Amount: money.New(1000)}},
+			lines: []Line{line(coffee, drinks, "2", 800)},
+			want:  []Discount{{Line: 0, PromotionID: 2, Name: "Coffee -1000", Amount: money.New(1600)}},
+		},
+		{
+			name:  "buy 2 get 1",
+			rules: []Rule{coffee2get1},
+			lines: []Line{line(coffee, drinks, "5", 20000)},
+			want:  []Discount{{Line: 0, PromotionID: 3, Name: "Coffee 2+1", Amount: money.New(20000)}},
+		},
+		{
+			name:  "buy 2 get 1 not reached",
+			rules: []Rule{coffee2get1},
+			lines: []Line{line(coffee, drinks, "2", 20000)},
+		},
+		{
+			name:  "buy 2 get 1 over the same item scanned three times",
+			rules: []Rule{coffee2get1},
+			lines: []Line{line(coffee, drinks, "1", 20000), line(rice, 0, "1", 30000), line(coffee, drinks, "1", 20000), line(coffee, drinks, "1", 20000)},
+			want: []Discount{
+				{Line: 0, PromotionID: 3, Name: "Coffee 2+1", Amount: money.MustParse("6666.67")},
+				{Line: 2, PromotionID: 3, Name: "Coffee 2+1", Amount: money.MustParse("6666.67")},
+				{Line: 3, PromotionID: 3, Name: "Coffee 2+1", Amount: money.MustParse("6666.66")},
+			},
+		},
+		{
+			name:  "buy 2 get 1 counts whole units across fractional lines",
+			rules: []Rule{coffee2get1},
+			lines: []Line{line(coffee, drinks, "1.5", 20000), line(coffee, drinks, "1.5", 20000)},
+			want: []Discount{
+				{Line: 0, PromotionID: 3, Name: "Coffee 2+1", Amount: money.New(10000)},
+				{Line: 1, PromotionID: 3, Name: "Coffee 2+1", Amount: money.New(10000)},
+			},
+		},
+		{
+			name:  "buy 2 get 1 gives the cheapest unit free",
+			rules: []Rule{coffee2get1},
+			lines: []Line{line(coffee, drinks, "2", 20000), line(coffee, drinks, "1", 14000)},
+			want: []Discount{
+				{Line: 0, PromotionID: 3, Name: "Coffee 2+1", Amount: money.MustParse("10370.37")},
+				{Line: 1, PromotionID: 3, Name: "Coffee 2+1", Amount: money.MustParse("3629.63")},
+			},
+		},
+		{
+			name:  "bundle price",
+			rules: []Rule{tea3for20},
+			lines: []Line{line(tea, drinks, "7", 8000)},
+			// Two bundles of 3 save 4000 each; the seventh tea is full price
+			want: []Discount{{Line: 0, PromotionID: 4, Name: "Tea 3 for 20000", Amount: money.New(8000)}},
+		},
+		{
+			name:  "bundle price over separate lines",
+			rules: []Rule{tea3for20},
+			lines: []Line{line(tea, drinks, "2", 8000), line(tea, drinks, "1", 8000)},
+			want: []Discount{
+				{Line: 0, PromotionID: 4, Name: "Tea 3 for 20000", Amount: money.MustParse("2666.67")},
+				{Line: 1, PromotionID: 4, Name: "Tea 3 for 20000", Amount: money.MustParse("1333.33")},
+			},
+		},
+		{
+			name:  "bundle price above the regular price is ignored",
+			rules: []Rule{{ID: 4, Name: "Tea 3 for 30000", Type: TypeBundlePrice, ProductID: tea, BuyQty: 3, Amount: money.New(30000)}},
+			lines: []Line{line(tea, drinks, "3", 8000)},
+		},
+		{
+			name:  "best line rule wins",
+			rules: []Rule{drinks10, coffee1000},
+			lines: []Line{line(coffee, drinks, "1", 20000), line(coffee, drinks, "1", 5000)},
+			want: []Discount{
+				{Line: 0, PromotionID: 1, Name: "Drinks 10%", Amount: money.New(2000)},
+				{Line: 1, PromotionID: 2, Name: "Coffee -1000", Amount: money.New(1000)},
+			},
+		},
+		{
+			name: "ties go to the lower ID",
+			rules: []Rule{
+				drinks10,
+				{ID: 7, Name: "Coffee -2000", Type: TypeProductAmount, ProductID: coffee, Amount: money.New(2000)},
+			},
+			lines: []Line{line(coffee, drinks, "1", 20000)},
+			want:  []Discount{{Line: 0, PromotionID: 1, Name: "Drinks 10%", Amount: money.New(2000)}},
+		},
+		{
+			name:  "buy 2 get 1 beats per-line rules when it saves more",
+			rules: []Rule{coffee1000, coffee2get1},
+			lines: []Line{line(coffee, drinks, "1", 20000), line(coffee, drinks, "2", 20000)},
+			want: []Discount{
+				{Line: 0, PromotionID: 3, Name: "Coffee 2+1", Amount: money.MustParse("6666.67")},
+				{Line: 1, PromotionID: 3, Name: "Coffee 2+1", Amount: money.MustParse("13333.33")},
+			},
+		},
+		{
+			name:  "per-line rules beat buy 2 get 1 when they save more",
+			rules: []Rule{coffee1000, coffee2get1},
+			lines: []Line{line(coffee, drinks, "5", 1500)},
+			want:  []Discount{{Line: 0, PromotionID: 2, Name: "Coffee -1000", Amount: money.New(5000)}},
+		},
+		{
+			name:  "min spend percent",
+			rules: []Rule{spend100k},
+			lines: []Line{line(rice, 0, "2", 30000), line(coffee, drinks, "2", 20000)},
+			want: []Discount{
+				{Line: 0, PromotionID: 5, Name: "Spend 100k get 20%", Amount: money.New(12000)},
+				{Line: 1, PromotionID: 5, Name: "Spend 100k get 20%", Amount: money.New(8000)},
+			},
+		},
+		{
+			name:  "min spend not reached",
+			rules: []Rule{spend100k},
+			lines: []Line{line(rice, 0, "3", 30000)},
+		},
+		{
+			name:  "best min spend rule",
+			rules: []Rule{spend50k, spend100k},
+			lines: []Line{line(rice, 0, "2", 30000)},
+			want:  []Discount{{Line: 0, PromotionID: 6, Name: "Spend 50k get 5000", Amount: money.New(5000)}},
+		},
+		{
+			name:  "line and cart promotions together",
+			rules: []Rule{coffee1000, spend50k},
+			lines: []Line{line(rice, 0, "2", 30000), line(coffee, drinks, "1", 20000)},
+			// 79000 left after the coffee discount still reaches 50000
+			want: []Discount{
+				{Line: 1, PromotionID: 2, Name: "Coffee -1000", Amount: money.New(1000)},
+				{Line: 0, PromotionID: 6, Name: "Spend 50k get 5000", Amount: money.MustParse("3797.47")},
+				{Line: 1, PromotionID: 6, Name: "Spend 50k get 5000", Amount: money.MustParse("1202.53")},
+			},
+		},
+		{
+			name:  "cart promotion alone when line promotions drop the cart below its threshold",
+			rules: []Rule{coffee1000, spend100k},
+			lines: []Line{line(rice, 0, "2", 30000), line(coffee, drinks, "2", 20000)},
+			// With the coffee discount the cart is 98000 and gets no 20%
+			want: []Discount{
+				{Line: 0, PromotionID: 5, Name: "Spend 100k get 20%", Amount: money.New(12000)},
+				{Line: 1, PromotionID: 5, Name: "Spend 100k get 20%", Amount: money.New(8000)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Apply(tt.rules, tt.lines)
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestActiveRulesWindow checks which promotions ActiveRules returns around
+// their validity windows. It runs when TEST_DATABASE_URL points at a
+// migrated Postgres database.
+func TestActiveRulesWindow(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Close()
+	queries := db.New(pool)
+
+	start := time.Date(2090, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	at := func(t time.Time) pgtype.Timestamptz { return pgtype.Timestamptz{Time: t, Valid: true} }
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	create := func(name string, endsAt pgtype.Timestamptz, active bool) int32 {
+		p, err := queries.CreatePromotion(ctx, db.CreatePromotionParams{
+			Name:     name + " " + suffix,
+			Type:     TypeMinSpend,
+			Amount:   money.New(1000).Numeric(),
+			MinSpend: money.New(10000).Numeric(),
+			StartsAt: at(start),
+			EndsAt:   endsAt,
+			Active:   active,
+		})
+		if err != nil {
+			t.Fatalf("CreatePromotion: %v", err)
+		}
+		t.Cleanup(func() { pool.Exec(ctx, "DELETE FROM promotions WHERE id = $1", p.ID) })
+		return p.ID
+	}
+	windowed := create("windowed", at(end), true)
+	openEnded := create("open-ended", pgtype.Timestamptz{}, true)
+	inactive := create("inactive", pgtype.Timestamptz{}, false)
+
+	tests := []struct {
+		at   time.Time
+		want []int32
+	}{
+		{start.Add(-time.Second), nil},
+		{start, []int32{windowed, openEnded}},
+		{end.Add(-time.Second), []int32{windowed, openEnded}},
+		{end, []int32{openEnded}},
+	}
+	for _, tt := range tests {
+		rules, err := ActiveRules(ctx, queries, tt.at)
+		if err != nil {
+			t.Fatalf("ActiveRules: %v", err)
+		}
+		var got []int32
+		for _, r := range rules {
+			switch r.ID {
+			case windowed, openEnded, inactive:
+				got = append(got, r.ID)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ActiveRules(%s) = %v, want %v", tt.at.Format(time.RFC3339), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type Service struct {
+	queries *db.Queries
+}
+
+func NewService(queries *db.Queries) *Service {
+	return &Service{queries: queries}
+}
+
+// PromotionRequest describes a promotion. Which of the optional fields are
+// required depends on Type:
+//
+//	category_percent  category_id, percent
+//	product_amount    product_id, amount (off each unit)
+//	buy_x_get_y       product_id, buy_qty, get_qty
+//	bundle_price      product_id, buy_qty, amount (price of buy_qty units)
+//	min_spend         min_spend and exactly one of percent or amount
+//
+// Active defaults to true.
+type PromotionRequest struct {
+	Name       string        `json:"name" binding:"required"`
+	Type       string        `json:"type" binding:"required"`
+	CategoryID *int32        `json:"category_id"`
+	ProductID  *int32        `json:"product_id"`
+	Percent    *money.Amount `json:"percent"`
+	Amount     *money.Amount `json:"amount"`
+	BuyQty     *int32        `json:"buy_qty"`
+	GetQty     *int32        `json:"get_qty"`
+	MinSpend   *money.Amount `json:"min_spend"`
+	StartsAt   time.Time     `json:"starts_at" binding:"required"`
+	EndsAt     *time.Time    `json:"ends_at"`
+	Active     *bool         `json:"active"`
+}
+
+type PromotionResponse struct {
+	ID         int32   `json:"id"`
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	CategoryID *int32  `json:"category_id,omitempty"`
+	ProductID  *int32  `json:"product_id,omitempty"`
+	Percent    *string `json:"percent,omitempty"`
+	Amount     *string `json:"amount,omitempty"`
+	BuyQty     *int32  `json:"buy_qty,omitempty"`
+	GetQty     *int32  `json:"get_qty,omitempty"`
+	MinSpend   *string `json:"min_spend,omitempty"`
+	StartsAt   string  `json:"starts_at"`
+	EndsAt     *string `json:"ends_at,omitempty"`
+	Active     bool    `json:"active"`
+	CreatedAt  string  `json:"created_at"`
+}
+
+// validate checks the request against its type and clears any field the
+// type does not use, so stored promotions never carry stray values.
+func validate(req *PromotionRequest) error {
+	positive := func(a *money.Amount) bool { return a != nil && *a > 0 }
+	atLeast := func(n *int32, min int32) bool { return n != nil && *n >= min }
+
+	switch req.Type {
+	case TypeCategoryPercent:
+		if req.CategoryID == nil {
+			return errors.New("category_percent promotion requires category_id")
+		}
+		if !positive(req.Percent) || *req.Percent > money.New(100) {
+			return errors.New("promotion percent must be between 0 and 100")
+		}
+		req.ProductID, req.Amount, req.BuyQty, req.GetQty, req.MinSpend = nil, nil, nil, nil, nil
+	case TypeProductAmount:
+		if req.ProductID == nil {
+			return errors.New("product_amount promotion requires product_id")
+		}
+		if !positive(req.Amount) {
+			return errors.New("promotion amount must be greater than zero")
+		}
+		req.CategoryID, req.Percent, req.BuyQty, req.GetQty, req.MinSpend = nil, nil, nil, nil, nil
+	case TypeBuyXGetY:
+		if req.ProductID == nil {
+			return errors.New("buy_x_get_y promotion requires product_id")
+		}
+		if !atLeast(req.BuyQty, 1) || !atLeast(req.GetQty, 1) {
+			return errors.New("buy_x_get_y promotion requires buy_qty and get_qty of at least 1")
+		}
+		req.CategoryID, req.Percent, req.Amount, req.MinSpend = nil, nil, nil, nil
+	case TypeBundlePrice:
+		if req.ProductID == nil {
+			return errors.New("bundle_price promotion requires product_id")
+		}
+		if !atLeast(req.BuyQty, 2) {
+			return errors.New("bundle_price promotion requires buy_qty of at least 2")
+		}
+		if !positive(req.Amount) {
+			return errors.New("promotion amount must be greater than zero")
+		}
+		req.CategoryID, req.Percent, req.GetQty, req.MinSpend = nil, nil, nil, nil
+	case TypeMinSpend:
+		if !positive(req.MinSpend) {
+			return errors.New("min_spend promotion requires min_spend greater than zero")
+		}
+		if (req.Percent == nil) == (req.Amount == nil) {
+			return errors.New("min_spend promotion requires exactly one of percent or amount")
+		}
+		if req.Percent != nil && (!positive(req.Percent) || *req.Percent > money.New(100)) {
+			return errors.New("promotion percent must be between 0 and 100")
+		}
+		if req.Amount != nil && !positive(req.Amount) {
+			return errors.New("promotion amount must be greater than zero")
+		}
+		req.CategoryID, req.ProductID, req.BuyQty, req.GetQty = nil, nil, nil, nil
+	default:
+		return errors.New("invalid promotion type")
+	}
+
+	if req.EndsAt != nil && !req.EndsAt.After(req.StartsAt) {
+		return errors.New("promotion ends_at must be after starts_at")
+	}
+	return nil
+}
+
+func int4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func numeric(v *money.Amount) pgtype.Numeric {
+	if v == nil {
+		return pgtype.Numeric{}
+	}
+	return v.Numeric()
+}
+
+func timestamptz(v *time.Time) pgtype.Timestamptz {
+	if v == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *v, Valid: true}
+}
+
+func active(v *bool) bool {
+	return v == nil || *v
+}
+
+func toResponse(p db.Promotion) PromotionResponse {
+	optInt := func(v pgtype.Int4) *int32 {
+		if !v.Valid {
+			return nil
+		}
+		return &v.Int32
+	}
+	optAmount := func(v pgtype.Numeric) *string {
+		if !v.Valid {
+			return nil
+		}
+		s := "0.00"
+		if amount, err := money.FromNumeric(v); err == nil {
+			s = amount.String()
+		}
+		return &s
+	}
+
+	resp := PromotionResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		Type:       p.Type,
+		CategoryID: optInt(p.CategoryID),
+		ProductID:  optInt(p.ProductID),
+		Percent:    optAmount(p.Percent),
+		Amount:     optAmount(p.Amount),
+		BuyQty:     optInt(p.BuyQty),
+		GetQty:     optInt(p.GetQty),
+		MinSpend:   optAmount(p.MinSpend),
+		Active:     p.Active,
+	}
+	if p.StartsAt.Valid {
+		resp.StartsAt = p.StartsAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if p.EndsAt.Valid {
+		endsAt := p.EndsAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		resp.EndsAt = &endsAt
+	}
+	if p.CreatedAt.Valid {
+		resp.CreatedAt = p.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+// checkReferences turns a missing category or product into a readable error
+// instead of a foreign key violation.
+func (s *Service) checkReferences(ctx context.Context, req PromotionRequest) error {
+	if req.CategoryID != nil {
+		if _, err := s.queries.GetCategoryByID(ctx, *req.CategoryID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errors.New("category not found")
+			}
+			return err
+		}
+	}
+	if req.ProductID != nil {
+		if _, err := s.queries.GetProductByID(ctx, *req.ProductID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return errors.New("product not found")
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Service) List(ctx context.Context) ([]PromotionResponse, error) {
+	promotions, err := s.queries.ListPromotions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PromotionResponse, len(promotions))
+	for i, p := range promotions {
+		result[i] = toResponse(p)
+	}
+	return result, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int32) (*PromotionResponse, error) {
+	p, err := s.queries.GetPromotionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, err
+	}
+
+	result := toResponse(p)
+	return &result, nil
+}
+
+func (s *Service) Create(ctx context.Context, req PromotionRequest) (*PromotionResponse, error) {
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	if err := s.checkReferences(ctx, req); err != nil {
+		return nil, err
+	}
+
+	p, err := s.queries.CreatePromotion(ctx, db.CreatePromotionParams{
+		Name:       req.Name,
+		Type:       req.Type,
+		CategoryID: int4(req.CategoryID),
+		ProductID:  int4(req.ProductID),
+		Percent:    numeric(req.Percent),
+		Amount:     numeric(req.Amount),
+		BuyQty:     int4(req.BuyQty),
+		GetQty:     int4(req.GetQty),
+		MinSpend:   numeric(req.MinSpend),
+		StartsAt:   pgtype.Timestamptz{Time: req.StartsAt, Valid: true},
+		EndsAt:     timestamptz(req.EndsAt),
+		Active:     active(req.Active),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := toResponse(p)
+	return &result, nil
+}
+
+// Update changes a promotion for future sales only; sales keep the discount
+// and promotion name they were recorded with.
+func (s *Service) Update(ctx context.Context, id int32, req PromotionRequest) (*PromotionResponse, error) {
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+	if err := s.checkReferences(ctx, req); err != nil {
+		return nil, err
+	}
+
+	p, err := s.queries.UpdatePromotion(ctx, db.UpdatePromotionParams{
+		ID:         id,
+		Name:       req.Name,
+		Type:       req.Type,
+		CategoryID: int4(req.CategoryID),
+		ProductID:  int4(req.ProductID),
+		Percent:    numeric(req.Percent),
+		Amount:     numeric(req.Amount),
+		BuyQty:     int4(req.BuyQty),
+		GetQty:     int4(req.GetQty),
+		MinSpend:   numeric(req.MinSpend),
+		StartsAt:   pgtype.Timestamptz{Time: req.StartsAt, Valid: true},
+		EndsAt:     timestamptz(req.EndsAt),
+		Active:     active(req.Active),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("promotion not found")
+		}
+		return nil, err
+	}
+
+	result := toResponse(p)
+	return &result, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int32) error {
+	if _, err := s.queries.GetPromotionByID(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("promotion not found")
+		}
+		return err
+	}
+
+	// Recorded sale discounts keep their promotion name (ON DELETE SET NULL)
+	return s.queries.DeletePromotion(ctx, id)
+}
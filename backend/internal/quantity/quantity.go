@@ -0,0 +1,253 @@
+// Package quantity provides an exact decimal type for the quantities of goods
+// sold, returned and kept in stock, so weighed and measured goods (1.25 kg of
+// coffee beans, 2.5 m of fabric) travel between JSON and the NUMERIC(12,3)
+// columns without passing through float64.
+package quantity
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"pos-system/internal/money"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Quantity is an amount of goods with up to three decimal places, held as a
+// whole number of thousandths. Quantities add, subtract and compare with the
+// usual operators.
+type Quantity int64
+
+// MaxPrecision is the most decimal places a product's quantities may have.
+const MaxPrecision = 3
+
+// scale is the number of thousandths in one unit.
+const scale = 1000
+
+// maxIntegerDigits matches NUMERIC(12,3).
+const maxIntegerDigits = 9
+
+// New returns a quantity of whole units.
+func New(units int64) Quantity {
+	return Quantity(units * scale)
+}
+
+// FromThousandths returns the quantity of thousandths given.
+func FromThousandths(n int64) Quantity {
+	return Quantity(n)
+}
+
+// Parse reads a decimal string such as "2", "1.25" or "-0.5". More than
+// three decimal places is an error rather than a silent rounding.
+func Parse(s string) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	digits := s
+	negative := false
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+	if len(frac) > MaxPrecision {
+		return 0, fmt.Errorf("quantity %q has more than %d decimal places", s, MaxPrecision)
+	}
+	if len(strings.TrimLeft(whole, "0")) > maxIntegerDigits {
+		return 0, fmt.Errorf("quantity %q is too large", s)
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return 0, fmt.Errorf("invalid quantity %q", s)
+	}
+
+	var n int64
+	for _, c := range whole + (frac + "000")[:MaxPrecision] {
+		n = n*10 + int64(c-'0')
+	}
+	if negative {
+		n = -n
+	}
+	return Quantity(n), nil
+}
+
+// MustParse is like Parse but panics on error. It is meant for constants and
+// tests.
+func MustParse(s string) Quantity {
+	q, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FromNumeric converts a NUMERIC column. NULL is zero, and values with more
+// than three decimal places are rounded half away from zero.
+func FromNumeric(n pgtype.Numeric) (Quantity, error) {
+	if !n.Valid {
+		return 0, nil
+	}
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return 0, errors.New("numeric is not a finite quantity")
+	}
+
+	v := new(big.Int).Set(n.Int)
+	if exp := n.Exp + MaxPrecision; exp >= 0 {
+		v.Mul(v, pow10(exp))
+	} else {
+		v = divRound(v, pow10(-exp))
+	}
+	if !v.IsInt64() {
+		return 0, errors.New("numeric is out of range for a quantity")
+	}
+	return Quantity(v.Int64()), nil
+}
+
+// Numeric converts the quantity for a NUMERIC column.
+func (q Quantity) Numeric() pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(int64(q)), Exp: -MaxPrecision, Valid: true}
+}
+
+// Thousandths returns the quantity as a whole number of thousandths.
+func (q Quantity) Thousandths() int64 {
+	return int64(q)
+}
+
+// Whole returns the number of complete units in q, e.g. 2 for 2.75. Rules
+// that count units, such as buy-two-get-one promotions, use it.
+func (q Quantity) Whole() int64 {
+	return int64(q) / scale
+}
+
+// Fits reports whether q has no more than precision decimal places.
+func (q Quantity) Fits(precision int) bool {
+	if precision >= MaxPrecision {
+		return true
+	}
+	if precision < 0 {
+		precision = 0
+	}
+	return int64(q)%pow10(int32(MaxPrecision-precision)).Int64() == 0
+}
+
+// Round rounds q to precision decimal places, half away from zero.
+func (q Quantity) Round(precision int) Quantity {
+	if precision >= MaxPrecision {
+		return q
+	}
+	if precision < 0 {
+		precision = 0
+	}
+	step := pow10(int32(MaxPrecision - precision))
+	return Quantity(new(big.Int).Mul(divRound(big.NewInt(int64(q)), step), step).Int64())
+}
+
+// Mul returns q units of a packaging unit holding factor base units each,
+// e.g. 2 cartons of 24 bottles is 48, rounded half away from zero to three
+// decimal places.
+func (q Quantity) Mul(factor Quantity) Quantity {
+	v := new(big.Int).Mul(big.NewInt(int64(q)), big.NewInt(int64(factor)))
+	return Quantity(divRound(v, big.NewInt(scale)).Int64())
+}
+
+// Cost returns the price of q units at unitPrice, rounded half away from zero
+// to the cent.
+func (q Quantity) Cost(unitPrice money.Amount) money.Amount {
+	return unitPrice.MulDiv(int64(q), scale)
+}
+
+// PerUnit returns the price of one unit when q units cost price, e.g. the
+// price of a bottle from the price of a carton of 24.
+func (q Quantity) PerUnit(price money.Amount) money.Amount {
+	return price.MulDiv(scale, int64(q))
+}
+
+// String formats the quantity with as few decimals as it needs, e.g. "2",
+// "1.25" or "0.125".
+func (q Quantity) String() string {
+	whole, frac, negative := q.parts()
+	sign := ""
+	if negative {
+		sign = "-"
+	}
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+// Display formats the quantity the way it is printed on receipts and
+// invoices: a decimal comma and only the decimals it needs, e.g. "2" or
+// "1,25".
+func (q Quantity) Display() string {
+	return strings.Replace(q.String(), ".", ",", 1)
+}
+
+// parts splits q into its whole units and its decimals with trailing zeros
+// dropped.
+func (q Quantity) parts() (string, string, bool) {
+	n := int64(q)
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+	frac := strings.TrimRight(fmt.Sprintf("%03d", n%scale), "0")
+	return strconv.FormatInt(n/scale, 10), frac, negative
+}
+
+// MarshalJSON writes the quantity as a JSON number with its exact decimal
+// text, so clients that always sent whole numbers keep reading them as such.
+func (q Quantity) MarshalJSON() ([]byte, error) {
+	return []byte(q.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number (1.25) or a decimal string ("1.25").
+// Numbers are parsed from their literal text, never through float64.
+func (q *Quantity) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return fmt.Errorf("invalid quantity %s", data)
+		}
+	}
+
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*q = v
+	return nil
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// divRound divides v by d, rounding half away from zero.
+func divRound(v, d *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(v, d, new(big.Int))
+	if new(big.Int).Mul(new(big.Int).Abs(r), big.NewInt(2)).Cmp(new(big.Int).Abs(d)) >= 0 {
+		if v.Sign()*d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
@@ -0,0 +1,161 @@
+package quantity
+
+import (
+	"encoding/json"
+	"math/big"
+	"pos-system/internal/money"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quantity
+	}{
+		{"2", 2000},
+		{"1.25", 1250},
+		{"0.125", 125},
+		{".5", 500},
+		{"-3", -3000},
+		{" 7.000 ", 7000},
+		{"999999999.999", 999999999999},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) returned error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d thousandths, want %d", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "-", ".", "1.2345", "1e3", "2kg", "1.2.3", "1234567890"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) should fail", in)
+		}
+	}
+}
+
+func TestStringAndDisplay(t *testing.T) {
+	tests := []struct {
+		q       Quantity
+		str     string
+		display string
+	}{
+		{0, "0", "0"},
+		{New(2), "2", "2"},
+		{1250, "1.25", "1,25"},
+		{125, "0.125", "0,125"},
+		{-500, "-0.5", "-0,5"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.String(); got != tt.str {
+			t.Errorf("Quantity(%d).String() = %q, want %q", int64(tt.q), got, tt.str)
+		}
+		if got := tt.q.Display(); got != tt.display {
+			t.Errorf("Quantity(%d).Display() = %q, want %q", int64(tt.q), got, tt.display)
+		}
+	}
+}
+
+func TestPrecision(t *testing.T) {
+	q := MustParse("1.25")
+	for precision, fits := range map[int]bool{0: false, 1: false, 2: true, 3: true} {
+		if got := q.Fits(precision); got != fits {
+			t.Errorf("%s.Fits(%d) = %v, want %v", q, precision, got, fits)
+		}
+	}
+
+	if got := MustParse("1.255").Round(2); got != MustParse("1.26") {
+		t.Errorf("Round(2) = %s, want 1.26", got)
+	}
+	if got := MustParse("2.5").Round(0); got != New(3) {
+		t.Errorf("Round(0) = %s, want 3", got)
+	}
+	if got := MustParse("2.75").Whole(); got != 2 {
+		t.Errorf("Whole() = %d, want 2", got)
+	}
+}
+
+func TestCost(t *testing.T) {
+	tests := []struct {
+		qty, price, want string
+	}{
+		{"2", "15000", "30000"},
+		{"1.25", "120000", "150000"},
+		{"0.333", "10000", "3330"},
+		{"0.005", "1", "0.01"},
+	}
+
+	for _, tt := range tests {
+		if got := MustParse(tt.qty).Cost(money.MustParse(tt.price)); got != money.MustParse(tt.want) {
+			t.Errorf("%s x %s = %s, want %s", tt.qty, tt.price, got, tt.want)
+		}
+	}
+
+	if got := New(24).PerUnit(money.MustParse("60000")); got != money.MustParse("2500") {
+		t.Errorf("60000 / 24 = %s, want 2500", got)
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		qty, factor, want string
+	}{
+		{"2", "24", "48"},
+		{"1.5", "12", "18"},
+		{"0.333", "0.5", "0.167"},
+		{"-1", "6", "-6"},
+	}
+
+	for _, tt := range tests {
+		if got := MustParse(tt.qty).Mul(MustParse(tt.factor)); got != MustParse(tt.want) {
+			t.Errorf("%s x %s = %s, want %s", tt.qty, tt.factor, got, tt.want)
+		}
+	}
+}
+
+func TestNumeric(t *testing.T) {
+	q := MustParse("1.25")
+	back, err := FromNumeric(q.Numeric())
+	if err != nil || back != q {
+		t.Errorf("FromNumeric(Numeric(%s)) = %s, %v", q, back, err)
+	}
+
+	got, err := FromNumeric(pgtype.Numeric{Int: big.NewInt(12345), Exp: -4, Valid: true})
+	if err != nil || got != MustParse("1.235") {
+		t.Errorf("FromNumeric(1.2345) = %s, %v, want 1.235", got, err)
+	}
+
+	got, err = FromNumeric(pgtype.Numeric{})
+	if err != nil || got != 0 {
+		t.Errorf("FromNumeric(NULL) = %s, %v, want 0", got, err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var req struct {
+		A Quantity `json:"a"`
+		B Quantity `json:"b"`
+	}
+	if err := json.Unmarshal([]byte(`{"a": 2, "b": "1.25"}`), &req); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if req.A != New(2) || req.B != MustParse("1.25") {
+		t.Errorf("decoded %s and %s, want 2 and 1.25", req.A, req.B)
+	}
+
+	out, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(out) != `{"a":2,"b":1.25}` {
+		t.Errorf("Marshal = %s", out)
+	}
+}
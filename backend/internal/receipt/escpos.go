@@ -0,0 +1,97 @@
+package receipt
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ESC/POS control bytes
+const (
+	esc = 0x1b
+	gs  = 0x1d
+	lf  = 0x0a
+)
+
+// codePage pairs a printer character table (selected with ESC t n) with the
+// charset used to encode text for it.
+type codePage struct {
+	number  byte
+	charmap *charmap.Charmap
+}
+
+// codePages are the tables most Epson-compatible printers number alike.
+var codePages = map[string]codePage{
+	"pc437":   {0, charmap.CodePage437},
+	"pc850":   {2, charmap.CodePage850},
+	"pc860":   {3, charmap.CodePage860},
+	"pc863":   {4, charmap.CodePage863},
+	"pc865":   {5, charmap.CodePage865},
+	"wpc1252": {16, charmap.Windows1252},
+	"pc866":   {17, charmap.CodePage866},
+	"pc852":   {18, charmap.CodePage852},
+	"pc858":   {19, charmap.CodePage858},
+}
+
+// encodeESCPOS produces the byte stream for the printer: initialise, select
+// the code page, print each line with its alignment and emphasis, then feed
+// and cut. Characters the code page lacks are printed as '?'.
+func encodeESCPOS(lines []line, cp codePage) []byte {
+	var buf bytes.Buffer
+	buf.Write([]byte{esc, '@'})
+	buf.Write([]byte{esc, 't', cp.number})
+
+	var cur line
+	for _, l := range lines {
+		if l.align != cur.align {
+			buf.Write([]byte{esc, 'a', byte(l.align)})
+		}
+		if l.bold != cur.bold {
+			buf.Write([]byte{esc, 'E', boolByte(l.bold)})
+		}
+		if l.large != cur.large {
+			// GS ! 0x01 is double height at normal width
+			buf.Write([]byte{gs, '!', boolByte(l.large)})
+		}
+		cur = l
+
+		for _, r := range l.text {
+			b, ok := cp.charmap.EncodeRune(r)
+			if !ok {
+				b = '?'
+			}
+			buf.WriteByte(b)
+		}
+		buf.WriteByte(lf)
+	}
+
+	// Feed past the tear bar and make a partial cut
+	buf.Write([]byte{gs, 'V', 66, 3})
+	return buf.Bytes()
+}
+
+// encodeText produces a UTF-8 preview of the receipt, centring with spaces
+// what the printer would centre itself.
+func encodeText(lines []line, width int) []byte {
+	var buf bytes.Buffer
+	for _, l := range lines {
+		text := l.text
+		if l.align == alignCenter {
+			if pad := (width - utf8.RuneCountInString(text)) / 2; pad > 0 {
+				text = strings.Repeat(" ", pad) + text
+			}
+		}
+		buf.WriteString(text)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func boolByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package receipt
+
+import (
+	"fmt"
+	"net/http"
+	"pos-system/internal/sale"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	sales *sale.Service
+	cfg   Config
+}
+
+func NewHandler(sales *sale.Service, cfg Config) *Handler {
+	return &Handler{sales: sales, cfg: cfg}
+}
+
+// Receipt renders a sale as ESC/POS bytes (format=escpos) or a plain-text
+// preview (format=text, the default). paper=58 or paper=80 overrides the
+// configured paper width for this request.
+func (h *Handler) Receipt(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sale id"})
+		return
+	}
+
+	cfg := h.cfg
+	if paper := c.Query("paper"); paper != "" {
+		width, err := strconv.Atoi(paper)
+		if err != nil || (width != 58 && width != 80) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "paper must be 58 or 80"})
+			return
+		}
+		cfg.PaperWidth = width
+	}
+
+	format := c.DefaultQuery("format", FormatText)
+	if format != FormatESCPOS && format != FormatText {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be escpos or text"})
+		return
+	}
+
+	s, err := h.sales.GetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		if err.Error() == "sale not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, err := Render(s, cfg, format)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if format == FormatESCPOS {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", s.InvoiceNo+".bin"))
+		c.Data(http.StatusOK, "application/octet-stream", data)
+		return
+	}
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", data)
+}
@@ -0,0 +1,320 @@
+// Package receipt renders sales for thermal receipt printers, either as a raw
+// ESC/POS byte stream or as plain text for on-screen previews.
+package receipt
+
+import (
+	"errors"
+	"fmt"
+	"pos-system/internal/money"
+	"pos-system/internal/sale"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	FormatESCPOS = "escpos"
+	FormatText   = "text"
+)
+
+// Config is the store header and footer printed on every receipt and the
+// printer it is laid out for.
+type Config struct {
+	StoreName    string
+	StoreAddress string
+	StorePhone   string
+	Footer       string
+	// PaperWidth is the roll width in millimetres, 58 or 80
+	PaperWidth int
+	// CodePage is the printer character table non-ASCII text is encoded in,
+	// e.g. pc437 or wpc1252. It only affects the ESC/POS format.
+	CodePage string
+}
+
+func (c Config) Validate() error {
+	if c.columns() == 0 {
+		return errors.New("receipt paper width must be 58 or 80")
+	}
+	if _, ok := codePages[strings.ToLower(c.CodePage)]; !ok {
+		return fmt.Errorf("unsupported receipt code page %q", c.CodePage)
+	}
+	return nil
+}
+
+// columns is how many characters of the printer's default font fit on a line.
+func (c Config) columns() int {
+	switch c.PaperWidth {
+	case 58:
+		return 32
+	case 80:
+		return 48
+	default:
+		return 0
+	}
+}
+
+// Render lays out the sale and encodes it in the given format.
+func Render(s *sale.SaleResponse, cfg Config, format string) ([]byte, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	lines, err := layout(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatESCPOS:
+		return encodeESCPOS(lines, codePages[strings.ToLower(cfg.CodePage)]), nil
+	case FormatText:
+		return encodeText(lines, cfg.columns()), nil
+	default:
+		return nil, errors.New("receipt format must be escpos or text")
+	}
+}
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+)
+
+// line is one printed line. Large text is printed at double height, so it
+// takes no more columns than normal text.
+type line struct {
+	text  string
+	align align
+	bold  bool
+	large bool
+}
+
+type builder struct {
+	width int
+	lines []line
+}
+
+func (b *builder) add(l line) {
+	b.lines = append(b.lines, l)
+}
+
+// centered adds text centred on the paper, wrapped to fit.
+func (b *builder) centered(text string, bold, large bool) {
+	for _, part := range wrap(text, b.width) {
+		b.add(line{text: part, align: alignCenter, bold: bold, large: large})
+	}
+}
+
+// text adds left-aligned text, wrapped to fit.
+func (b *builder) text(text string) {
+	for _, part := range wrap(text, b.width) {
+		b.add(line{text: part})
+	}
+}
+
+// pair puts label on the left and value on the right of one line. When the
+// two do not fit together the label gets a line of its own.
+func (b *builder) pair(label, value string, bold bool) {
+	gap := b.width - utf8.RuneCountInString(label) - utf8.RuneCountInString(value)
+	if gap < 1 {
+		for _, part := range wrap(label, b.width) {
+			b.add(line{text: part, bold: bold})
+		}
+		label = ""
+		gap = b.width - utf8.RuneCountInString(value)
+	}
+	b.add(line{text: label + strings.Repeat(" ", gap) + value, bold: bold})
+}
+
+func (b *builder) rule() {
+	b.add(line{text: strings.Repeat("-", b.width)})
+}
+
+func (b *builder) blank() {
+	b.add(line{})
+}
+
+// wrap breaks text into lines of at most width characters, at spaces where
+// it can.
+func wrap(text string, width int) []string {
+	var lines []string
+	for _, word := range strings.Fields(text) {
+		for utf8.RuneCountInString(word) > width {
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		n := len(lines)
+		if n > 0 && utf8.RuneCountInString(lines[n-1])+1+utf8.RuneCountInString(word) <= width {
+			lines[n-1] += " " + word
+		} else {
+			lines = append(lines, word)
+		}
+	}
+	return lines
+}
+
+// layout arranges the receipt. Item lines show the catalogue amount less the
+// manual and promotion discounts; the voucher is taken off the subtotal, and
+// tax is added for exclusive-tax lines and noted for inclusive ones, so the
+// printed figures add up to the total.
+func layout(s *sale.SaleResponse, cfg Config) ([]line, error) {
+	b := &builder{width: cfg.columns()}
+
+	b.centered(cfg.StoreName, true, true)
+	b.centered(cfg.StoreAddress, false, false)
+	b.centered(cfg.StorePhone, false, false)
+	b.rule()
+
+	b.pair("Invoice", s.InvoiceNo, false)
+	if t, err := time.Parse(time.RFC3339, s.CreatedAt); err == nil {
+		b.pair("Date", t.Format("02/01/2006 15:04"), false)
+	}
+	if s.CashierName != nil {
+		b.pair("Cashier", *s.CashierName, false)
+	}
+	if s.VoidedAt != nil {
+		b.blank()
+		b.centered("*** VOID ***", true, true)
+		if s.VoidReason != nil {
+			b.centered(*s.VoidReason, false, false)
+		}
+	}
+	if s.PaymentStatus == "pending" {
+		b.blank()
+		b.centered("*** AWAITING PAYMENT ***", true, false)
+	}
+	b.rule()
+
+	var itemsTotal, exclusiveTax, inclusiveTax money.Amount
+	for _, item := range s.Items {
+		price, err := money.Parse(item.Price)
+		if err != nil {
+			return nil, err
+		}
+		discount, err := money.Parse(item.Discount)
+		if err != nil {
+			return nil, err
+		}
+		tax, err := money.Parse(item.TaxAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		gross := item.Qty.Cost(price)
+		b.text(item.ProductName)
+		b.pair(fmt.Sprintf("  %s x %s", item.DisplayQty(), price.Display()), gross.Display(), false)
+		itemsTotal += gross
+
+		if discount != 0 {
+			b.pair("  Discount", (-discount).Display(), false)
+			itemsTotal -= discount
+		}
+		for _, p := range item.Promotions {
+			amount, err := money.Parse(p.Amount)
+			if err != nil {
+				return nil, err
+			}
+			b.pair("  "+p.Name, (-amount).Display(), false)
+			itemsTotal -= amount
+		}
+
+		if item.TaxInclusive {
+			inclusiveTax += tax
+		} else {
+			exclusiveTax += tax
+		}
+	}
+	b.rule()
+
+	total, err := money.Parse(s.TotalAmount)
+	if err != nil {
+		return nil, err
+	}
+	voucherDiscount, err := money.Parse(s.VoucherDiscount)
+	if err != nil {
+		return nil, err
+	}
+
+	b.pair("Subtotal", itemsTotal.Display(), false)
+	if voucherDiscount != 0 {
+		label := "Voucher"
+		if s.VoucherCode != nil {
+			label += " " + *s.VoucherCode
+		}
+		b.pair(label, (-voucherDiscount).Display(), false)
+	}
+	if exclusiveTax != 0 {
+		b.pair("Tax", exclusiveTax.Display(), false)
+	}
+	b.pair("TOTAL", total.Display(), true)
+	if inclusiveTax != 0 {
+		b.pair("Incl. tax", inclusiveTax.Display(), false)
+	}
+	// Cash rounding is shown under the total it was applied to, with what
+	// the customer actually paid for
+	if s.RoundingAmount != "" {
+		rounding, err := money.Parse(s.RoundingAmount)
+		if err != nil {
+			return nil, err
+		}
+		if rounding != 0 {
+			b.pair("Rounding", rounding.Display(), false)
+			b.pair("TOTAL DUE", (total + rounding).Display(), true)
+		}
+	}
+	b.blank()
+
+	// Split tenders are listed one by one; a single tender is just "Paid"
+	if len(s.Payments) > 1 {
+		for _, p := range s.Payments {
+			amount, err := money.Parse(p.Amount)
+			if err != nil {
+				return nil, err
+			}
+			b.pair(methodLabel(p.Method), amount.Display(), false)
+		}
+	}
+	paid, err := money.Parse(s.PaidAmount)
+	if err != nil {
+		return nil, err
+	}
+	change, err := money.Parse(s.ChangeAmount)
+	if err != nil {
+		return nil, err
+	}
+	paidLabel := "Paid"
+	if len(s.Payments) == 1 {
+		paidLabel += " (" + methodLabel(s.Payments[0].Method) + ")"
+	}
+	b.pair(paidLabel, paid.Display(), false)
+	b.pair("Change", change.Display(), false)
+
+	if s.PointsEarned > 0 || s.PointsRedeemed > 0 {
+		b.blank()
+		if s.PointsRedeemed > 0 {
+			b.pair("Points redeemed", fmt.Sprintf("%d", s.PointsRedeemed), false)
+		}
+		if s.PointsEarned > 0 {
+			b.pair("Points earned", fmt.Sprintf("%d", s.PointsEarned), false)
+		}
+	}
+
+	if cfg.Footer != "" {
+		b.rule()
+		b.centered(cfg.Footer, false, false)
+	}
+
+	return b.lines, nil
+}
+
+// methodLabel prints a payment method for the customer, so "gift_card"
+// reads "Gift card".
+func methodLabel(method string) string {
+	if method == "" {
+		return method
+	}
+	method = strings.ReplaceAll(method, "_", " ")
+	return strings.ToUpper(method[:1]) + strings.ToLower(method[1:])
+}
@@ -0,0 +1,170 @@
+package receipt
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"pos-system/internal/quantity"
+	"pos-system/internal/sale"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func strPtr(s string) *string { return &s }
+
+func int32Ptr(v int32) *int32 { return &v }
+
+// testSale has a promotion, a manual discount, a voucher, both inclusive and
+// exclusive tax, a non-ASCII product name, a line sold in a packaging unit
+// and split tenders.
+func testSale() *sale.SaleResponse {
+	return &sale.SaleResponse{
+		ID:              42,
+		InvoiceNo:       "INV-JKT01-20260115-00042",
+		UserID:          int32Ptr(3),
+		CashierName:     strPtr("siti"),
+		TotalAmount:     "76105.00",
+		PaidAmount:      "80000.00",
+		ChangeAmount:    "3895.00",
+		SubtotalAmount:  "68563.00",
+		TaxAmount:       "7542.00",
+		VoucherCode:     strPtr("HEMAT5"),
+		VoucherDiscount: "5000.00",
+		CustomerID:      int32Ptr(7),
+		PointsEarned:    3,
+		PaymentMethod:   strPtr(sale.PaymentMethodSplit),
+		Payments: []sale.SalePaymentResponse{
+			{ID: 1, Method: "qris", Amount: "40000.00"},
+			{ID: 2, Method: "cash", Amount: "36105.00"},
+		},
+		Items: []sale.SaleItemResponse{
+			{
+				ID:                1,
+				ProductID:         10,
+				ProductName:       "Kopi Susu Gula Aren Café Latte Extra Shot",
+				Qty:               quantity.New(2),
+				Price:             "25000.00",
+				Discount:          "0.00",
+				Subtotal:          "41625.00",
+				TaxRate:           "11.00",
+				TaxInclusive:      false,
+				TaxAmount:         "4125.00",
+				PromotionDiscount: "10000.00",
+				Promotions: []sale.SaleItemPromotionResponse{
+					{PromotionID: int32Ptr(1), Name: "Beli 2 Diskon 20%", Amount: "10000.00"},
+				},
+				VoucherDiscount: "2500.00",
+			},
+			{
+				ID:              2,
+				ProductID:       11,
+				ProductName:     "Roti Bakar",
+				Qty:             quantity.New(1),
+				Price:           "18500.00",
+				Discount:        "1500.00",
+				Subtotal:        "14500.00",
+				TaxRate:         "11.00",
+				TaxInclusive:    true,
+				TaxAmount:       "1437.00",
+				VoucherDiscount: "2500.00",
+				Promotions:      []sale.SaleItemPromotionResponse{},
+			},
+			{
+				ID:           3,
+				ProductID:    12,
+				ProductName:  "Air Mineral",
+				Qty:          quantity.New(3),
+				Unit:         strPtr("pack"),
+				Price:        "6000.00",
+				Discount:     "0.00",
+				Subtotal:     "19980.00",
+				TaxRate:      "11.00",
+				TaxInclusive: false,
+				TaxAmount:    "1980.00",
+				Promotions:   []sale.SaleItemPromotionResponse{},
+			},
+		},
+		CreatedAt: "2026-01-15T14:05:09+07:00",
+	}
+}
+
+func testConfig() Config {
+	return Config{
+		StoreName:    "Toko Maju Jaya",
+		StoreAddress: "Jl. Sudirman No. 12, Jakarta Pusat",
+		StorePhone:   "021-555-0123",
+		Footer:       "Terima kasih atas kunjungan Anda",
+		PaperWidth:   58,
+		CodePage:     "pc437",
+	}
+}
+
+func TestRenderGolden(t *testing.T) {
+	voided := testSale()
+	voided.VoidedAt = strPtr("2026-01-15T15:00:00+07:00")
+	voided.VoidReason = strPtr("Customer cancelled")
+	voided.Payments = voided.Payments[1:]
+	voided.PointsEarned = 0
+
+	tests := []struct {
+		golden     string
+		sale       *sale.SaleResponse
+		format     string
+		paperWidth int
+		codePage   string
+	}{
+		{"sale_58.txt", testSale(), FormatText, 58, "pc437"},
+		{"sale_80.txt", testSale(), FormatText, 80, "pc437"},
+		{"sale_58_pc437.escpos", testSale(), FormatESCPOS, 58, "pc437"},
+		{"sale_80_wpc1252.escpos", testSale(), FormatESCPOS, 80, "wpc1252"},
+		{"voided_58.txt", voided, FormatText, 58, "pc437"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			cfg := testConfig()
+			cfg.PaperWidth = tt.paperWidth
+			cfg.CodePage = tt.codePage
+
+			got, err := Render(tt.sale, cfg, tt.format)
+			if err != nil {
+				t.Fatalf("Render failed: %v", err)
+			}
+
+			path := filepath.Join("testdata", tt.golden+".golden")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatalf("Failed to write golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Output differs from %s (run with -update if the change is intended)\ngot:\n%q\nwant:\n%q", path, got, want)
+			}
+		})
+	}
+}
+
+func TestRenderRejectsBadConfig(t *testing.T) {
+	cfg := testConfig()
+	cfg.PaperWidth = 76
+	if _, err := Render(testSale(), cfg, FormatText); err == nil {
+		t.Error("Expected an error for an unsupported paper width")
+	}
+
+	cfg = testConfig()
+	cfg.CodePage = "pc999"
+	if _, err := Render(testSale(), cfg, FormatESCPOS); err == nil {
+		t.Error("Expected an error for an unknown code page")
+	}
+
+	if _, err := Render(testSale(), testConfig(), "pdf"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -123,3 +123,36 @@ func (h *Handler) GetStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+func (h *Handler) GetTaxSummary(c *gin.Context) {
+	fromStr := c.Query("from")
+	toStr := c.Query("to")
+
+	if fromStr == "" || toStr == "" {
+		to := time.Now()
+		from := to.AddDate(0, 0, -30)
+		fromStr = from.Format("2006-01-02")
+		toStr = to.Format("2006-01-02")
+	}
+
+	from, err := time.Parse("2006-01-02", fromStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'from' date format"})
+		return
+	}
+
+	to, err := time.Parse("2006-01-02", toStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid 'to' date format"})
+		return
+	}
+
+	to = to.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+
+	summary, err := h.service.GetTaxSummary(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
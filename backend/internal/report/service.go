@@ -4,27 +4,36 @@ import (
 	"context"
 	"fmt"
 	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-// numericToString converts pgtype.Numeric to string
+// numericToString formats a NUMERIC money column with two decimals
 func numericToString(n pgtype.Numeric) string {
-	if !n.Valid {
-		return "0"
+	amount, err := money.FromNumeric(n)
+	if err != nil {
+		return "0.00"
 	}
-	val, err := n.Value()
+	return amount.String()
+}
+
+// numericToQty reads a NUMERIC quantity column
+func numericToQty(n pgtype.Numeric) quantity.Quantity {
+	qty, err := quantity.FromNumeric(n)
 	if err != nil {
-		return "0"
+		return 0
 	}
-	return fmt.Sprintf("%v", val)
+	return qty
 }
 
-// numericFromInterface converts interface{} to string (for aggregated results)
+// numericFromInterface converts interface{} to string (for aggregated results).
+// Averages come back with more than two decimals and are rounded to cents.
 func numericFromInterface(v interface{}) string {
 	if v == nil {
-		return "0"
+		return "0.00"
 	}
 	if numeric, ok := v.(pgtype.Numeric); ok && numeric.Valid {
 		return numericToString(numeric)
@@ -40,17 +49,23 @@ func NewService(queries *db.Queries) *Service {
 	return &Service{queries: queries}
 }
 
+// SalesByDateResponse is one day of sales. TotalRounding is the cash
+// rounding collected on top of GrossRevenue, so the day's payments add up to
+// GrossRevenue + TotalRounding.
 type SalesByDateResponse struct {
 	SaleDate         string  `json:"sale_date"`
 	TotalTransactions int64   `json:"total_transactions"`
 	TotalRevenue      string  `json:"total_revenue"`
+	GrossRevenue      string  `json:"gross_revenue"`
+	TotalRefunds      string  `json:"total_refunds"`
+	TotalRounding     string  `json:"total_rounding"`
 }
 
 type TopProductResponse struct {
 	ProductID    int32   `json:"product_id"`
 	ProductName  string  `json:"product_name"`
 	SKU          *string `json:"sku"`
-	TotalQtySold int64   `json:"total_qty_sold"`
+	TotalQtySold quantity.Quantity `json:"total_qty_sold"`
 	TotalRevenue string  `json:"total_revenue"`
 }
 
@@ -58,12 +73,32 @@ type PaymentMethodStats struct {
 	PaymentMethod    string `json:"payment_method"`
 	TransactionCount int64  `json:"transaction_count"`
 	TotalAmount      string `json:"total_amount"`
+	TotalRefunds     string `json:"total_refunds"`
+}
+
+// TaxSummaryResponse is the tax charged at one rate. TaxableAmount is the
+// base the tax was charged on; NetTax is TaxAmount less tax refunded on
+// returns in the same period.
+type TaxSummaryResponse struct {
+	TaxRate          string `json:"tax_rate"`
+	TaxInclusive     bool   `json:"tax_inclusive"`
+	TransactionCount int64  `json:"transaction_count"`
+	TaxableAmount    string `json:"taxable_amount"`
+	TaxAmount        string `json:"tax_amount"`
+	TaxRefunded      string `json:"tax_refunded"`
+	NetTax           string `json:"net_tax"`
 }
 
+// SalesStatsResponse reports revenue net of refunds; GrossRevenue and
+// TotalRefunds carry the two sides of that figure. TotalRounding is the cash
+// rounding collected on top of GrossRevenue and is not revenue.
 type SalesStatsResponse struct {
 	TotalSales    int64  `json:"total_sales"`
 	TotalRevenue  string `json:"total_revenue"`
 	AvgSaleAmount string `json:"avg_sale_amount"`
+	GrossRevenue  string `json:"gross_revenue"`
+	TotalRefunds  string `json:"total_refunds"`
+	TotalRounding string `json:"total_rounding"`
 }
 
 func (s *Service) GetSalesByDate(ctx context.Context, from, to time.Time) ([]SalesByDateResponse, error) {
@@ -84,15 +119,13 @@ func (s *Service) GetSalesByDate(ctx context.Context, from, to time.Time) ([]Sal
 			saleDate = r.SaleDate.Time.Format("2006-01-02")
 		}
 
-		var totalRevenue string
-		if r.TotalRevenue != nil {
-			totalRevenue = numericFromInterface(r.TotalRevenue)
-		}
-
 		response[i] = SalesByDateResponse{
 			SaleDate:          saleDate,
 			TotalTransactions: r.TotalTransactions,
-			TotalRevenue:      totalRevenue,
+			TotalRevenue:      numericToString(r.TotalRevenue),
+			GrossRevenue:      numericToString(r.GrossRevenue),
+			TotalRefunds:      numericToString(r.TotalRefunds),
+			TotalRounding:     numericToString(r.TotalRounding),
 		}
 	}
 
@@ -118,17 +151,12 @@ func (s *Service) GetTopProducts(ctx context.Context, from, to time.Time, limit
 			sku = &r.Sku.String
 		}
 
-		var totalRevenue string
-		if r.TotalRevenue != nil {
-			totalRevenue = numericFromInterface(r.TotalRevenue)
-		}
-
 		response[i] = TopProductResponse{
 			ProductID:    r.ID,
 			ProductName:  r.Name,
 			SKU:          sku,
-			TotalQtySold: r.TotalQtySold,
-			TotalRevenue: totalRevenue,
+			TotalQtySold: numericToQty(r.TotalQtySold),
+			TotalRevenue: numericToString(r.TotalRevenue),
 		}
 	}
 
@@ -146,11 +174,6 @@ func (s *Service) GetSalesStats(ctx context.Context, from, to time.Time) (*Sales
 		return nil, err
 	}
 
-	var totalRevenue string
-	if stats.TotalRevenue != nil {
-		totalRevenue = numericFromInterface(stats.TotalRevenue)
-	}
-
 	var avgSaleAmount string
 	if stats.AvgSaleAmount != nil {
 		avgSaleAmount = numericFromInterface(stats.AvgSaleAmount)
@@ -158,8 +181,11 @@ func (s *Service) GetSalesStats(ctx context.Context, from, to time.Time) (*Sales
 
 	return &SalesStatsResponse{
 		TotalSales:    stats.TotalSales,
-		TotalRevenue:  totalRevenue,
+		TotalRevenue:  numericToString(stats.TotalRevenue),
 		AvgSaleAmount: avgSaleAmount,
+		GrossRevenue:  numericToString(stats.GrossRevenue),
+		TotalRefunds:  numericToString(stats.TotalRefunds),
+		TotalRounding: numericToString(stats.TotalRounding),
 	}, nil
 }
 
@@ -181,18 +207,40 @@ func (s *Service) GetSalesByPaymentMethod(ctx context.Context, from, to time.Tim
 			method = r.PaymentMethod.String
 		}
 
-		var totalAmount string
-		if r.TotalAmount != nil {
-			totalAmount = numericFromInterface(r.TotalAmount)
-		}
-
 		response[i] = PaymentMethodStats{
 			PaymentMethod:    method,
 			TransactionCount: r.TransactionCount,
-			TotalAmount:      totalAmount,
+			TotalAmount:      numericToString(r.TotalAmount),
+			TotalRefunds:     numericToString(r.TotalRefunds),
 		}
 	}
 
 	return response, nil
 }
 
+func (s *Service) GetTaxSummary(ctx context.Context, from, to time.Time) ([]TaxSummaryResponse, error) {
+	fromPg := pgtype.Timestamptz{Time: from, Valid: true}
+	toPg := pgtype.Timestamptz{Time: to, Valid: true}
+	results, err := s.queries.TaxSummary(ctx, db.TaxSummaryParams{
+		CreatedAt:   fromPg,
+		CreatedAt_2: toPg,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]TaxSummaryResponse, len(results))
+	for i, r := range results {
+		response[i] = TaxSummaryResponse{
+			TaxRate:          numericToString(r.TaxRate),
+			TaxInclusive:     r.TaxInclusive,
+			TransactionCount: r.TransactionCount,
+			TaxableAmount:    numericToString(r.TaxableAmount),
+			TaxAmount:        numericToString(r.TaxAmount),
+			TaxRefunded:      numericToString(r.TaxRefunded),
+			NetTax:           numericToString(r.NetTax),
+		}
+	}
+
+	return response, nil
+}
@@ -0,0 +1,112 @@
+package returns
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) Create(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req CreateReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ret, err := h.service.Create(c.Request.Context(), userID.(int32), req)
+	if err != nil {
+		errMsg := err.Error()
+		if errMsg == "sale not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": errMsg})
+			return
+		}
+		// Validation errors (quantities, refund amounts, foreign sale items,
+		// unusable cards for a card refund)
+		if errMsg == "cannot return items from a voided sale" ||
+			errMsg == "cannot return items from a sale awaiting payment" ||
+			strings.Contains(errMsg, "return qty") ||
+			strings.Contains(errMsg, "refund amount") ||
+			strings.Contains(errMsg, "refund method") ||
+			strings.Contains(errMsg, "sale item") ||
+			strings.Contains(errMsg, "at least one item") ||
+			strings.HasPrefix(errMsg, "gift card") ||
+			strings.HasPrefix(errMsg, "card number") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		if strings.HasPrefix(errMsg, "payment provider") {
+			c.JSON(http.StatusBadGateway, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ret)
+}
+
+func (h *Handler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid return id"})
+		return
+	}
+
+	ret, err := h.service.GetByID(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ret)
+}
+
+func (h *Handler) List(c *gin.Context) {
+	// Filter by sale when sale_id is given
+	if saleIDStr := c.Query("sale_id"); saleIDStr != "" {
+		saleID, err := strconv.ParseInt(saleIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sale id"})
+			return
+		}
+
+		rets, err := h.service.ListBySale(c.Request.Context(), int32(saleID))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, rets)
+		return
+	}
+
+	limitStr := c.DefaultQuery("limit", "50")
+	offsetStr := c.DefaultQuery("offset", "0")
+
+	limit, _ := strconv.ParseInt(limitStr, 10, 32)
+	offset, _ := strconv.ParseInt(offsetStr, 10, 32)
+
+	rets, err := h.service.List(c.Request.Context(), int32(limit), int32(offset))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rets)
+}
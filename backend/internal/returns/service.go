@@ -0,0 +1,461 @@
+package returns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-system/internal/db"
+	"pos-system/internal/giftcard"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	saleapi "pos-system/internal/sale"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// numericToString formats a NUMERIC money column with two decimals
+func numericToString(n pgtype.Numeric) string {
+	amount, err := money.FromNumeric(n)
+	if err != nil {
+		return "0.00"
+	}
+	return amount.String()
+}
+
+// numericToQty reads a NUMERIC quantity column
+func numericToQty(n pgtype.Numeric) quantity.Quantity {
+	qty, err := quantity.FromNumeric(n)
+	if err != nil {
+		return 0
+	}
+	return qty
+}
+
+type Service struct {
+	queries *db.Queries
+	db      *pgxpool.Pool
+	sales   *saleapi.Service
+}
+
+// NewService takes the sale service for return numbers and refunds through
+// the payment provider.
+func NewService(queries *db.Queries, db *pgxpool.Pool, sales *saleapi.Service) *Service {
+	return &Service{queries: queries, db: db, sales: sales}
+}
+
+// CreateReturnRequest refunds through RefundMethod. Refunding to
+// store_credit issues a new store credit card unless CardNumber names one to
+// top up; refunding to gift_card credits CardNumber, or the card the sale was
+// paid from.
+type CreateReturnRequest struct {
+	SaleID       int32               `json:"sale_id" binding:"required"`
+	Items        []ReturnItemRequest `json:"items" binding:"required"`
+	RefundMethod string              `json:"refund_method"`
+	CardNumber   string              `json:"card_number"`
+	Reason       string              `json:"reason"`
+}
+
+// ReturnItemRequest returns Qty units of one sale item, counted in the unit
+// it was sold in. RefundAmount defaults to the item's subtotal prorated by
+// quantity, so line discounts carry over.
+type ReturnItemRequest struct {
+	SaleItemID   int32             `json:"sale_item_id" binding:"required"`
+	Qty          quantity.Quantity `json:"qty" binding:"required"`
+	RefundAmount *money.Amount     `json:"refund_amount"`
+}
+
+// ReturnResponse describes a return. CreditCardNumber is the card a store
+// credit or gift card refund went to.
+type ReturnResponse struct {
+	ID               int32                `json:"id"`
+	ReturnNo         string               `json:"return_no"`
+	SaleID           int32                `json:"sale_id"`
+	InvoiceNo        string               `json:"invoice_no"`
+	UserID           *int32               `json:"user_id"`
+	CashierName      *string              `json:"cashier_name"`
+	RefundAmount     string               `json:"refund_amount"`
+	RefundMethod     *string              `json:"refund_method"`
+	CreditCardNumber *string              `json:"credit_card_number,omitempty"`
+	Reason           *string              `json:"reason"`
+	Items            []ReturnItemResponse `json:"items"`
+	CreatedAt        string               `json:"created_at"`
+}
+
+type ReturnItemResponse struct {
+	ID          int32             `json:"id"`
+	SaleItemID  int32             `json:"sale_item_id"`
+	ProductID   int32             `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	SKU         *string           `json:"sku"`
+	Qty         quantity.Quantity `json:"qty"`
+	// Unit is the packaging unit Qty is counted in, null for the base unit
+	Unit         *string `json:"unit"`
+	RefundAmount string  `json:"refund_amount"`
+}
+
+func (s *Service) Create(ctx context.Context, userID int32, req CreateReturnRequest) (*ReturnResponse, error) {
+	if len(req.Items) == 0 {
+		return nil, errors.New("return must contain at least one item")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	// Lock the sale so concurrent returns (or a void) against it are serialized
+	// and the already-returned quantities read below stay accurate
+	sale, err := qtx.GetSaleForUpdate(ctx, req.SaleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("sale not found")
+		}
+		return nil, err
+	}
+	if sale.VoidedAt.Valid {
+		return nil, errors.New("cannot return items from a voided sale")
+	}
+	if sale.PaymentStatus == "pending" {
+		return nil, errors.New("cannot return items from a sale awaiting payment")
+	}
+
+	saleItems, err := qtx.GetReturnableSaleItems(ctx, pgtype.Int4{Int32: sale.ID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	saleItemsByID := make(map[int32]db.GetReturnableSaleItemsRow, len(saleItems))
+	for _, si := range saleItems {
+		saleItemsByID[si.ID] = si
+	}
+
+	// Validate every line and work out its refund before writing anything
+	refunds := make([]money.Amount, len(req.Items))
+	restock := make([]quantity.Quantity, len(req.Items))
+	seen := make(map[int32]bool, len(req.Items))
+	var totalRefund, refundedBefore money.Amount
+	for _, si := range saleItems {
+		refunded, err := money.FromNumeric(si.RefundedAmount)
+		if err != nil {
+			return nil, err
+		}
+		refundedBefore += refunded
+	}
+	for i, item := range req.Items {
+		si, ok := saleItemsByID[item.SaleItemID]
+		if !ok {
+			return nil, fmt.Errorf("sale item %d does not belong to sale %s", item.SaleItemID, sale.InvoiceNo)
+		}
+		if seen[item.SaleItemID] {
+			return nil, fmt.Errorf("sale item %d appears more than once in the return", item.SaleItemID)
+		}
+		seen[item.SaleItemID] = true
+
+		if item.Qty <= 0 {
+			return nil, errors.New("return qty must be greater than zero")
+		}
+		// Qty is in the unit the item was sold in; precision applies to the
+		// base units it puts back in stock
+		unitFactor, err := quantity.FromNumeric(si.UnitFactor)
+		if err != nil {
+			return nil, err
+		}
+		restock[i] = item.Qty.Mul(unitFactor)
+		if !restock[i].Fits(int(si.QtyPrecision)) {
+			return nil, fmt.Errorf("return qty %s for sale item %d allows at most %d decimal places", item.Qty, si.ID, si.QtyPrecision)
+		}
+
+		soldQty, err := quantity.FromNumeric(si.Qty)
+		if err != nil {
+			return nil, err
+		}
+		returnedQty, err := quantity.FromNumeric(si.ReturnedQty)
+		if err != nil {
+			return nil, err
+		}
+		remainingQty := soldQty - returnedQty
+		if item.Qty > remainingQty {
+			return nil, fmt.Errorf("return qty exceeds sold qty for sale item %d (sold: %s, already returned: %s, requested: %s)",
+				si.ID, soldQty, returnedQty, item.Qty)
+		}
+
+		// Returning the last units refunds whatever is left on the line so
+		// prorating never leaves a rounding remainder behind
+		subtotal, err := money.FromNumeric(si.Subtotal)
+		if err != nil {
+			return nil, err
+		}
+		refunded, err := money.FromNumeric(si.RefundedAmount)
+		if err != nil {
+			return nil, err
+		}
+		maxRefund := subtotal - refunded
+		if item.Qty < remainingQty {
+			maxRefund = subtotal.MulDiv(item.Qty.Thousandths(), soldQty.Thousandths())
+		}
+
+		refund := maxRefund
+		if item.RefundAmount != nil {
+			if *item.RefundAmount < 0 || *item.RefundAmount > maxRefund {
+				return nil, fmt.Errorf("refund amount for sale item %d must be between 0 and %s", si.ID, maxRefund)
+			}
+			refund = *item.RefundAmount
+		}
+
+		refunds[i] = refund
+		totalRefund += refund
+	}
+
+	// Refund through the original tender unless told otherwise. A split-tender
+	// sale has no single original tender, so the method must be given.
+	refundMethodPg := sale.PaymentMethod
+	if req.RefundMethod != "" {
+		refundMethodPg = pgtype.Text{String: req.RefundMethod, Valid: true}
+	} else if sale.PaymentMethod.String == saleapi.PaymentMethodSplit {
+		return nil, errors.New("refund method is required for split-tender sales")
+	}
+
+	var reasonPg pgtype.Text
+	if req.Reason != "" {
+		reasonPg = pgtype.Text{String: req.Reason, Valid: true}
+	}
+
+	// A QRIS or e-wallet refund goes back through the provider before the
+	// return is written, so one the provider turns down records nothing.
+	// Only the sale row is locked while the provider answers.
+	if err := s.sales.RefundReturn(ctx, qtx, sale.ID, refundMethodPg.String, totalRefund); err != nil {
+		return nil, err
+	}
+
+	returnNo, err := s.sales.NextReturnNo(ctx, qtx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := qtx.CreateSaleReturn(ctx, db.CreateSaleReturnParams{
+		ReturnNo:     returnNo,
+		SaleID:       sale.ID,
+		UserID:       pgtype.Int4{Int32: userID, Valid: true},
+		RefundAmount: totalRefund.Numeric(),
+		RefundMethod: refundMethodPg,
+		Reason:       reasonPg,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	restocked := make(map[int32]quantity.Quantity, len(req.Items))
+	for i, item := range req.Items {
+		si := saleItemsByID[item.SaleItemID]
+
+		_, err := qtx.CreateSaleReturnItem(ctx, db.CreateSaleReturnItemParams{
+			ReturnID:     ret.ID,
+			SaleItemID:   si.ID,
+			ProductID:    si.ProductID,
+			Qty:          item.Qty.Numeric(),
+			RefundAmount: refunds[i].Numeric(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		restocked[si.ProductID.Int32] += restock[i]
+	}
+
+	// Restock returned goods (increase)
+	if err := saleapi.Restock(ctx, qtx, restocked); err != nil {
+		return nil, err
+	}
+
+	// Take back the loyalty points earned on what is refunded
+	if err := saleapi.ReturnPoints(ctx, qtx, sale, refundedBefore, refundedBefore+totalRefund); err != nil {
+		return nil, err
+	}
+
+	if err := creditCard(ctx, qtx, sale, ret, req.CardNumber, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, ret.ID)
+}
+
+// isCardRefund reports whether method refunds onto a card rather than
+// paying out at the till.
+func isCardRefund(method string) bool {
+	return strings.EqualFold(method, giftcard.TypeStoreCredit) || strings.EqualFold(method, saleapi.PaymentMethodGiftCard)
+}
+
+// creditCard pays a store credit or gift card refund onto its card.
+func creditCard(ctx context.Context, qtx *db.Queries, sale db.Sale, ret db.SaleReturn, cardNumber string, userID int32) error {
+	if !isCardRefund(ret.RefundMethod.String) {
+		return nil
+	}
+	amount, err := money.FromNumeric(ret.RefundAmount)
+	if err != nil || amount <= 0 {
+		return err
+	}
+
+	// A gift card refund goes back to the card the sale was paid from
+	// unless another is named
+	if cardNumber == "" && strings.EqualFold(ret.RefundMethod.String, saleapi.PaymentMethodGiftCard) {
+		if cardNumber, err = giftcard.RedeemedCard(ctx, qtx, sale.ID); err != nil {
+			return err
+		}
+	}
+
+	_, err = giftcard.Credit(ctx, qtx, giftcard.CreditParams{
+		CardNumber: cardNumber,
+		CustomerID: sale.CustomerID,
+		Amount:     amount,
+		ReturnID:   ret.ID,
+		UserID:     userID,
+	})
+	return err
+}
+
+func (s *Service) GetByID(ctx context.Context, id int32) (*ReturnResponse, error) {
+	ret, err := s.queries.GetSaleReturnByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("return not found")
+		}
+		return nil, err
+	}
+
+	return s.toResponse(ctx, ret)
+}
+
+func (s *Service) List(ctx context.Context, limit, offset int32) ([]ReturnResponse, error) {
+	rets, err := s.queries.ListSaleReturns(ctx, db.ListSaleReturnsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ReturnResponse, len(rets))
+	for i, ret := range rets {
+		resp, err := s.toResponse(ctx, db.GetSaleReturnByIDRow(ret))
+		if err != nil {
+			return nil, err
+		}
+		result[i] = *resp
+	}
+
+	return result, nil
+}
+
+func (s *Service) ListBySale(ctx context.Context, saleID int32) ([]ReturnResponse, error) {
+	rets, err := s.queries.ListSaleReturnsBySale(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ReturnResponse, len(rets))
+	for i, ret := range rets {
+		resp, err := s.toResponse(ctx, db.GetSaleReturnByIDRow(ret))
+		if err != nil {
+			return nil, err
+		}
+		result[i] = *resp
+	}
+
+	return result, nil
+}
+
+func (s *Service) toResponse(ctx context.Context, ret db.GetSaleReturnByIDRow) (*ReturnResponse, error) {
+	items, err := s.queries.GetSaleReturnItems(ctx, ret.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	itemResponses := make([]ReturnItemResponse, len(items))
+	for i, item := range items {
+		var productID int32
+		if item.ProductID.Valid {
+			productID = item.ProductID.Int32
+		}
+
+		var sku *string
+		if item.Sku.Valid {
+			sku = &item.Sku.String
+		}
+
+		var unit *string
+		if item.UnitName.Valid {
+			unit = &item.UnitName.String
+		}
+
+		itemResponses[i] = ReturnItemResponse{
+			ID:           item.ID,
+			SaleItemID:   item.SaleItemID,
+			ProductID:    productID,
+			ProductName:  item.ProductName,
+			SKU:          sku,
+			Qty:          numericToQty(item.Qty),
+			Unit:         unit,
+			RefundAmount: numericToString(item.RefundAmount),
+		}
+	}
+
+	var userID *int32
+	if ret.UserID.Valid {
+		userID = &ret.UserID.Int32
+	}
+
+	var cashierName *string
+	if ret.CashierName.Valid {
+		cashierName = &ret.CashierName.String
+	}
+
+	var refundMethod *string
+	if ret.RefundMethod.Valid {
+		refundMethod = &ret.RefundMethod.String
+	}
+
+	var creditCardNumber *string
+	if isCardRefund(ret.RefundMethod.String) {
+		card, err := s.queries.GetGiftCardByReturn(ctx, pgtype.Int4{Int32: ret.ID, Valid: true})
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		if err == nil {
+			creditCardNumber = &card.CardNumber
+		}
+	}
+
+	var reason *string
+	if ret.Reason.Valid {
+		reason = &ret.Reason.String
+	}
+
+	var createdAt string
+	if ret.CreatedAt.Valid {
+		createdAt = ret.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return &ReturnResponse{
+		ID:               ret.ID,
+		ReturnNo:         ret.ReturnNo,
+		SaleID:           ret.SaleID,
+		InvoiceNo:        ret.InvoiceNo,
+		UserID:           userID,
+		CashierName:      cashierName,
+		RefundAmount:     numericToString(ret.RefundAmount),
+		RefundMethod:     refundMethod,
+		CreditCardNumber: creditCardNumber,
+		Reason:           reason,
+		Items:            itemResponses,
+		CreatedAt:        createdAt,
+	}, nil
+}
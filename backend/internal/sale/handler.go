@@ -1,9 +1,14 @@
 package sale
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"pos-system/internal/money"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -28,17 +33,13 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
 
 	sale, err := h.service.Create(c.Request.Context(), userID.(int32), req)
 	if err != nil {
-		// Check if it's a validation error (stock not sufficient, paid amount insufficient)
-		errMsg := err.Error()
-		if errMsg == "paid amount is less than total amount" || 
-		   strings.Contains(errMsg, "stock not sufficient") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(CreateErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -54,26 +55,392 @@ func (h *Handler) GetByID(c *gin.Context) {
 
 	sale, err := h.service.GetByID(c.Request.Context(), int32(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		if err.Error() == "sale not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, sale)
 }
 
+// List serves GET /sales. Filters come from the query string; the page
+// itself is the body, with the match count in X-Total-Count and the cursor
+// for the next page in X-Next-Cursor.
 func (h *Handler) List(c *gin.Context) {
-	limitStr := c.DefaultQuery("limit", "50")
-	offsetStr := c.DefaultQuery("offset", "0")
+	filter, err := FilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.service.List(c.Request.Context(), filter)
+	if err != nil {
+		switch err.Error() {
+		case "invalid cursor", "min_total cannot exceed max_total", "from must be before to":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+	c.JSON(http.StatusOK, page.Sales)
+}
+
+// FilterFromQuery reads the sales listing filters. Dates are YYYY-MM-DD,
+// where to covers the whole day, or RFC 3339 timestamps, where to is
+// exclusive.
+func FilterFromQuery(c *gin.Context) (ListFilter, error) {
+	var f ListFilter
+
+	if v := c.Query("from"); v != "" {
+		from, _, err := parseListTime(v)
+		if err != nil {
+			return f, errors.New("invalid 'from' date (use YYYY-MM-DD or RFC 3339)")
+		}
+		f.From = &from
+	}
+	if v := c.Query("to"); v != "" {
+		to, dateOnly, err := parseListTime(v)
+		if err != nil {
+			return f, errors.New("invalid 'to' date (use YYYY-MM-DD or RFC 3339)")
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		f.To = &to
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  **int32
+	}{
+		{"cashier_id", &f.CashierID},
+		{"product_id", &f.ProductID},
+	} {
+		if v := c.Query(p.name); v != "" {
+			id, err := strconv.ParseInt(v, 10, 32)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s", p.name)
+			}
+			id32 := int32(id)
+			*p.dst = &id32
+		}
+	}
 
-	limit, _ := strconv.ParseInt(limitStr, 10, 32)
-	offset, _ := strconv.ParseInt(offsetStr, 10, 32)
+	for _, p := range []struct {
+		name string
+		dst  **money.Amount
+	}{
+		{"min_total", &f.MinTotal},
+		{"max_total", &f.MaxTotal},
+	} {
+		if v := c.Query(p.name); v != "" {
+			amount, err := money.Parse(v)
+			if err != nil {
+				return f, fmt.Errorf("invalid %s: %v", p.name, err)
+			}
+			*p.dst = &amount
+		}
+	}
+
+	f.PaymentMethod = strings.TrimSpace(c.Query("payment_method"))
+	f.InvoicePrefix = strings.TrimSpace(c.Query("invoice_prefix"))
+	f.Cursor = c.Query("cursor")
+
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
+	offset, _ := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	f.Limit = int32(limit)
+	f.Offset = int32(offset)
+
+	return f, nil
+}
 
-	sales, err := h.service.List(c.Request.Context(), int32(limit), int32(offset))
+func parseListTime(v string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	return t, false, err
+}
+
+
+func (h *Handler) Void(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sale id"})
+		return
+	}
+
+	var req VoidSaleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sale, err := h.service.Void(c.Request.Context(), int32(id), userID.(int32), req)
+	if err != nil {
+		switch err.Error() {
+		case "sale not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "sale already voided", "sale has returns and cannot be voided", "sale is awaiting payment":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, sale)
+}
+
+func (h *Handler) Hold(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	var req HoldCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cart, err := h.service.Hold(c.Request.Context(), userID.(int32), req)
+	if err != nil {
+		if strings.Contains(err.Error(), "held cart") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, cart)
+}
+
+func (h *Handler) ListHeld(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	carts, err := h.service.ListHeld(c.Request.Context(), userID.(int32))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, sales)
+	c.JSON(http.StatusOK, carts)
 }
 
+func (h *Handler) Resume(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid held cart id"})
+		return
+	}
+
+	cart, err := h.service.Resume(c.Request.Context(), userID.(int32), int32(id))
+	if err != nil {
+		if err.Error() == "held cart not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+func (h *Handler) CheckoutHeld(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid held cart id"})
+		return
+	}
+
+	var req CheckoutHeldCartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sale, err := h.service.CheckoutHeld(c.Request.Context(), userID.(int32), int32(id), req)
+	if err != nil {
+		errMsg := err.Error()
+		if errMsg == "held cart not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(CreateErrorStatus(err), gin.H{"error": errMsg})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sale)
+}
+
+// createErrorStatus maps errors from sale creation to a status code: failed
+// override approval is forbidden, a reused idempotency key is a conflict,
+// pricing, stock and payment problems are bad requests, anything else is a
+// server error.
+func (h *Handler) ValidateVoucher(c *gin.Context) {
+	var req ValidateVoucherRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.service.ValidateVoucher(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(CreateErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// CreateErrorStatus maps errors from creating a sale to a status code. Other
+// packages that settle through Create use it for the errors they pass on.
+func CreateErrorStatus(err error) int {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, "price override"):
+		return http.StatusForbidden
+	case strings.HasPrefix(errMsg, "payment provider"):
+		return http.StatusBadGateway
+	case strings.Contains(errMsg, "idempotency key already used"),
+		strings.Contains(errMsg, "no open shift"):
+		return http.StatusConflict
+	case strings.Contains(errMsg, "stock not sufficient"),
+		strings.HasPrefix(errMsg, "idempotency key"),
+		strings.Contains(errMsg, "payment"),
+		strings.Contains(errMsg, "paid amount"),
+		strings.HasPrefix(errMsg, "item "),
+		strings.HasPrefix(errMsg, "product "),
+		strings.HasPrefix(errMsg, "sale must"),
+		strings.HasPrefix(errMsg, "voucher"),
+		strings.HasPrefix(errMsg, "gift card"),
+		strings.HasPrefix(errMsg, "customer "):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// paymentErrorStatus maps errors from the payment endpoints to a status code.
+func paymentErrorStatus(err error) int {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "sale not found",
+		errMsg == "sale has no payment charge",
+		errMsg == "payment charge not found",
+		errMsg == "unknown payment provider",
+		errMsg == "mock payment provider is not enabled":
+		return http.StatusNotFound
+	case errMsg == "invalid callback signature":
+		return http.StatusUnauthorized
+	case errMsg == "sale is not awaiting payment", errMsg == "payment charge is not pending":
+		return http.StatusConflict
+	case strings.HasPrefix(errMsg, "payment provider"):
+		return http.StatusBadGateway
+	case errMsg == "invalid callback body",
+		strings.HasPrefix(errMsg, "status must be"),
+		strings.HasPrefix(errMsg, "payment amount"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Payment serves the QRIS or e-wallet charge of a sale, checking with the
+// provider while it is pending.
+func (h *Handler) Payment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sale id"})
+		return
+	}
+
+	charge, err := h.service.Payment(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, charge)
+}
+
+func (h *Handler) CancelPayment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sale id"})
+		return
+	}
+
+	sale, err := h.service.CancelPayment(c.Request.Context(), int32(id))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sale)
+}
+
+// PaymentCallback receives the provider's report on a charge. It is not
+// behind the login; the body must carry the provider's signature.
+func (h *Handler) PaymentCallback(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid callback body"})
+		return
+	}
+
+	charge, err := h.service.HandleCallback(c.Request.Context(), c.Param("provider"), c.Request.Header, body)
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, charge)
+}
+
+// SettleMock pays, fails or expires a charge of the mock provider, standing
+// in for the customer during development.
+func (h *Handler) SettleMock(c *gin.Context) {
+	charge, err := h.service.SettleMock(c.Request.Context(), c.Param("reference"), c.Param("status"))
+	if err != nil {
+		c.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, charge)
+}
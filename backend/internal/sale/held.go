@@ -0,0 +1,194 @@
+package sale
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type HoldCartRequest struct {
+	Label string            `json:"label"`
+	Items []SaleItemRequest `json:"items" binding:"required"`
+}
+
+// CheckoutHeldCartRequest carries the tenders for a held cart; its items come
+// from the cart itself.
+type CheckoutHeldCartRequest struct {
+	Payments        []PaymentRequest       `json:"payments"`
+	PaidAmount      money.Amount           `json:"paid_amount"`
+	PaymentMethod   string                 `json:"payment_method"`
+	Override        *PriceOverrideApproval `json:"override"`
+	VoucherCode     string                 `json:"voucher_code"`
+	VoucherCustomer string                 `json:"voucher_customer"`
+	CustomerID      *int32                 `json:"customer_id"`
+}
+
+type HeldCartResponse struct {
+	ID        int32             `json:"id"`
+	Label     *string           `json:"label"`
+	Items     []SaleItemRequest `json:"items"`
+	CreatedAt string            `json:"created_at"`
+	ExpiresAt string            `json:"expires_at"`
+}
+
+// Hold parks a cart for the cashier so the counter can serve someone else.
+func (s *Service) Hold(ctx context.Context, userID int32, req HoldCartRequest) (*HeldCartResponse, error) {
+	if len(req.Items) == 0 {
+		return nil, errors.New("held cart must contain at least one item")
+	}
+	for _, item := range req.Items {
+		if item.Qty <= 0 {
+			return nil, errors.New("held cart item qty must be greater than zero")
+		}
+	}
+
+	// Purge expired carts while we are here
+	if _, err := s.queries.DeleteExpiredHeldCarts(ctx); err != nil {
+		return nil, err
+	}
+
+	items, err := json.Marshal(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	var labelPg pgtype.Text
+	if req.Label != "" {
+		labelPg = pgtype.Text{String: req.Label, Valid: true}
+	}
+
+	cart, err := s.queries.CreateHeldCart(ctx, db.CreateHeldCartParams{
+		UserID:    userID,
+		Label:     labelPg,
+		Items:     items,
+		ExpiresAt: pgtype.Timestamptz{Time: time.Now().Add(s.cfg.HeldCartTTL), Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return heldCartResponseFromRow(cart)
+}
+
+// ListHeld returns the cashier's carts that have not expired yet.
+func (s *Service) ListHeld(ctx context.Context, userID int32) ([]HeldCartResponse, error) {
+	carts, err := s.queries.ListHeldCartsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]HeldCartResponse, len(carts))
+	for i, cart := range carts {
+		resp, err := heldCartResponseFromRow(cart)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = *resp
+	}
+
+	return result, nil
+}
+
+// Resume takes a held cart back to the counter. The cart is removed so it
+// cannot be resumed or checked out twice.
+func (s *Service) Resume(ctx context.Context, userID, id int32) (*HeldCartResponse, error) {
+	cart, err := s.queries.DeleteHeldCart(ctx, db.DeleteHeldCartParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("held cart not found")
+		}
+		return nil, err
+	}
+
+	return heldCartResponseFromRow(cart)
+}
+
+// CheckoutHeld turns a held cart into a sale. Claiming the cart and creating
+// the sale share one transaction, so a failed sale leaves the cart parked.
+func (s *Service) CheckoutHeld(ctx context.Context, userID, id int32, req CheckoutHeldCartRequest) (*SaleResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	cart, err := qtx.DeleteHeldCart(ctx, db.DeleteHeldCartParams{
+		ID:     id,
+		UserID: userID,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("held cart not found")
+		}
+		return nil, err
+	}
+
+	var items []SaleItemRequest
+	if err := json.Unmarshal(cart.Items, &items); err != nil {
+		return nil, err
+	}
+
+	sale, charge, err := s.create(ctx, qtx, userID, CreateSaleRequest{
+		Items:           items,
+		Payments:        req.Payments,
+		PaidAmount:      req.PaidAmount,
+		PaymentMethod:   req.PaymentMethod,
+		Override:        req.Override,
+		VoucherCode:     req.VoucherCode,
+		VoucherCustomer: req.VoucherCustomer,
+		CustomerID:      req.CustomerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	if charge != nil {
+		return s.startCharge(ctx, sale, *charge)
+	}
+	return sale, nil
+}
+
+func heldCartResponseFromRow(cart db.HeldCart) (*HeldCartResponse, error) {
+	var items []SaleItemRequest
+	if err := json.Unmarshal(cart.Items, &items); err != nil {
+		return nil, err
+	}
+
+	var label *string
+	if cart.Label.Valid {
+		label = &cart.Label.String
+	}
+
+	var createdAt string
+	if cart.CreatedAt.Valid {
+		createdAt = cart.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	var expiresAt string
+	if cart.ExpiresAt.Valid {
+		expiresAt = cart.ExpiresAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return &HeldCartResponse{
+		ID:        cart.ID,
+		Label:     label,
+		Items:     items,
+		CreatedAt: createdAt,
+		ExpiresAt: expiresAt,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package sale
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"pos-system/internal/db"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// requestHash fingerprints a sale request so that a reused idempotency key can
+// be told apart from a genuine retry. The key itself and the override
+// credentials are left out of the hash.
+func requestHash(req CreateSaleRequest) (string, error) {
+	req.IdempotencyKey = ""
+	req.Override = nil
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replay returns the sale already recorded under the cashier's key, or nil if
+// the key has not been used yet.
+func (s *Service) replay(ctx context.Context, userID int32, key, hash string) (*SaleResponse, error) {
+	record, err := s.queries.GetSaleIdempotencyKey(ctx, db.GetSaleIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if record.RequestHash != hash {
+		return nil, errors.New("idempotency key already used for a different request")
+	}
+
+	return s.GetByID(ctx, record.SaleID)
+}
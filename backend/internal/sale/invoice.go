@@ -0,0 +1,110 @@
+package sale
+
+import (
+	"context"
+	"fmt"
+	"pos-system/internal/db"
+	"strings"
+	"time"
+)
+
+// Invoice counter reset periods
+const (
+	InvoiceResetDaily   = "daily"
+	InvoiceResetMonthly = "monthly"
+	InvoiceResetYearly  = "yearly"
+	InvoiceResetNever   = "never"
+)
+
+const defaultInvoiceCounterWidth = 5
+
+// returnPrefix starts return numbers in place of the invoice prefix
+const returnPrefix = "RET"
+
+// InvoiceConfig shapes invoice numbers as PREFIX-STORE-DATE-COUNTER, e.g.
+// INV-JKT01-20260115-00042. Empty segments are left out, and the date segment
+// follows the reset period (20260115, 202601, 2026, or none for "never").
+type InvoiceConfig struct {
+	Prefix       string
+	StoreCode    string
+	Reset        string
+	CounterWidth int
+	// Location decides where a day, month or year begins; nil means local time
+	Location *time.Location
+}
+
+// Validate rejects reset periods and counter widths that cannot be honoured.
+func (c InvoiceConfig) Validate() error {
+	switch c.Reset {
+	case "", InvoiceResetDaily, InvoiceResetMonthly, InvoiceResetYearly, InvoiceResetNever:
+	default:
+		return fmt.Errorf("unknown invoice reset period %q", c.Reset)
+	}
+	if c.CounterWidth > 12 {
+		return fmt.Errorf("invoice counter width must be at most 12, got %d", c.CounterWidth)
+	}
+	return nil
+}
+
+// period returns the date segment for t. It also keys the counter, so every
+// new period starts again from 1.
+func (c InvoiceConfig) period(t time.Time) string {
+	if c.Location != nil {
+		t = t.In(c.Location)
+	}
+
+	switch c.Reset {
+	case InvoiceResetNever:
+		return ""
+	case InvoiceResetYearly:
+		return t.Format("2006")
+	case InvoiceResetMonthly:
+		return t.Format("200601")
+	default:
+		return t.Format("20060102")
+	}
+}
+
+func (c InvoiceConfig) format(period string, counter int64) string {
+	width := c.CounterWidth
+	if width <= 0 {
+		width = defaultInvoiceCounterWidth
+	}
+
+	var segments []string
+	for _, segment := range []string{c.Prefix, c.StoreCode, period} {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	segments = append(segments, fmt.Sprintf("%0*d", width, counter))
+
+	return strings.Join(segments, "-")
+}
+
+// nextInvoiceNo takes the next number for the current period. The counter row
+// stays locked until the caller's transaction ends, so concurrent checkouts
+// queue behind each other and a rolled-back sale hands its number back.
+func (s *Service) nextInvoiceNo(ctx context.Context, qtx *db.Queries, now time.Time) (string, error) {
+	return s.cfg.Invoice.next(ctx, qtx, now, "invoice")
+}
+
+// NextReturnNo numbers a return the way nextInvoiceNo numbers a sale, from a
+// counter of its own under the RET prefix, e.g. RET-JKT01-20260115-00003.
+func (s *Service) NextReturnNo(ctx context.Context, qtx *db.Queries, now time.Time) (string, error) {
+	cfg := s.cfg.Invoice
+	cfg.Prefix = returnPrefix
+	return cfg.next(ctx, qtx, now, "return")
+}
+
+// next takes the next number for the current period under c's prefix.
+func (c InvoiceConfig) next(ctx context.Context, qtx *db.Queries, now time.Time, what string) (string, error) {
+	period := c.period(now)
+
+	counter, err := qtx.NextInvoiceCounter(ctx, strings.Join([]string{c.Prefix, c.StoreCode, period}, "|"))
+	if err != nil {
+		return "", fmt.Errorf("failed to allocate %s number: %w", what, err)
+	}
+
+	return c.format(period, counter), nil
+}
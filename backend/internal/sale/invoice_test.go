@@ -0,0 +1,169 @@
+package sale
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"pos-system/internal/db"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestInvoicePeriod(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	// 20:30 UTC on the last day of January is already February in Jakarta
+	at := time.Date(2026, 1, 31, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		reset    string
+		location *time.Location
+		want     string
+	}{
+		{InvoiceResetDaily, time.UTC, "20260131"},
+		{"", time.UTC, "20260131"},
+		{InvoiceResetMonthly, time.UTC, "202601"},
+		{InvoiceResetYearly, time.UTC, "2026"},
+		{InvoiceResetNever, time.UTC, ""},
+		{InvoiceResetDaily, jakarta, "20260201"},
+		{InvoiceResetMonthly, jakarta, "202602"},
+		{InvoiceResetYearly, jakarta, "2026"},
+	}
+
+	for _, tt := range tests {
+		cfg := InvoiceConfig{Reset: tt.reset, Location: tt.location}
+		if got := cfg.period(at); got != tt.want {
+			t.Errorf("period(%s) with reset %q in %s = %q, want %q", at.Format(time.RFC3339), tt.reset, tt.location, got, tt.want)
+		}
+	}
+
+	// The year rolls over at local midnight too
+	newYear := time.Date(2026, 12, 31, 17, 0, 0, 0, time.UTC)
+	if got := (InvoiceConfig{Reset: InvoiceResetYearly, Location: jakarta}).period(newYear); got != "2027" {
+		t.Errorf("yearly period at %s in Jakarta = %q, want 2027", newYear.Format(time.RFC3339), got)
+	}
+}
+
+func TestInvoiceFormat(t *testing.T) {
+	tests := []struct {
+		cfg     InvoiceConfig
+		period  string
+		counter int64
+		want    string
+	}{
+		{InvoiceConfig{Prefix: "INV", StoreCode: "JKT01"}, "20260115", 42, "INV-JKT01-20260115-00042"},
+		{InvoiceConfig{Prefix: "INV"}, "202601", 1, "INV-202601-00001"},
+		{InvoiceConfig{Prefix: "INV", CounterWidth: 8}, "2026", 123, "INV-2026-00000123"},
+		{InvoiceConfig{Prefix: "INV", StoreCode: "BDG"}, "", 7, "INV-BDG-00007"},
+		{InvoiceConfig{}, "", 9, "00009"},
+		// A counter wider than the width is never truncated
+		{InvoiceConfig{Prefix: "INV", CounterWidth: 3}, "20260115", 12345, "INV-20260115-12345"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cfg.format(tt.period, tt.counter); got != tt.want {
+			t.Errorf("format(%q, %d) with %+v = %q, want %q", tt.period, tt.counter, tt.cfg, got, tt.want)
+		}
+	}
+}
+
+// TestConcurrentInvoiceNumbersAreGapFree takes invoice numbers from many
+// transactions at once and rolls some of them back. The committed numbers
+// must be exactly 1..n: a rolled-back transaction hands its number back and
+// no number is given out twice. It runs when TEST_DATABASE_URL points at a
+// migrated Postgres database.
+func TestConcurrentInvoiceNumbersAreGapFree(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	const takers = 30
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Close()
+	queries := db.New(pool)
+
+	cfg := InvoiceConfig{
+		Prefix:    "TST",
+		StoreCode: fmt.Sprintf("S%d", time.Now().UnixNano()),
+		Reset:     InvoiceResetNever,
+	}
+	t.Cleanup(func() {
+		pool.Exec(ctx, "DELETE FROM invoice_counters WHERE scope = $1", strings.Join([]string{cfg.Prefix, cfg.StoreCode, ""}, "|"))
+	})
+	svc := NewService(queries, pool, nil, Config{Invoice: cfg})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var committed []int64
+	start := make(chan struct{})
+	for i := 0; i < takers; i++ {
+		wg.Add(1)
+		go func(rollback bool) {
+			defer wg.Done()
+			<-start
+
+			tx, err := pool.Begin(ctx)
+			if err != nil {
+				t.Errorf("Begin: %v", err)
+				return
+			}
+			defer tx.Rollback(ctx)
+
+			invoiceNo, err := svc.nextInvoiceNo(ctx, queries.WithTx(tx), time.Now())
+			if err != nil {
+				t.Errorf("nextInvoiceNo: %v", err)
+				return
+			}
+			if rollback {
+				return
+			}
+			if err := tx.Commit(ctx); err != nil {
+				t.Errorf("Commit: %v", err)
+				return
+			}
+
+			counter, err := strconv.ParseInt(invoiceNo[strings.LastIndex(invoiceNo, "-")+1:], 10, 64)
+			if err != nil {
+				t.Errorf("invoice number %q: %v", invoiceNo, err)
+				return
+			}
+			mu.Lock()
+			committed = append(committed, counter)
+			mu.Unlock()
+		}(i%3 == 0)
+	}
+	close(start)
+	wg.Wait()
+
+	sort.Slice(committed, func(i, j int) bool { return committed[i] < committed[j] })
+	for i, counter := range committed {
+		if counter != int64(i+1) {
+			t.Fatalf("committed counters %v are not 1..%d", committed, len(committed))
+		}
+	}
+
+	// Numbers keep increasing from where the committed ones left off
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tx.Rollback(ctx)
+	next, err := svc.nextInvoiceNo(ctx, queries.WithTx(tx), time.Now())
+	if err != nil {
+		t.Fatalf("nextInvoiceNo: %v", err)
+	}
+	if want := cfg.format("", int64(len(committed)+1)); next != want {
+		t.Errorf("next invoice number = %q, want %q", next, want)
+	}
+}
@@ -0,0 +1,134 @@
+package sale
+
+import (
+	"context"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// journalBatchSize is how many journal rows are held in memory at a time.
+const journalBatchSize = 1000
+
+// JournalRow is one sold item in the sales journal. Discount is everything
+// taken off the catalogue price: manual, promotion and voucher. Subtotal
+// includes the line's tax.
+type JournalRow struct {
+	InvoiceNo   string
+	CreatedAt   time.Time
+	Cashier     string
+	ProductName string
+	SKU         string
+	Qty         quantity.Quantity
+	// Unit is the unit Qty and Price are in: the packaging unit the item
+	// was sold in, or else the product's unit
+	Unit     string
+	Price    money.Amount
+	Discount money.Amount
+	Tax      money.Amount
+	Subtotal money.Amount
+	Voided   bool
+}
+
+// Journal calls fn for every item of the sales matching f, oldest first,
+// with times in the store's invoice time zone. Rows are read in batches
+// from one snapshot, so a long export sees a consistent set of sales and
+// never holds more than a batch in memory. It stops at the first error fn
+// returns.
+func (s *Service) Journal(ctx context.Context, f ListFilter, fn func(JournalRow) error) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	loc := s.cfg.Invoice.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	count := f.countParams()
+	params := db.ListSaleJournalParams{
+		CreatedFrom:   count.CreatedFrom,
+		CreatedTo:     count.CreatedTo,
+		CashierID:     count.CashierID,
+		PaymentMethod: count.PaymentMethod,
+		InvoicePrefix: count.InvoicePrefix,
+		MinTotal:      count.MinTotal,
+		MaxTotal:      count.MaxTotal,
+		ProductID:     count.ProductID,
+		Limit:         journalBatchSize,
+	}
+
+	for {
+		rows, err := qtx.ListSaleJournal(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		for _, r := range rows {
+			row, err := journalRowFromDB(r, loc)
+			if err != nil {
+				return err
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+
+		if len(rows) < journalBatchSize {
+			return nil
+		}
+		last := rows[len(rows)-1]
+		params.CursorCreatedAt = last.CreatedAt
+		params.CursorSaleID = pgtype.Int4{Int32: last.SaleID, Valid: true}
+		params.CursorItemID = pgtype.Int4{Int32: last.ItemID, Valid: true}
+	}
+}
+
+func journalRowFromDB(r db.ListSaleJournalRow, loc *time.Location) (JournalRow, error) {
+	amounts := make([]money.Amount, 6)
+	for i, n := range []pgtype.Numeric{r.Price, r.Discount, r.PromotionDiscount, r.VoucherDiscount, r.TaxAmount, r.Subtotal} {
+		if !n.Valid {
+			continue
+		}
+		amount, err := money.FromNumeric(n)
+		if err != nil {
+			return JournalRow{}, err
+		}
+		amounts[i] = amount
+	}
+
+	qty, err := quantity.FromNumeric(r.Qty)
+	if err != nil {
+		return JournalRow{}, err
+	}
+
+	row := JournalRow{
+		InvoiceNo:   r.InvoiceNo,
+		Cashier:     r.CashierName.String,
+		ProductName: r.ProductName,
+		SKU:         r.Sku.String,
+		Qty:         qty,
+		Unit:        r.Unit.String,
+		Price:       amounts[0],
+		Discount:    amounts[1] + amounts[2] + amounts[3],
+		Tax:         amounts[4],
+		Subtotal:    amounts[5],
+		Voided:      r.VoidedAt.Valid,
+	}
+	if r.CreatedAt.Valid {
+		row.CreatedAt = r.CreatedAt.Time.In(loc)
+	}
+	return row, nil
+}
@@ -0,0 +1,197 @@
+package sale
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// ListFilter narrows the sales listing. Zero values do not filter.
+type ListFilter struct {
+	// From and To bound created_at; From is inclusive and To exclusive
+	From          *time.Time
+	To            *time.Time
+	CashierID     *int32
+	PaymentMethod string
+	InvoicePrefix string
+	MinTotal      *money.Amount
+	MaxTotal      *money.Amount
+	// ProductID keeps sales with at least one line of the product
+	ProductID *int32
+	// Cursor is the NextCursor of the previous page; when set, Offset is
+	// ignored
+	Cursor string
+	Limit  int32
+	Offset int32
+}
+
+// SalePage is one page of the sales listing. Total counts every sale that
+// matches the filter, and NextCursor is empty on the last page.
+type SalePage struct {
+	Sales      []SaleResponse
+	Total      int64
+	NextCursor string
+}
+
+// encodeCursor makes an opaque cursor from the last sale on a page.
+func encodeCursor(createdAt time.Time, id int32) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(int64(id), 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (time.Time, int32, error) {
+	invalid := errors.New("invalid cursor")
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, invalid
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, 0, invalid
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return time.Time{}, 0, invalid
+	}
+	saleID, err := strconv.ParseInt(id, 10, 32)
+	if err != nil {
+		return time.Time{}, 0, invalid
+	}
+	return createdAt, int32(saleID), nil
+}
+
+// escapeLike quotes the LIKE wildcards in a prefix so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Validate rejects filters that cannot match anything.
+func (f ListFilter) Validate() error {
+	if f.MinTotal != nil && f.MaxTotal != nil && *f.MinTotal > *f.MaxTotal {
+		return errors.New("min_total cannot exceed max_total")
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return errors.New("from must be before to")
+	}
+	return nil
+}
+
+// countParams converts the filter to query arguments. The other listing
+// queries take the same filter columns.
+func (f ListFilter) countParams() db.CountSalesParams {
+	var count db.CountSalesParams
+	if f.From != nil {
+		count.CreatedFrom = pgtype.Timestamptz{Time: *f.From, Valid: true}
+	}
+	if f.To != nil {
+		count.CreatedTo = pgtype.Timestamptz{Time: *f.To, Valid: true}
+	}
+	if f.CashierID != nil {
+		count.CashierID = pgtype.Int4{Int32: *f.CashierID, Valid: true}
+	}
+	if f.PaymentMethod != "" {
+		count.PaymentMethod = pgtype.Text{String: f.PaymentMethod, Valid: true}
+	}
+	if f.InvoicePrefix != "" {
+		count.InvoicePrefix = pgtype.Text{String: escapeLike(f.InvoicePrefix), Valid: true}
+	}
+	if f.MinTotal != nil {
+		count.MinTotal = f.MinTotal.Numeric()
+	}
+	if f.MaxTotal != nil {
+		count.MaxTotal = f.MaxTotal.Numeric()
+	}
+	if f.ProductID != nil {
+		count.ProductID = pgtype.Int4{Int32: *f.ProductID, Valid: true}
+	}
+	return count
+}
+
+// List returns a page of sales, newest first. Pages are keyed on the last
+// sale seen rather than an offset, so sales recorded while paging neither
+// shift rows onto the next page nor repeat them.
+func (s *Service) List(ctx context.Context, f ListFilter) (*SalePage, error) {
+	if err := f.Validate(); err != nil {
+		return nil, err
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+
+	count := f.countParams()
+
+	// One extra row tells whether there is a next page
+	params := db.ListSalesParams{
+		CreatedFrom:   count.CreatedFrom,
+		CreatedTo:     count.CreatedTo,
+		CashierID:     count.CashierID,
+		PaymentMethod: count.PaymentMethod,
+		InvoicePrefix: count.InvoicePrefix,
+		MinTotal:      count.MinTotal,
+		MaxTotal:      count.MaxTotal,
+		ProductID:     count.ProductID,
+		Limit:         limit + 1,
+		Offset:        f.Offset,
+	}
+	if f.Cursor != "" {
+		createdAt, id, err := decodeCursor(f.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		params.CursorCreatedAt = pgtype.Timestamptz{Time: createdAt, Valid: true}
+		params.CursorID = pgtype.Int4{Int32: id, Valid: true}
+		params.Offset = 0
+	}
+	if params.Offset < 0 {
+		params.Offset = 0
+	}
+
+	total, err := s.queries.CountSales(ctx, count)
+	if err != nil {
+		return nil, err
+	}
+
+	sales, err := s.queries.ListSales(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &SalePage{Total: total}
+	if len(sales) > int(limit) {
+		sales = sales[:limit]
+		last := sales[len(sales)-1]
+		if !last.CreatedAt.Valid {
+			return nil, fmt.Errorf("sale %d has no creation time", last.ID)
+		}
+		page.NextCursor = encodeCursor(last.CreatedAt.Time, last.ID)
+	}
+
+	rows := make([]db.GetSaleByIDRow, len(sales))
+	for i, sale := range sales {
+		rows[i] = db.GetSaleByIDRow(sale)
+	}
+	page.Sales, err = saleResponses(ctx, s.queries, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
@@ -0,0 +1,167 @@
+package sale
+
+import (
+	"context"
+	"fmt"
+	"pos-system/internal/db"
+	"pos-system/internal/quantity"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeDB answers the queries behind Service.List from memory and counts the
+// round trips it would have cost.
+type fakeDB struct {
+	sales        int
+	itemsPerSale int
+	queries      int
+}
+
+func queryName(sql string) string {
+	name, _, _ := strings.Cut(strings.TrimPrefix(sql, "-- name: "), " ")
+	return name
+}
+
+func (f *fakeDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	f.queries++
+	return pgconn.CommandTag{}, nil
+}
+
+func (f *fakeDB) Query(_ context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	f.queries++
+	var rows [][]interface{}
+	switch queryName(sql) {
+	case "ListSales":
+		createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < f.sales; i++ {
+			row := make([]interface{}, 22)
+			row[0] = int32(i + 1)
+			row[7] = pgtype.Timestamptz{Time: createdAt.Add(-time.Duration(i) * time.Minute), Valid: true}
+			rows = append(rows, row)
+		}
+	case "GetSaleItemsBySaleIDs":
+		for _, saleID := range args[0].([]int32) {
+			for j := 0; j < f.itemsPerSale; j++ {
+				row := make([]interface{}, 21)
+				row[0] = saleID*100 + int32(j)
+				row[1] = pgtype.Int4{Int32: saleID, Valid: true}
+				row[16] = pgtype.Int4{Int32: 1, Valid: true}
+				row[17] = pgtype.Text{String: "Box", Valid: true}
+				row[18] = quantity.New(12).Numeric()
+				row[19] = "Product"
+				rows = append(rows, row)
+			}
+		}
+	case "GetSaleItemPromotionsBySaleIDs":
+		for _, saleID := range args[0].([]int32) {
+			rows = append(rows, []interface{}{saleID, saleID, saleID * 100, nil, "Promo", nil})
+		}
+	case "GetSalePaymentsBySaleIDs":
+		for _, saleID := range args[0].([]int32) {
+			rows = append(rows, []interface{}{saleID, saleID, "cash", nil, nil})
+		}
+	default:
+		return nil, fmt.Errorf("unexpected query %s", queryName(sql))
+	}
+	return &fakeRows{rows: rows, at: -1}, nil
+}
+
+func (f *fakeDB) QueryRow(_ context.Context, sql string, _ ...interface{}) pgx.Row {
+	f.queries++
+	if queryName(sql) != "CountSales" {
+		return &fakeRows{err: fmt.Errorf("unexpected query %s", queryName(sql))}
+	}
+	return &fakeRows{rows: [][]interface{}{{int64(f.sales)}}}
+}
+
+// fakeRows serves both pgx.Rows and pgx.Row. Nil values leave the
+// destination at its zero value.
+type fakeRows struct {
+	rows [][]interface{}
+	at   int
+	err  error
+}
+
+func (r *fakeRows) Close()                                       {}
+func (r *fakeRows) Err() error                                   { return r.err }
+func (r *fakeRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *fakeRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (r *fakeRows) RawValues() [][]byte                          { return nil }
+func (r *fakeRows) Conn() *pgx.Conn                              { return nil }
+
+func (r *fakeRows) Next() bool {
+	r.at++
+	return r.at < len(r.rows)
+}
+
+func (r *fakeRows) Values() ([]interface{}, error) {
+	return r.rows[r.at], nil
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.at < 0 {
+		r.at = 0
+	}
+	if r.at >= len(r.rows) {
+		return pgx.ErrNoRows
+	}
+	row := r.rows[r.at]
+	if len(row) != len(dest) {
+		return fmt.Errorf("scan into %d values, row has %d", len(dest), len(row))
+	}
+	for i, v := range row {
+		if v != nil {
+			reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(v))
+		}
+	}
+	return nil
+}
+
+// BenchmarkListQueryCount shows that a page of sales costs the same number
+// of queries whatever its size: the count, the page, and one batched query
+// each for items, promotions and payments.
+func BenchmarkListQueryCount(b *testing.B) {
+	const queriesPerPage = 5
+	ctx := context.Background()
+
+	for _, size := range []int{10, 50, 200} {
+		b.Run(fmt.Sprintf("page=%d", size), func(b *testing.B) {
+			fake := &fakeDB{sales: size, itemsPerSale: 3}
+			service := &Service{queries: db.New(fake)}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				page, err := service.List(ctx, ListFilter{Limit: int32(size)})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(page.Sales) != size {
+					b.Fatalf("got %d sales, want %d", len(page.Sales), size)
+				}
+				for _, s := range page.Sales {
+					if len(s.Items) != 3 || len(s.Payments) != 1 || len(s.Items[0].Promotions) != 1 {
+						b.Fatalf("sale %d assembled with %d items, %d payments", s.ID, len(s.Items), len(s.Payments))
+					}
+					if item := s.Items[0]; item.Unit == nil || *item.Unit != "Box" || item.UnitFactor != quantity.New(12) {
+						b.Fatalf("sale %d item %d assembled without its unit", s.ID, item.ID)
+					}
+				}
+			}
+			b.StopTimer()
+
+			if fake.queries != queriesPerPage*b.N {
+				b.Fatalf("%d queries for %d pages of %d sales, want %d per page", fake.queries, b.N, size, queriesPerPage)
+			}
+			b.ReportMetric(float64(fake.queries)/float64(b.N), "queries/op")
+		})
+	}
+}
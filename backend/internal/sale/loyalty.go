@@ -0,0 +1,216 @@
+package sale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PaymentMethodPoints pays with the customer's loyalty points. The tender
+// amount is converted to points at LoyaltyConfig.PointValue.
+const PaymentMethodPoints = "points"
+
+// LoyaltyConfig sets how customers earn and spend points.
+type LoyaltyConfig struct {
+	// SpendPerPoint is how much a customer spends, excluding whatever they
+	// pay with points, to earn one point. Zero turns earning off.
+	SpendPerPoint money.Amount
+	// PointValue is what one point is worth as a tender. Zero turns
+	// redemption off.
+	PointValue money.Amount
+}
+
+func (c LoyaltyConfig) Validate() error {
+	if c.SpendPerPoint < 0 {
+		return errors.New("loyalty spend per point cannot be negative")
+	}
+	if c.PointValue < 0 {
+		return errors.New("loyalty point value cannot be negative")
+	}
+	return nil
+}
+
+// pointsEarned is the whole number of points spend earns.
+func (c LoyaltyConfig) pointsEarned(spend money.Amount) int64 {
+	if c.SpendPerPoint <= 0 || spend <= 0 {
+		return 0
+	}
+	return spend.Cents() / c.SpendPerPoint.Cents()
+}
+
+func isPoints(method string) bool {
+	return strings.EqualFold(method, PaymentMethodPoints)
+}
+
+// pointsTendered works out how many points the points tenders of a sale
+// redeem and how much of the total they cover.
+func (c LoyaltyConfig) pointsTendered(payments []PaymentRequest, customer *db.Customer) (int64, money.Amount, error) {
+	var amount money.Amount
+	for _, p := range payments {
+		if isPoints(p.Method) {
+			amount += p.Amount
+		}
+	}
+	if amount == 0 {
+		return 0, 0, nil
+	}
+
+	if c.PointValue <= 0 {
+		return 0, 0, errors.New("points payments are not enabled")
+	}
+	if customer == nil {
+		return 0, 0, errors.New("points payment requires a customer")
+	}
+	if amount.Cents()%c.PointValue.Cents() != 0 {
+		return 0, 0, fmt.Errorf("points payment must be a multiple of the point value %s", c.PointValue)
+	}
+	return amount.Cents() / c.PointValue.Cents(), amount, nil
+}
+
+// lookupCustomer loads the sale's customer, if one was given.
+func lookupCustomer(ctx context.Context, qtx *db.Queries, id *int32) (*db.Customer, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	customer, err := qtx.GetCustomerByID(ctx, *id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("customer not found")
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// settlePoints takes the redeemed points off the customer's balance and adds
+// the earned ones, recording both in the ledger. The redemption is guarded
+// so two sales cannot spend the same points.
+func settlePoints(ctx context.Context, qtx *db.Queries, customerID, saleID int32, earned, redeemed int64) error {
+	saleIDPg := pgtype.Int4{Int32: saleID, Valid: true}
+
+	if redeemed > 0 {
+		rows, err := qtx.RedeemCustomerPoints(ctx, db.RedeemCustomerPointsParams{
+			Points: redeemed,
+			ID:     customerID,
+		})
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return errors.New("customer has insufficient points")
+		}
+		if _, err := qtx.CreateCustomerPointEntry(ctx, db.CreateCustomerPointEntryParams{
+			CustomerID: customerID,
+			SaleID:     saleIDPg,
+			Type:       "redeem",
+			Points:     -redeemed,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if earned > 0 {
+		if _, err := qtx.AddCustomerPoints(ctx, db.AddCustomerPointsParams{
+			Points: earned,
+			ID:     customerID,
+		}); err != nil {
+			return err
+		}
+		if _, err := qtx.CreateCustomerPointEntry(ctx, db.CreateCustomerPointEntryParams{
+			CustomerID: customerID,
+			SaleID:     saleIDPg,
+			Type:       "earn",
+			Points:     earned,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reversePoints undoes settlePoints for a voided sale: redeemed points go
+// back to the customer and earned ones are taken away.
+func reversePoints(ctx context.Context, qtx *db.Queries, sale db.Sale) error {
+	if !sale.CustomerID.Valid {
+		return nil
+	}
+
+	net := sale.PointsRedeemed - sale.PointsEarned
+	if net == 0 {
+		return nil
+	}
+
+	if _, err := qtx.AddCustomerPoints(ctx, db.AddCustomerPointsParams{
+		Points: net,
+		ID:     sale.CustomerID.Int32,
+	}); err != nil {
+		return err
+	}
+
+	_, err := qtx.CreateCustomerPointEntry(ctx, db.CreateCustomerPointEntryParams{
+		CustomerID: sale.CustomerID.Int32,
+		SaleID:     pgtype.Int4{Int32: sale.ID, Valid: true},
+		Type:       "void",
+		Points:     net,
+	})
+	return err
+}
+
+// pointsReturned is how many of the points a sale of total earned are taken
+// back as its refunds go from before to after. Each share is rounded down, so
+// a return never takes more than it should, and the last refund takes what is
+// left.
+func pointsReturned(earned int64, total, before, after money.Amount) int64 {
+	earnedOn := func(refunded money.Amount) int64 {
+		if refunded >= total {
+			return earned
+		}
+		share := new(big.Int).Mul(big.NewInt(earned), big.NewInt(refunded.Cents()))
+		return share.Div(share, big.NewInt(total.Cents())).Int64()
+	}
+	return earnedOn(after) - earnedOn(before)
+}
+
+// ReturnPoints takes back the points a sale earned on what a return refunds.
+// before and after are the sale's total refunds without and with the
+// return; working from the running total keeps the points taken back over
+// several returns adding up to exactly what the sale earned once all of it
+// is refunded.
+func ReturnPoints(ctx context.Context, qtx *db.Queries, sale db.Sale, before, after money.Amount) error {
+	if !sale.CustomerID.Valid || sale.PointsEarned == 0 {
+		return nil
+	}
+	total, err := money.FromNumeric(sale.TotalAmount)
+	if err != nil || total <= 0 {
+		return err
+	}
+
+	points := pointsReturned(sale.PointsEarned, total, before, after)
+	if points <= 0 {
+		return nil
+	}
+
+	if _, err := qtx.AddCustomerPoints(ctx, db.AddCustomerPointsParams{
+		Points: -points,
+		ID:     sale.CustomerID.Int32,
+	}); err != nil {
+		return err
+	}
+
+	_, err = qtx.CreateCustomerPointEntry(ctx, db.CreateCustomerPointEntryParams{
+		CustomerID: sale.CustomerID.Int32,
+		SaleID:     pgtype.Int4{Int32: sale.ID, Valid: true},
+		Type:       "return",
+		Points:     -points,
+	})
+	return err
+}
@@ -0,0 +1,45 @@
+package sale
+
+import (
+	"pos-system/internal/money"
+	"testing"
+)
+
+func TestPointsReturned(t *testing.T) {
+	total := money.New(100000)
+
+	tests := []struct {
+		name          string
+		earned        int64
+		before, after string
+		want          int64
+	}{
+		{"whole sale", 10, "0", "100000", 10},
+		{"half the sale", 10, "0", "50000", 5},
+		{"share is rounded down", 10, "0", "19999", 1},
+		{"second half", 10, "50000", "100000", 5},
+		{"too small to take a point", 10, "0", "9999.99", 0},
+		{"last refund takes what is left", 3, "66666.67", "100000", 1},
+		{"nothing refunded", 10, "20000", "20000", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pointsReturned(tt.earned, total, money.MustParse(tt.before), money.MustParse(tt.after))
+			if got != tt.want {
+				t.Errorf("pointsReturned(%d, %s, %s, %s) = %d, want %d", tt.earned, total, tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+
+	// Returns of a third each give back exactly what the sale earned
+	var returned int64
+	refunded := money.Amount(0)
+	for _, part := range total.Allocate([]money.Amount{1, 1, 1}) {
+		returned += pointsReturned(7, total, refunded, refunded+part)
+		refunded += part
+	}
+	if returned != 7 {
+		t.Errorf("three returns of a third took back %d points, want 7", returned)
+	}
+}
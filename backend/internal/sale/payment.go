@@ -0,0 +1,629 @@
+package sale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"pos-system/internal/db"
+	"pos-system/internal/giftcard"
+	"pos-system/internal/money"
+	"pos-system/internal/payment"
+	"pos-system/internal/voucher"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Sales paid with a QRIS or e-wallet tender wait in PaymentStatusPending
+// until the payment provider reports on the charge. Everything but the stock
+// is settled when the sale is created; stock is taken only once the charge is
+// paid, and a failed charge voids the sale and gives back its voucher, points
+// and gift card payments.
+const (
+	PaymentStatusPending = "pending"
+	PaymentStatusPaid    = "paid"
+	PaymentStatusFailed  = "failed"
+)
+
+// PaymentChargeResponse is what the customer pays a pending sale with: the
+// QR string to render or the e-wallet checkout URL.
+type PaymentChargeResponse struct {
+	Provider    string  `json:"provider"`
+	Method      string  `json:"method"`
+	Reference   string  `json:"reference"`
+	Amount      string  `json:"amount"`
+	Status      string  `json:"status"`
+	QRString    *string `json:"qr_string"`
+	CheckoutURL *string `json:"checkout_url"`
+	ExpiresAt   *string `json:"expires_at"`
+	PaidAt      *string `json:"paid_at"`
+}
+
+// providerTender returns the index of the tender to charge through the
+// payment provider, or -1 when there is none. Without a provider QRIS and
+// e-wallet tenders are recorded as already paid, like any other.
+func (s *Service) providerTender(payments []PaymentRequest) (int, error) {
+	at := -1
+	if s.cfg.Payments == nil {
+		return at, nil
+	}
+	for i, p := range payments {
+		if !payment.IsProviderMethod(p.Method) {
+			continue
+		}
+		if at >= 0 {
+			return -1, errors.New("only one qris or e-wallet payment is allowed per sale")
+		}
+		at = i
+	}
+	return at, nil
+}
+
+// pendingCharge is the provider tender of a sale that create left pending.
+type pendingCharge struct {
+	Method string
+	Amount money.Amount
+}
+
+// startCharge asks the provider to collect the pending tender of a committed
+// sale and records the charge. The provider is called with no transaction
+// open, so the sale's invoice counter, stock and shift rows are not held
+// while it answers. If the provider turns the charge down the sale fails and
+// its idempotency key is freed, so the cashier can simply try again. A charge
+// that is created but cannot be recorded leaves the sale pending until
+// SweepPayments fails it.
+func (s *Service) startCharge(ctx context.Context, sale *SaleResponse, pending pendingCharge) (*SaleResponse, error) {
+	expiresAt := time.Now().Add(s.cfg.ChargeTTL)
+	charge, err := s.cfg.Payments.CreateCharge(ctx, payment.ChargeRequest{
+		OrderID:   sale.InvoiceNo,
+		Method:    strings.ToLower(pending.Method),
+		Amount:    pending.Amount,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		if err := s.abandonSale(ctx, sale.ID); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("payment provider: %w", err)
+	}
+	if !charge.ExpiresAt.IsZero() {
+		expiresAt = charge.ExpiresAt
+	}
+
+	var qrString, checkoutURL pgtype.Text
+	if charge.QRString != "" {
+		qrString = pgtype.Text{String: charge.QRString, Valid: true}
+	}
+	if charge.CheckoutURL != "" {
+		checkoutURL = pgtype.Text{String: charge.CheckoutURL, Valid: true}
+	}
+
+	row, err := s.queries.CreatePaymentCharge(ctx, db.CreatePaymentChargeParams{
+		SaleID:      sale.ID,
+		Provider:    s.cfg.Payments.Name(),
+		Method:      strings.ToLower(pending.Method),
+		Reference:   charge.Reference,
+		Amount:      pending.Amount.Numeric(),
+		QrString:    qrString,
+		CheckoutUrl: checkoutURL,
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	sale.Payment = paymentChargeResponseFromRow(row)
+	return sale, nil
+}
+
+// abandonSale fails a pending sale whose charge could not be started.
+func (s *Service) abandonSale(ctx context.Context, saleID int32) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+	if err := failSale(ctx, qtx, saleID, "payment could not be started"); err != nil {
+		return err
+	}
+	if err := qtx.DeleteSaleIdempotencyKeyBySale(ctx, saleID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// provider returns the configured provider if it is the one charge was
+// made with.
+func (s *Service) provider(charge db.PaymentCharge) (payment.Provider, error) {
+	if s.cfg.Payments == nil || s.cfg.Payments.Name() != charge.Provider {
+		return nil, fmt.Errorf("payment provider %s is not configured", charge.Provider)
+	}
+	return s.cfg.Payments, nil
+}
+
+// HandleCallback applies a signed callback from the named provider.
+func (s *Service) HandleCallback(ctx context.Context, providerName string, header http.Header, body []byte) (*PaymentChargeResponse, error) {
+	if s.cfg.Payments == nil || s.cfg.Payments.Name() != providerName {
+		return nil, errors.New("unknown payment provider")
+	}
+
+	reported, err := s.cfg.Payments.VerifyCallback(header, body)
+	if err != nil {
+		return nil, err
+	}
+	return s.applyCharge(ctx, reported)
+}
+
+// SettleMock ends a charge of the mock provider through the same signed
+// callback path a real provider would use. It is for development only.
+func (s *Service) SettleMock(ctx context.Context, reference, status string) (*PaymentChargeResponse, error) {
+	mock, ok := s.cfg.Payments.(*payment.Mock)
+	if !ok {
+		return nil, errors.New("mock payment provider is not enabled")
+	}
+
+	body, signature, err := mock.Settle(reference, status)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	header.Set(payment.SignatureHeader, signature)
+	return s.HandleCallback(ctx, mock.Name(), header, body)
+}
+
+// Payment returns the charge of a sale. While it is pending the provider is
+// asked for its status, so clients can poll this instead of waiting for the
+// callback.
+func (s *Service) Payment(ctx context.Context, saleID int32) (*PaymentChargeResponse, error) {
+	charge, err := s.saleCharge(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if charge.Status != payment.StatusPending {
+		return paymentChargeResponseFromRow(charge), nil
+	}
+
+	provider, err := s.provider(charge)
+	if err != nil {
+		return nil, err
+	}
+	reported, err := provider.Status(ctx, charge.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("payment provider: %w", err)
+	}
+	if reported.Status == payment.StatusPending {
+		return paymentChargeResponseFromRow(charge), nil
+	}
+	return s.applyCharge(ctx, reported)
+}
+
+// CancelPayment stops waiting for a pending sale's charge and voids the sale.
+// If the customer has paid in the meantime the sale is completed instead.
+func (s *Service) CancelPayment(ctx context.Context, saleID int32) (*SaleResponse, error) {
+	charge, err := s.saleCharge(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if charge.Status != payment.StatusPending {
+		return nil, errors.New("sale is not awaiting payment")
+	}
+
+	provider, err := s.provider(charge)
+	if err != nil {
+		return nil, err
+	}
+	reported, err := provider.Status(ctx, charge.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("payment provider: %w", err)
+	}
+	if reported.Status == payment.StatusPending {
+		reported.Status = payment.StatusCancelled
+	}
+
+	if _, err := s.applyCharge(ctx, reported); err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, saleID)
+}
+
+func (s *Service) saleCharge(ctx context.Context, saleID int32) (db.PaymentCharge, error) {
+	charge, err := s.queries.GetPaymentChargeBySale(ctx, saleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return charge, errors.New("sale has no payment charge")
+		}
+		return charge, err
+	}
+	return charge, nil
+}
+
+// applyCharge brings a charge and its sale in line with what the provider
+// reported, in one transaction. Reports that change nothing, such as a
+// repeated callback, are accepted and ignored.
+func (s *Service) applyCharge(ctx context.Context, reported payment.Charge) (*PaymentChargeResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	// The row lock serializes a callback with a concurrent poll or cancel
+	charge, err := qtx.GetPaymentChargeByReferenceForUpdate(ctx, db.GetPaymentChargeByReferenceForUpdateParams{
+		Provider:  s.cfg.Payments.Name(),
+		Reference: reported.Reference,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("payment charge not found")
+		}
+		return nil, err
+	}
+
+	charge, err = settleCharge(ctx, qtx, charge, reported)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	// A payment that arrived for a voided sale is paid back now that the
+	// sale and stock rows are unlocked. If the provider turns the refund down
+	// the charge stays paid and SweepPayments tries again.
+	if charge.Status == payment.StatusPaid {
+		if refunded, err := s.refundSaleCharge(ctx, charge.SaleID); err == nil && refunded != nil {
+			charge = *refunded
+		}
+	}
+	return paymentChargeResponseFromRow(charge), nil
+}
+
+// settleCharge records what the provider reported about charge. A charge
+// paid for a sale that has been given up on is recorded as paid, leaving the
+// refund to the caller once its transaction has committed.
+func settleCharge(ctx context.Context, qtx *db.Queries, charge db.PaymentCharge, reported payment.Charge) (db.PaymentCharge, error) {
+	if reported.Status == charge.Status {
+		return charge, nil
+	}
+
+	amount, err := money.FromNumeric(charge.Amount)
+	if err != nil {
+		return charge, err
+	}
+	paidAt := reported.PaidAt
+	if reported.Status == payment.StatusPaid && paidAt.IsZero() {
+		paidAt = time.Now()
+	}
+
+	pending := charge.Status == payment.StatusPending
+	switch reported.Status {
+	case payment.StatusPaid:
+		if reported.Amount != amount {
+			return charge, fmt.Errorf("payment amount %s does not match the charge amount %s", reported.Amount, amount)
+		}
+		if !pending {
+			// The customer paid after the sale was given up on
+			return setChargeStatus(ctx, qtx, charge, payment.StatusPaid, paidAt)
+		}
+
+		complete, err := completeSale(ctx, qtx, charge.SaleID)
+		if err != nil {
+			return charge, err
+		}
+		if !complete {
+			if err := failSale(ctx, qtx, charge.SaleID, "stock ran out before payment"); err != nil {
+				return charge, err
+			}
+		}
+		return setChargeStatus(ctx, qtx, charge, payment.StatusPaid, paidAt)
+
+	case payment.StatusFailed, payment.StatusExpired, payment.StatusCancelled:
+		if !pending {
+			return charge, nil
+		}
+		if err := failSale(ctx, qtx, charge.SaleID, "payment "+reported.Status); err != nil {
+			return charge, err
+		}
+		return setChargeStatus(ctx, qtx, charge, reported.Status, time.Time{})
+
+	case payment.StatusRefunded:
+		// Refunded at the provider, e.g. from its dashboard
+		if charge.Status != payment.StatusPaid {
+			return charge, nil
+		}
+		return setChargeStatus(ctx, qtx, charge, payment.StatusRefunded, time.Time{})
+
+	default:
+		return charge, nil
+	}
+}
+
+// refundSaleCharge pays back the provider charge of a voided sale, if it has
+// a paid one, and returns the charge as refunded. Only the charge row is
+// locked while the provider is called, so a concurrent callback or sweep
+// waits for the outcome instead of refunding a second time. The charge is
+// marked refunded only once the provider has accepted the refund.
+func (s *Service) refundSaleCharge(ctx context.Context, saleID int32) (*db.PaymentCharge, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	charge, err := qtx.GetPaymentChargeBySaleForUpdate(ctx, saleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if charge.Status != payment.StatusPaid {
+		return nil, nil
+	}
+	sale, err := qtx.GetSaleByID(ctx, saleID)
+	if err != nil {
+		return nil, err
+	}
+	if !sale.VoidedAt.Valid {
+		return nil, nil
+	}
+
+	amount, err := money.FromNumeric(charge.Amount)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := s.provider(charge)
+	if err != nil {
+		return nil, err
+	}
+	if err := provider.Refund(ctx, charge.Reference, amount); err != nil {
+		return nil, fmt.Errorf("payment provider: %w", err)
+	}
+
+	charge, err = setChargeStatus(ctx, qtx, charge, payment.StatusRefunded, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &charge, nil
+}
+
+// RefundReturn pays amount of a return back through the provider when the
+// sale was charged through it and the return refunds to the same method.
+// Earlier returns refunded that way count against the charge, so together
+// they never pay back more than was charged. It is called with the sale row
+// locked and before the return is written, so a refund the provider turns
+// down records no return. Other refund methods are paid out at the till and
+// need nothing here.
+func (s *Service) RefundReturn(ctx context.Context, qtx *db.Queries, saleID int32, method string, amount money.Amount) error {
+	if !payment.IsProviderMethod(method) || amount <= 0 {
+		return nil
+	}
+
+	charge, err := qtx.GetPaymentChargeBySale(ctx, saleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	if charge.Status != payment.StatusPaid || !strings.EqualFold(charge.Method, method) {
+		return nil
+	}
+
+	left, err := money.FromNumeric(charge.Amount)
+	if err != nil {
+		return err
+	}
+	returns, err := qtx.ListSaleReturnsBySale(ctx, saleID)
+	if err != nil {
+		return err
+	}
+	for _, ret := range returns {
+		if !strings.EqualFold(ret.RefundMethod.String, charge.Method) {
+			continue
+		}
+		refunded, err := money.FromNumeric(ret.RefundAmount)
+		if err != nil {
+			return err
+		}
+		left -= refunded
+	}
+	if amount > left {
+		return fmt.Errorf("refund amount %s exceeds the %s left on the %s payment", amount, left, charge.Method)
+	}
+
+	provider, err := s.provider(charge)
+	if err != nil {
+		return err
+	}
+	if err := provider.Refund(ctx, charge.Reference, amount); err != nil {
+		return fmt.Errorf("payment provider: %w", err)
+	}
+	return nil
+}
+
+// SweepPayments settles what no callback will. Pending sales whose charge
+// has expired are failed, unless the provider reports the charge paid after
+// all, and so are sales whose charge was never recorded within ChargeTTL.
+// Either way their voucher, points and gift card payments are given back.
+// Paid charges of voided sales are refunded again, for when the provider
+// turned the refund down before. It is meant to run every minute or so.
+func (s *Service) SweepPayments(ctx context.Context) error {
+	if s.cfg.Payments == nil {
+		return nil
+	}
+
+	now := time.Now()
+	overdue, err := s.queries.ListOverduePendingSales(ctx, db.ListOverduePendingSalesParams{
+		Now:           pgtype.Timestamptz{Time: now, Valid: true},
+		StartedBefore: pgtype.Timestamptz{Time: now.Add(-s.cfg.ChargeTTL), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, sale := range overdue {
+		if err := s.expireSale(ctx, sale); err != nil {
+			errs = append(errs, fmt.Errorf("sale %d: %w", sale.SaleID, err))
+		}
+	}
+
+	unrefunded, err := s.queries.ListUnrefundedCharges(ctx)
+	if err != nil {
+		return err
+	}
+	for _, charge := range unrefunded {
+		if _, err := s.refundSaleCharge(ctx, charge.SaleID); err != nil {
+			errs = append(errs, fmt.Errorf("sale %d: %w", charge.SaleID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// expireSale fails an overdue pending sale.
+func (s *Service) expireSale(ctx context.Context, sale db.ListOverduePendingSalesRow) error {
+	if !sale.Reference.Valid {
+		tx, err := s.db.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback(ctx)
+
+		if err := failSale(ctx, s.queries.WithTx(tx), sale.SaleID, "payment expired"); err != nil {
+			return err
+		}
+		return tx.Commit(ctx)
+	}
+
+	// The customer may have paid just before the charge expired with the
+	// callback lost on the way
+	reported := payment.Charge{Reference: sale.Reference.String, Status: payment.StatusExpired}
+	if current, err := s.cfg.Payments.Status(ctx, sale.Reference.String); err == nil && current.Status != payment.StatusPending {
+		reported = current
+	}
+	_, err := s.applyCharge(ctx, reported)
+	return err
+}
+
+func setChargeStatus(ctx context.Context, qtx *db.Queries, charge db.PaymentCharge, status string, paidAt time.Time) (db.PaymentCharge, error) {
+	var paidAtPg pgtype.Timestamptz
+	if !paidAt.IsZero() {
+		paidAtPg = pgtype.Timestamptz{Time: paidAt, Valid: true}
+	}
+	return qtx.UpdatePaymentChargeStatus(ctx, db.UpdatePaymentChargeStatusParams{
+		Status: status,
+		PaidAt: paidAtPg,
+		ID:     charge.ID,
+	})
+}
+
+// completeSale takes the stock of a paid sale, marks it paid and closes the
+// table order it settles, if any. It reports false, changing nothing, when an
+// item has run out since the sale was rung up.
+func completeSale(ctx context.Context, qtx *db.Queries, saleID int32) (bool, error) {
+	sale, err := qtx.GetSaleForUpdate(ctx, saleID)
+	if err != nil {
+		return false, err
+	}
+	if sale.PaymentStatus != PaymentStatusPending {
+		return false, errors.New("sale is not awaiting payment")
+	}
+
+	items, err := qtx.GetSaleItemsBySaleID(ctx, pgtype.Int4{Int32: saleID, Valid: true})
+	if err != nil {
+		return false, err
+	}
+	needs := make([]stockNeed, len(items))
+	for i, item := range items {
+		qty, err := itemStockQty(item.Qty, item.UnitFactor)
+		if err != nil {
+			return false, err
+		}
+		needs[i] = stockNeed{ProductID: item.ProductID.Int32, Name: item.ProductName, Qty: qty}
+	}
+	if err := deductStock(ctx, qtx, needs); err != nil {
+		if isStockShortage(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	if _, err := qtx.CompletePendingSale(ctx, saleID); err != nil {
+		return false, err
+	}
+	// A table order waiting on this payment is settled with it
+	if err := qtx.SettleTableOrderBySale(ctx, pgtype.Int4{Int32: saleID, Valid: true}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// failSale voids a sale whose payment did not go through. Its stock was
+// never taken, so only the voucher, points and gift cards are given back, and
+// a table order it was settling is freed for another try.
+func failSale(ctx context.Context, qtx *db.Queries, saleID int32, reason string) error {
+	sale, err := qtx.FailPendingSale(ctx, db.FailPendingSaleParams{
+		ID:         saleID,
+		VoidReason: pgtype.Text{String: reason, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("sale is not awaiting payment")
+		}
+		return err
+	}
+	// A table order waiting on this payment can be settled again
+	if err := qtx.ReleaseTableOrderSale(ctx, pgtype.Int4{Int32: saleID, Valid: true}); err != nil {
+		return err
+	}
+	return releaseSale(ctx, qtx, sale, sale.UserID.Int32)
+}
+
+// releaseSale gives back what a voided sale took besides stock: the voucher
+// use, loyalty points and gift card balances.
+func releaseSale(ctx context.Context, qtx *db.Queries, sale db.Sale, userID int32) error {
+	if err := voucher.Release(ctx, qtx, sale.ID); err != nil {
+		return err
+	}
+	if err := reversePoints(ctx, qtx, sale); err != nil {
+		return err
+	}
+	return giftcard.ReverseSale(ctx, qtx, sale.ID, userID)
+}
+
+func paymentChargeResponseFromRow(c db.PaymentCharge) *PaymentChargeResponse {
+	resp := &PaymentChargeResponse{
+		Provider:  c.Provider,
+		Method:    c.Method,
+		Reference: c.Reference,
+		Amount:    numericToString(c.Amount),
+		Status:    c.Status,
+	}
+	if c.QrString.Valid {
+		resp.QRString = &c.QrString.String
+	}
+	if c.CheckoutUrl.Valid {
+		resp.CheckoutURL = &c.CheckoutUrl.String
+	}
+	if c.ExpiresAt.Valid {
+		v := c.ExpiresAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		resp.ExpiresAt = &v
+	}
+	if c.PaidAt.Valid {
+		v := c.PaidAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		resp.PaidAt = &v
+	}
+	return resp
+}
@@ -0,0 +1,290 @@
+package sale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/promotion"
+	"pos-system/internal/quantity"
+	"pos-system/internal/tax"
+	"pos-system/internal/voucher"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PriceOverrideApproval is an admin's credential entered at the till to
+// authorise unit prices or discounts that differ from the catalogue.
+type PriceOverrideApproval struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// pricedLine is a sale line after the server has priced, discounted and
+// taxed it. Subtotal is what the customer pays for the line, so it is net of
+// Discount, PromotionDiscount and VoucherDiscount and includes Tax whether
+// the rate is inclusive or exclusive. A line sold in a packaging unit has
+// Qty and Price per unit; UnitFactor is the base units in one, 1 otherwise.
+type pricedLine struct {
+	Product           db.GetProductByIDRow
+	Qty               quantity.Quantity
+	UnitID            pgtype.Int4
+	UnitName          pgtype.Text
+	UnitFactor        quantity.Quantity
+	ListPrice         money.Amount
+	Price             money.Amount
+	Discount          money.Amount
+	Subtotal          money.Amount
+	Overridden        bool
+	TaxRateID         pgtype.Int4
+	TaxRate           money.Amount
+	TaxInclusive      bool
+	Tax               money.Amount
+	PromotionDiscount money.Amount
+	Promotions        []promotion.Discount
+	VoucherDiscount   money.Amount
+}
+
+// baseQty is the line's quantity in the product's base unit, the unit
+// inventory is counted in.
+func (l pricedLine) baseQty() quantity.Quantity {
+	return l.Qty.Mul(l.UnitFactor)
+}
+
+// pricedSale is a cart after priceSale. Voucher is nil when no code was
+// given.
+type pricedSale struct {
+	Lines           []pricedLine
+	Voucher         *db.Voucher
+	VoucherDiscount money.Amount
+}
+
+// priceSale prices the cart, applies active promotions and then the voucher
+// code, if any, and finally charges tax on what is left. With lockVoucher set
+// the voucher stays locked until qtx's transaction ends so it can be redeemed
+// in it.
+func (s *Service) priceSale(ctx context.Context, qtx *db.Queries, req CreateSaleRequest, lockVoucher bool) (*pricedSale, error) {
+	lines, err := s.priceLines(ctx, qtx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyPromotions(ctx, qtx, lines); err != nil {
+		return nil, err
+	}
+
+	priced := &pricedSale{Lines: lines}
+	if strings.TrimSpace(req.VoucherCode) != "" {
+		if err := applyVoucher(ctx, qtx, priced, req.VoucherCode, req.VoucherCustomer, lockVoucher); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range lines {
+		if err := s.applyTax(ctx, qtx, &lines[i]); err != nil {
+			return nil, err
+		}
+	}
+	return priced, nil
+}
+
+// priceLines prices every requested line from products.price, or from the
+// unit's price when the line is sold in a packaging unit. A client price of
+// zero means "use the list price"; any other price that differs from the list
+// price, and any manual discount, is an override that needs an admin's
+// approval (see approveOverrides).
+func (s *Service) priceLines(ctx context.Context, qtx *db.Queries, items []SaleItemRequest) ([]pricedLine, error) {
+	if len(items) == 0 {
+		return nil, errors.New("sale must contain at least one item")
+	}
+
+	lines := make([]pricedLine, len(items))
+	for i, item := range items {
+		if item.Qty <= 0 {
+			return nil, errors.New("item qty must be greater than zero")
+		}
+		if item.Price < 0 || item.Discount < 0 {
+			return nil, errors.New("item price and discount cannot be negative")
+		}
+
+		product, err := qtx.GetProductByID(ctx, item.ProductID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, fmt.Errorf("product %d not found", item.ProductID)
+			}
+			return nil, err
+		}
+
+		listPrice, err := money.FromNumeric(product.Price)
+		if err != nil {
+			return nil, err
+		}
+
+		line := pricedLine{Product: product, Qty: item.Qty, UnitFactor: quantity.New(1)}
+		if item.UnitID != nil {
+			unit, err := qtx.GetProductUnit(ctx, db.GetProductUnitParams{ID: *item.UnitID, ProductID: product.ID})
+			if err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil, fmt.Errorf("item unit %d not found for product: %s", *item.UnitID, product.Name)
+				}
+				return nil, err
+			}
+			if line.UnitFactor, err = quantity.FromNumeric(unit.Factor); err != nil {
+				return nil, err
+			}
+			line.UnitID = pgtype.Int4{Int32: unit.ID, Valid: true}
+			line.UnitName = pgtype.Text{String: unit.Name, Valid: true}
+
+			// Without a price of its own the unit costs its base units
+			listPrice = line.UnitFactor.Cost(listPrice)
+			if unit.Price.Valid {
+				if listPrice, err = money.FromNumeric(unit.Price); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		// Precision applies to the base units the line takes from stock
+		if base := line.baseQty(); !base.Fits(int(product.QtyPrecision)) {
+			if line.UnitID.Valid {
+				return nil, fmt.Errorf("item qty %s %s for product: %s is %s base units, which allows at most %d decimal places", item.Qty, line.UnitName.String, product.Name, base, product.QtyPrecision)
+			}
+			return nil, fmt.Errorf("item qty %s for product: %s allows at most %d decimal places", item.Qty, product.Name, product.QtyPrecision)
+		}
+
+		price := listPrice
+		overridden := false
+		if item.Price != 0 && item.Price != listPrice {
+			price = item.Price
+			overridden = true
+		}
+		if item.Discount != 0 {
+			overridden = true
+		}
+
+		gross := item.Qty.Cost(price)
+		if item.Discount > gross {
+			return nil, fmt.Errorf("item discount cannot exceed line total for product: %s", product.Name)
+		}
+
+		line.ListPrice = listPrice
+		line.Price = price
+		line.Discount = item.Discount
+		line.Subtotal = gross - item.Discount
+		line.Overridden = overridden
+		lines[i] = line
+	}
+
+	return lines, nil
+}
+
+// approveOverrides checks the admin approval needed when any line is
+// overridden and returns the approving admin so it can be stored per line.
+func (s *Service) approveOverrides(ctx context.Context, lines []pricedLine, override *PriceOverrideApproval) (pgtype.Int4, error) {
+	var approvedBy pgtype.Int4
+
+	needsApproval := false
+	for _, line := range lines {
+		needsApproval = needsApproval || line.Overridden
+	}
+	if !needsApproval {
+		return approvedBy, nil
+	}
+
+	if override == nil {
+		return approvedBy, errors.New("price override requires admin approval")
+	}
+
+	approver, err := s.auth.VerifyAdmin(ctx, override.Username, override.Password)
+	if err != nil {
+		return approvedBy, fmt.Errorf("price override approval failed: %s", err.Error())
+	}
+	return pgtype.Int4{Int32: approver.ID, Valid: true}, nil
+}
+
+// applyPromotions takes the best combination of active promotions off the
+// lines' subtotals, before tax.
+func applyPromotions(ctx context.Context, qtx *db.Queries, lines []pricedLine) error {
+	rules, err := promotion.ActiveRules(ctx, qtx, time.Now())
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	// Promotions count and price base units, so a carton counts as the
+	// bottles in it
+	cart := make([]promotion.Line, len(lines))
+	for i, line := range lines {
+		cart[i] = promotion.Line{
+			ProductID:  line.Product.ID,
+			CategoryID: line.Product.CategoryID.Int32,
+			Qty:        line.baseQty(),
+			UnitPrice:  line.UnitFactor.PerUnit(line.Price),
+			Amount:     line.Subtotal,
+		}
+	}
+
+	for _, d := range promotion.Apply(rules, cart) {
+		line := &lines[d.Line]
+		line.PromotionDiscount += d.Amount
+		line.Subtotal -= d.Amount
+		line.Promotions = append(line.Promotions, d)
+	}
+	return nil
+}
+
+// applyVoucher takes the voucher's discount off the cart as it stands after
+// promotions, spread over the lines in proportion to their subtotals so each
+// line is taxed on what the customer actually pays for it.
+func applyVoucher(ctx context.Context, qtx *db.Queries, priced *pricedSale, code, customer string, lock bool) error {
+	weights := make([]money.Amount, len(priced.Lines))
+	var cartTotal money.Amount
+	for i, line := range priced.Lines {
+		weights[i] = line.Subtotal
+		cartTotal += line.Subtotal
+	}
+
+	v, discount, err := voucher.Check(ctx, qtx, code, customer, cartTotal, time.Now(), lock)
+	if err != nil {
+		return err
+	}
+
+	for i, share := range discount.Allocate(weights) {
+		priced.Lines[i].VoucherDiscount = share
+		priced.Lines[i].Subtotal -= share
+	}
+	priced.Voucher = &v
+	priced.VoucherDiscount = discount
+	return nil
+}
+
+// applyTax charges the line at its product's tax rate, if it has one.
+// Exclusive tax is added to the subtotal; inclusive tax is already in it.
+func (s *Service) applyTax(ctx context.Context, qtx *db.Queries, line *pricedLine) error {
+	rate, err := qtx.GetProductTaxRate(ctx, line.Product.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	percent, err := money.FromNumeric(rate.Rate)
+	if err != nil {
+		return err
+	}
+
+	line.TaxRateID = pgtype.Int4{Int32: rate.ID, Valid: true}
+	line.TaxRate = percent
+	line.TaxInclusive = rate.Inclusive
+	line.Tax = tax.Calculate(line.Subtotal, percent, rate.Inclusive)
+	if !rate.Inclusive {
+		line.Subtotal += line.Tax
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package sale
+
+import (
+	"errors"
+	"pos-system/internal/money"
+)
+
+const (
+	RoundNearest = "nearest"
+	RoundUp      = "up"
+	RoundDown    = "down"
+)
+
+// CashRounding rounds what is paid in cash to the smallest amount the store
+// can give change in, e.g. Rp100 or Rp500. Non-cash tenders are never
+// rounded.
+type CashRounding struct {
+	// Increment is the amount cash is rounded to. Zero turns rounding off.
+	Increment money.Amount
+	// Mode is nearest, up or down. Nearest rounds halves up.
+	Mode string
+}
+
+func (r CashRounding) Validate() error {
+	if r.Increment < 0 {
+		return errors.New("cash rounding increment cannot be negative")
+	}
+	switch r.Mode {
+	case RoundNearest, RoundUp, RoundDown:
+		return nil
+	default:
+		return errors.New("cash rounding mode must be nearest, up or down")
+	}
+}
+
+// round rounds a positive amount to the increment.
+func (r CashRounding) round(amount money.Amount) money.Amount {
+	if r.Increment <= 0 {
+		return amount
+	}
+
+	remainder := amount % r.Increment
+	if remainder == 0 {
+		return amount
+	}
+	down := amount - remainder
+	switch r.Mode {
+	case RoundUp:
+		return down + r.Increment
+	case RoundDown:
+		return down
+	default:
+		if remainder*2 >= r.Increment {
+			return down + r.Increment
+		}
+		return down
+	}
+}
@@ -0,0 +1,135 @@
+package sale
+
+import (
+	"pos-system/internal/money"
+	"reflect"
+	"testing"
+)
+
+func TestCashRoundingRound(t *testing.T) {
+	tests := []struct {
+		increment money.Amount
+		mode      string
+		amount    string
+		want      string
+	}{
+		// Rp100
+		{money.New(100), RoundNearest, "15049", "15000"},
+		{money.New(100), RoundNearest, "15050", "15100"},
+		{money.New(100), RoundNearest, "15049.99", "15000"},
+		{money.New(100), RoundNearest, "15100", "15100"},
+		{money.New(100), RoundUp, "15001", "15100"},
+		{money.New(100), RoundUp, "15000.01", "15100"},
+		{money.New(100), RoundUp, "15100", "15100"},
+		{money.New(100), RoundDown, "15099.99", "15000"},
+		{money.New(100), RoundDown, "15000", "15000"},
+		// Rp500
+		{money.New(500), RoundNearest, "12249", "12000"},
+		{money.New(500), RoundNearest, "12250", "12500"},
+		{money.New(500), RoundNearest, "12750", "13000"},
+		{money.New(500), RoundUp, "12001", "12500"},
+		{money.New(500), RoundUp, "12500", "12500"},
+		{money.New(500), RoundDown, "12999", "12500"},
+		// Off
+		{0, RoundNearest, "12345.67", "12345.67"},
+	}
+
+	for _, tt := range tests {
+		r := CashRounding{Increment: tt.increment, Mode: tt.mode}
+		if got := r.round(money.MustParse(tt.amount)); got != money.MustParse(tt.want) {
+			t.Errorf("round(%s) to %s %s = %s, want %s", tt.amount, tt.increment, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestSettleTendersRoundsOnlyCash(t *testing.T) {
+	nearest500 := CashRounding{Increment: money.New(500), Mode: RoundNearest}
+
+	tests := []struct {
+		name      string
+		rounding  CashRounding
+		total     string
+		payments  []PaymentRequest
+		applied   []string
+		roundedBy string
+		change    string
+	}{
+		{
+			name:      "cash only rounds the total",
+			rounding:  nearest500,
+			total:     "18300",
+			payments:  []PaymentRequest{{Method: "cash", Amount: money.New(20000)}},
+			applied:   []string{"18500"},
+			roundedBy: "200",
+			change:    "1500",
+		},
+		{
+			name:      "card only is never rounded",
+			rounding:  nearest500,
+			total:     "18300",
+			payments:  []PaymentRequest{{Method: "card", Amount: money.New(18300)}},
+			applied:   []string{"18300"},
+			roundedBy: "0",
+			change:    "0",
+		},
+		{
+			name:     "split rounds only the part paid in cash",
+			rounding: nearest500,
+			total:    "18300",
+			payments: []PaymentRequest{
+				{Method: "card", Amount: money.New(10100)},
+				{Method: "cash", Amount: money.New(10000)},
+			},
+			// Cash due is 8200, rounded down to 8000
+			applied:   []string{"10100", "8000"},
+			roundedBy: "-200",
+			change:    "2000",
+		},
+		{
+			name:     "cash due already on the increment",
+			rounding: CashRounding{Increment: money.New(100), Mode: RoundUp},
+			total:    "18300",
+			payments: []PaymentRequest{
+				{Method: "qris", Amount: money.New(8300)},
+				{Method: "cash", Amount: money.New(10000)},
+			},
+			applied:   []string{"8300", "10000"},
+			roundedBy: "0",
+			change:    "0",
+		},
+		{
+			name:     "rounded down cash may pay less than the total",
+			rounding: CashRounding{Increment: money.New(100), Mode: RoundDown},
+			total:    "18350",
+			payments: []PaymentRequest{
+				{Method: "cash", Amount: money.New(18300)},
+			},
+			applied:   []string{"18300"},
+			roundedBy: "-50",
+			change:    "0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := settleTenders(money.MustParse(tt.total), tt.payments, tt.rounding)
+			if err != nil {
+				t.Fatalf("settleTenders: %v", err)
+			}
+
+			want := make([]money.Amount, len(tt.applied))
+			for i, a := range tt.applied {
+				want[i] = money.MustParse(a)
+			}
+			if !reflect.DeepEqual(got.Applied, want) {
+				t.Errorf("Applied = %v, want %v", got.Applied, want)
+			}
+			if got.Rounding != money.MustParse(tt.roundedBy) {
+				t.Errorf("Rounding = %s, want %s", got.Rounding, tt.roundedBy)
+			}
+			if got.ChangeAmount != money.MustParse(tt.change) {
+				t.Errorf("ChangeAmount = %s, want %s", got.ChangeAmount, tt.change)
+			}
+		})
+	}
+}
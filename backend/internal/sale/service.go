@@ -2,50 +2,98 @@ package sale
 
 import (
 	"context"
-	"database/sql"
 	"errors"
-	"fmt"
+	"pos-system/internal/auth"
 	"pos-system/internal/db"
-	"strconv"
+	"pos-system/internal/giftcard"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"pos-system/internal/payment"
+	"pos-system/internal/voucher"
+	"strings"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// numericToString converts pgtype.Numeric to string
+// numericToString formats a NUMERIC money column with two decimals
 func numericToString(n pgtype.Numeric) string {
-	if !n.Valid {
-		return "0"
+	amount, err := money.FromNumeric(n)
+	if err != nil {
+		return "0.00"
 	}
-	val, err := n.Value()
+	return amount.String()
+}
+
+// numericToQty reads a NUMERIC quantity column
+func numericToQty(n pgtype.Numeric) quantity.Quantity {
+	qty, err := quantity.FromNumeric(n)
 	if err != nil {
-		return "0"
+		return 0
 	}
-	return fmt.Sprintf("%v", val)
+	return qty
+}
+
+// Config holds the store settings that shape how sales are recorded.
+type Config struct {
+	// HeldCartTTL is how long a parked cart is kept before it expires
+	HeldCartTTL time.Duration
+	Invoice     InvoiceConfig
+	Loyalty     LoyaltyConfig
+	// CashRounding applies to the part of a sale paid in cash
+	CashRounding CashRounding
+	// Payments charges QRIS and e-wallet tenders; nil records them as paid.
+	// Charges not paid within ChargeTTL expire.
+	Payments  payment.Provider
+	ChargeTTL time.Duration
 }
 
 type Service struct {
 	queries *db.Queries
 	db      *pgxpool.Pool
+	auth    *auth.Service
+	cfg     Config
 }
 
-func NewService(queries *db.Queries, db *pgxpool.Pool) *Service {
-	return &Service{queries: queries, db: db}
+func NewService(queries *db.Queries, db *pgxpool.Pool, authService *auth.Service, cfg Config) *Service {
+	return &Service{queries: queries, db: db, auth: authService, cfg: cfg}
 }
 
+// CreateSaleRequest takes its tenders from Payments. PaidAmount and
+// PaymentMethod are still accepted as a single tender when Payments is empty.
 type CreateSaleRequest struct {
-	Items         []SaleItemRequest `json:"items" binding:"required"`
-	PaidAmount    float64           `json:"paid_amount" binding:"required"`
-	PaymentMethod string            `json:"payment_method"`
+	Items         []SaleItemRequest      `json:"items" binding:"required"`
+	Payments      []PaymentRequest       `json:"payments"`
+	PaidAmount    money.Amount           `json:"paid_amount"`
+	PaymentMethod string                 `json:"payment_method"`
+	Override      *PriceOverrideApproval `json:"override"`
+	// VoucherCode is redeemed against the cart after promotions.
+	// VoucherCustomer (phone number or member ID) is needed by vouchers
+	// with a per-customer limit.
+	VoucherCode     string `json:"voucher_code"`
+	VoucherCustomer string `json:"voucher_customer"`
+	// CustomerID links the sale to a customer who earns points on it and
+	// may pay with points. Their phone is the voucher customer when
+	// VoucherCustomer is empty.
+	CustomerID *int32 `json:"customer_id"`
+	// IdempotencyKey may also be sent as the Idempotency-Key header
+	IdempotencyKey string `json:"idempotency_key"`
 }
 
+// SaleItemRequest prices the line from the catalogue. Price may be left at
+// zero; a different price or any discount needs a PriceOverrideApproval.
+// Active promotions are applied on top automatically and need no approval.
 type SaleItemRequest struct {
 	ProductID int32   `json:"product_id" binding:"required"`
-	Qty       int32   `json:"qty" binding:"required"`
-	Price     float64 `json:"price" binding:"required"`
-	Discount  float64 `json:"discount"`
+	// Qty may have as many decimals as the product's qty_precision
+	Qty       quantity.Quantity `json:"qty" binding:"required"`
+	// UnitID sells the line in one of the product's packaging units; Qty
+	// and Price are then per unit
+	UnitID    *int32       `json:"unit_id"`
+	Price     money.Amount `json:"price"`
+	Discount  money.Amount `json:"discount"`
 }
 
 type SaleResponse struct {
@@ -56,9 +104,33 @@ type SaleResponse struct {
 	TotalAmount   string             `json:"total_amount"`
 	PaidAmount    string             `json:"paid_amount"`
 	ChangeAmount  string             `json:"change_amount"`
-	PaymentMethod *string            `json:"payment_method"`
-	Items         []SaleItemResponse `json:"items"`
-	CreatedAt     string             `json:"created_at"`
+	// RoundingAmount is the cash rounding on top of TotalAmount, negative
+	// when rounded down; the payments add up to TotalAmount + RoundingAmount
+	RoundingAmount string `json:"rounding_amount"`
+	// SubtotalAmount is the total before tax; TotalAmount = SubtotalAmount + TaxAmount
+	SubtotalAmount string `json:"subtotal_amount"`
+	TaxAmount      string `json:"tax_amount"`
+	VoucherCode     *string `json:"voucher_code"`
+	VoucherDiscount string  `json:"voucher_discount"`
+	CustomerID      *int32  `json:"customer_id"`
+	PointsEarned    int64   `json:"points_earned"`
+	PointsRedeemed  int64   `json:"points_redeemed"`
+	ShiftID         *int32  `json:"shift_id"`
+	// PaymentStatus is pending while a QRIS or e-wallet charge is awaited,
+	// failed when it did not go through, and paid otherwise
+	PaymentStatus string                 `json:"payment_status"`
+	Payment       *PaymentChargeResponse `json:"payment,omitempty"`
+	PaymentMethod *string               `json:"payment_method"`
+	Payments      []SalePaymentResponse `json:"payments"`
+	Items         []SaleItemResponse    `json:"items"`
+	CreatedAt     string                `json:"created_at"`
+	VoidedAt      *string            `json:"voided_at"`
+	VoidedBy      *int32             `json:"voided_by"`
+	VoidReason    *string            `json:"void_reason"`
+}
+
+type VoidSaleRequest struct {
+	Reason string `json:"reason" binding:"required"`
 }
 
 type SaleItemResponse struct {
@@ -66,27 +138,72 @@ type SaleItemResponse struct {
 	ProductID  int32  `json:"product_id"`
 	ProductName string `json:"product_name"`
 	SKU        *string `json:"sku"`
-	Qty        int32  `json:"qty"`
+	Qty        quantity.Quantity `json:"qty"`
+	// UnitID and Unit are the packaging unit the line was sold in, null for
+	// the base unit; the stock taken is Qty * UnitFactor
+	UnitID     *int32            `json:"unit_id"`
+	Unit       *string           `json:"unit"`
+	UnitFactor quantity.Quantity `json:"unit_factor"`
 	Price      string `json:"price"`
 	Discount   string `json:"discount"`
 	Subtotal   string `json:"subtotal"`
+	ListPrice     string  `json:"list_price"`
+	OverridePrice *string `json:"override_price"`
+	OverrideBy    *int32  `json:"override_by"`
+	TaxRate       string  `json:"tax_rate"`
+	TaxInclusive  bool    `json:"tax_inclusive"`
+	TaxAmount     string  `json:"tax_amount"`
+	// PromotionDiscount is the total of Promotions; Discount stays the manual discount
+	PromotionDiscount string                  `json:"promotion_discount"`
+	Promotions        []SaleItemPromotionResponse `json:"promotions"`
+	VoucherDiscount   string                      `json:"voucher_discount"`
 }
 
-func (s *Service) Create(ctx context.Context, userID int32, req CreateSaleRequest) (*SaleResponse, error) {
-	// Calculate total
-	var totalAmount float64
-	for _, item := range req.Items {
-		subtotal := (item.Price * float64(item.Qty)) - item.Discount
-		totalAmount += subtotal
+// DisplayQty formats Qty for receipts and invoices, followed by the unit the
+// line was sold in if it is not the base unit, e.g. "2 dus".
+func (i SaleItemResponse) DisplayQty() string {
+	if i.Unit == nil {
+		return i.Qty.Display()
 	}
+	return i.Qty.Display() + " " + *i.Unit
+}
 
-	changeAmount := req.PaidAmount - totalAmount
-	if changeAmount < 0 {
-		return nil, errors.New("paid amount is less than total amount")
-	}
+type SaleItemPromotionResponse struct {
+	PromotionID *int32 `json:"promotion_id"`
+	Name        string `json:"name"`
+	Amount      string `json:"amount"`
+}
 
-	// Generate invoice number
-	invoiceNo := fmt.Sprintf("INV-%s", uuid.New().String()[:8])
+// Create records a sale. When req carries an idempotency key that the cashier
+// has already used, the original sale is returned instead of a new one.
+func (s *Service) Create(ctx context.Context, userID int32, req CreateSaleRequest) (*SaleResponse, error) {
+	return s.CreateWith(ctx, userID, req, nil)
+}
+
+// CreateWith is Create for sales that settle something recorded elsewhere,
+// such as a table order. then runs with the new sale in the sale's
+// transaction, so what it writes commits together with the sale and an error
+// from it records no sale at all. A replayed idempotency key returns the
+// original sale without calling then.
+func (s *Service) CreateWith(ctx context.Context, userID int32, req CreateSaleRequest, then func(ctx context.Context, qtx *db.Queries, sale *SaleResponse) error) (*SaleResponse, error) {
+	key := strings.TrimSpace(req.IdempotencyKey)
+	var hash string
+	if key != "" {
+		if len(key) > maxIdempotencyKeyLength {
+			return nil, errors.New("idempotency key must be at most 255 characters")
+		}
+
+		var err error
+		hash, err = requestHash(req)
+		if err != nil {
+			return nil, err
+		}
+
+		sale, err := s.replay(ctx, userID, key, hash)
+		if err != nil || sale != nil {
+			return sale, err
+		}
+	}
 
 	// Start transaction
 	tx, err := s.db.Begin(ctx)
@@ -97,259 +214,603 @@ func (s *Service) Create(ctx context.Context, userID int32, req CreateSaleReques
 
 	qtx := s.queries.WithTx(tx)
 
-	// Create sale
-	userIDPg := pgtype.Int4{Int32: userID, Valid: true}
-	
-	var totalAmountPg pgtype.Numeric
-	if err := totalAmountPg.Scan(strconv.FormatFloat(totalAmount, 'f', 2, 64)); err != nil {
+	sale, charge, err := s.create(ctx, qtx, userID, req)
+	if err != nil {
 		return nil, err
 	}
-	
-	var paidAmountPg pgtype.Numeric
-	if err := paidAmountPg.Scan(strconv.FormatFloat(req.PaidAmount, 'f', 2, 64)); err != nil {
-		return nil, err
+	if then != nil {
+		if err := then(ctx, qtx, sale); err != nil {
+			return nil, err
+		}
 	}
-	
-	var changeAmountPg pgtype.Numeric
-	if err := changeAmountPg.Scan(strconv.FormatFloat(changeAmount, 'f', 2, 64)); err != nil {
+
+	if key != "" {
+		_, err := qtx.CreateSaleIdempotencyKey(ctx, db.CreateSaleIdempotencyKeyParams{
+			UserID:         userID,
+			IdempotencyKey: key,
+			RequestHash:    hash,
+			SaleID:         sale.ID,
+		})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				// A concurrent retry with the same key committed first; drop
+				// this sale and hand back the one that won.
+				tx.Rollback(ctx)
+				return s.replay(ctx, userID, key, hash)
+			}
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
-	
-	var paymentMethodPg pgtype.Text
-	if req.PaymentMethod != "" {
-		paymentMethodPg = pgtype.Text{String: req.PaymentMethod, Valid: true}
+
+	if charge != nil {
+		return s.startCharge(ctx, sale, *charge)
+	}
+	return sale, nil
+}
+
+// create records a sale using qtx, which must be bound to a transaction owned
+// by the caller. Nothing is committed here, so callers can make the sale part
+// of a larger unit of work. A sale paid through the payment provider comes
+// back pending with the charge still to start; callers pass it to
+// startCharge once the transaction has committed.
+func (s *Service) create(ctx context.Context, qtx *db.Queries, userID int32, req CreateSaleRequest) (*SaleResponse, *pendingCharge, error) {
+	// Every sale belongs to the cashier's open shift. The shared lock keeps
+	// the shift from closing until this sale is committed.
+	shift, err := qtx.GetOpenShiftByUserForShare(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil, errors.New("cashier has no open shift")
+		}
+		return nil, nil, err
 	}
 
+	customer, err := lookupCustomer(ctx, qtx, req.CustomerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if customer != nil && req.VoucherCustomer == "" && customer.Phone.Valid {
+		req.VoucherCustomer = customer.Phone.String
+	}
+
+	// Price every line from the catalogue, then apply promotions, the
+	// voucher and tax
+	priced, err := s.priceSale(ctx, qtx, req, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	lines := priced.Lines
+
+	approvedBy, err := s.approveOverrides(ctx, lines, req.Override)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Calculate total; line subtotals already include their tax
+	var totalAmount, taxAmount money.Amount
+	for _, line := range lines {
+		totalAmount += line.Subtotal
+		taxAmount += line.Tax
+	}
+
+	payments := req.payments()
+	tender, err := settleTenders(totalAmount, payments, s.cfg.CashRounding)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// A tender charged through the payment provider leaves the sale
+	// pending until the provider confirms it
+	chargeAt, err := s.providerTender(payments)
+	if err != nil {
+		return nil, nil, err
+	}
+	paymentStatus := PaymentStatusPaid
+	if chargeAt >= 0 {
+		paymentStatus = PaymentStatusPending
+	}
+
+	// Points pay for part of the sale but earn nothing themselves
+	pointsRedeemed, pointsAmount, err := s.cfg.Loyalty.pointsTendered(payments, customer)
+	if err != nil {
+		return nil, nil, err
+	}
+	var customerIDPg pgtype.Int4
+	var pointsEarned int64
+	if customer != nil {
+		customerIDPg = pgtype.Int4{Int32: customer.ID, Valid: true}
+		pointsEarned = s.cfg.Loyalty.pointsEarned(totalAmount - pointsAmount)
+	}
+
+	// Take the next sequential invoice number
+	invoiceNo, err := s.nextInvoiceNo(ctx, qtx, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var voucherCodePg pgtype.Text
+	if priced.Voucher != nil {
+		voucherCodePg = pgtype.Text{String: priced.Voucher.Code, Valid: true}
+	}
+
+	// Create sale
 	sale, err := qtx.CreateSale(ctx, db.CreateSaleParams{
-		InvoiceNo:    invoiceNo,
-		UserID:       userIDPg,
-		TotalAmount:  totalAmountPg,
-		PaidAmount:   paidAmountPg,
-		ChangeAmount: changeAmountPg,
-		PaymentMethod: paymentMethodPg,
+		InvoiceNo:     invoiceNo,
+		UserID:        pgtype.Int4{Int32: userID, Valid: true},
+		TotalAmount:   totalAmount.Numeric(),
+		PaidAmount:    tender.PaidAmount.Numeric(),
+		ChangeAmount:  tender.ChangeAmount.Numeric(),
+		PaymentMethod:  pgtype.Text{String: tender.PaymentMethod, Valid: true},
+		SubtotalAmount: (totalAmount - taxAmount).Numeric(),
+		TaxAmount:      taxAmount.Numeric(),
+		VoucherCode:     voucherCodePg,
+		VoucherDiscount: priced.VoucherDiscount.Numeric(),
+		CustomerID:      customerIDPg,
+		PointsEarned:    pointsEarned,
+		PointsRedeemed:  pointsRedeemed,
+		ShiftID:         pgtype.Int4{Int32: shift.ID, Valid: true},
+		RoundingAmount:  tender.Rounding.Numeric(),
+		PaymentStatus:   paymentStatus,
 	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	// Validate stock availability for all items BEFORE creating sale items
-	// This ensures atomicity: if any item has insufficient stock, entire sale is rolled back
-	for _, item := range req.Items {
-		productIDPg := pgtype.Int4{Int32: item.ProductID, Valid: true}
-		
-		// Get current inventory
-		inv, err := qtx.GetInventoryByProduct(ctx, productIDPg)
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				// Get product name for better error message
-				product, _ := qtx.GetProductByID(ctx, item.ProductID)
-				return nil, fmt.Errorf("stock not sufficient for product: %s (inventory not found)", product.Name)
-			}
-			return nil, fmt.Errorf("failed to check inventory for product %d: %w", item.ProductID, err)
+	if customer != nil {
+		if err := settlePoints(ctx, qtx, customer.ID, sale.ID, pointsEarned, pointsRedeemed); err != nil {
+			return nil, nil, err
 		}
+	}
 
-		// Check if stock is sufficient
-		if inv.Qty < item.Qty {
-			// Get product name for better error message
-			product, _ := qtx.GetProductByID(ctx, item.ProductID)
-			return nil, fmt.Errorf("stock not sufficient for product: %s (available: %d, requested: %d)", product.Name, inv.Qty, item.Qty)
+	// Use up the voucher in the same transaction as the sale
+	if priced.Voucher != nil {
+		if err := voucher.Redeem(ctx, qtx, *priced.Voucher, sale.ID, req.VoucherCustomer, priced.VoucherDiscount); err != nil {
+			return nil, nil, err
 		}
 	}
 
-	// Create sale items and update inventory
-	items := make([]SaleItemResponse, len(req.Items))
-	for i, item := range req.Items {
-		subtotal := (item.Price * float64(item.Qty)) - item.Discount
-
-		saleIDPg := pgtype.Int4{Int32: sale.ID, Valid: true}
-		productIDPg := pgtype.Int4{Int32: item.ProductID, Valid: true}
-		
-		var pricePg pgtype.Numeric
-		if err := pricePg.Scan(strconv.FormatFloat(item.Price, 'f', 2, 64)); err != nil {
-			return nil, err
+	// Record each tender, drawing gift card tenders from their cards in
+	// the same transaction
+	paymentResponses := make([]SalePaymentResponse, len(payments))
+	for i, p := range payments {
+		if isGiftCard(p.Method) {
+			if err := giftcard.Redeem(ctx, qtx, p.CardNumber, tender.Applied[i], sale.ID, userID); err != nil {
+				return nil, nil, err
+			}
 		}
-		
-		var discountPg pgtype.Numeric
-		if err := discountPg.Scan(strconv.FormatFloat(item.Discount, 'f', 2, 64)); err != nil {
-			return nil, err
+		payment, err := qtx.CreateSalePayment(ctx, db.CreateSalePaymentParams{
+			SaleID: sale.ID,
+			Method: p.Method,
+			Amount: tender.Applied[i].Numeric(),
+		})
+		if err != nil {
+			return nil, nil, err
 		}
+		paymentResponses[i] = salePaymentResponseFromRow(payment)
+	}
+
+	// Take the stock BEFORE creating sale items. The inventory rows stay
+	// locked until the sale commits, so concurrent sales of the last unit
+	// cannot both succeed; if any item is short the entire sale is rolled
+	// back. A pending sale only checks now and takes its stock when the
+	// payment is confirmed.
+	needs := make([]stockNeed, len(lines))
+	for i, line := range lines {
+		needs[i] = stockNeed{ProductID: line.Product.ID, Name: line.Product.Name, Qty: line.baseQty()}
+	}
+	if paymentStatus == PaymentStatusPaid {
+		err = deductStock(ctx, qtx, needs)
+	} else {
+		err = checkStock(ctx, qtx, needs, false)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Create sale items
+	items := make([]SaleItemResponse, len(lines))
+	for i, line := range lines {
+		saleIDPg := pgtype.Int4{Int32: sale.ID, Valid: true}
+		productIDPg := pgtype.Int4{Int32: line.Product.ID, Valid: true}
 		
-		var subtotalPg pgtype.Numeric
-		if err := subtotalPg.Scan(strconv.FormatFloat(subtotal, 'f', 2, 64)); err != nil {
-			return nil, err
+		// Only overridden lines carry an override price and approver
+		var overridePricePg pgtype.Numeric
+		var overrideByPg pgtype.Int4
+		if line.Overridden {
+			overridePricePg = line.Price.Numeric()
+			overrideByPg = approvedBy
 		}
 
 		// Create sale item
 		saleItem, err := qtx.CreateSaleItem(ctx, db.CreateSaleItemParams{
-			SaleID:    saleIDPg,
-			ProductID: productIDPg,
-			Qty:       item.Qty,
-			Price:     pricePg,
-			Discount:  discountPg,
-			Subtotal:  subtotalPg,
+			SaleID:        saleIDPg,
+			ProductID:     productIDPg,
+			Qty:           line.Qty.Numeric(),
+			Price:         line.Price.Numeric(),
+			Discount:      line.Discount.Numeric(),
+			Subtotal:      line.Subtotal.Numeric(),
+			ListPrice:     line.ListPrice.Numeric(),
+			OverridePrice: overridePricePg,
+			OverrideBy:    overrideByPg,
+			TaxRateID:     line.TaxRateID,
+			TaxRate:       line.TaxRate.Numeric(),
+			TaxInclusive:  line.TaxInclusive,
+			TaxAmount:     line.Tax.Numeric(),
+			PromotionDiscount: line.PromotionDiscount.Numeric(),
+			VoucherDiscount:   line.VoucherDiscount.Numeric(),
+			UnitID:            line.UnitID,
+			UnitName:          line.UnitName,
+			UnitFactor:        line.UnitFactor.Numeric(),
 		})
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		// Record which promotions produced the line's promotion discount
+		promotions := make([]db.SaleItemPromotion, len(line.Promotions))
+		for j, d := range line.Promotions {
+			promotions[j], err = qtx.CreateSaleItemPromotion(ctx, db.CreateSaleItemPromotionParams{
+				SaleID:        sale.ID,
+				SaleItemID:    saleItem.ID,
+				PromotionID:   pgtype.Int4{Int32: d.PromotionID, Valid: true},
+				PromotionName: d.Name,
+				Amount:        d.Amount.Numeric(),
+			})
+			if err != nil {
+				return nil, nil, err
+			}
 		}
 
-		// Update inventory (decrease)
-		_, err = qtx.AdjustInventoryQty(ctx, db.AdjustInventoryQtyParams{
-			ProductID: productIDPg,
-			Qty:       -item.Qty,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("failed to update inventory for product %d: %w", item.ProductID, err)
-		}
+		items[i] = saleItemResponseFromRow(db.GetSaleItemsBySaleIDRow{
+			ID:            saleItem.ID,
+			SaleID:        saleItem.SaleID,
+			ProductID:     saleItem.ProductID,
+			Qty:           saleItem.Qty,
+			Price:         saleItem.Price,
+			Discount:      saleItem.Discount,
+			Subtotal:      saleItem.Subtotal,
+			ListPrice:     saleItem.ListPrice,
+			OverridePrice: saleItem.OverridePrice,
+			OverrideBy:    saleItem.OverrideBy,
+			TaxRateID:     saleItem.TaxRateID,
+			TaxRate:       saleItem.TaxRate,
+			TaxInclusive:  saleItem.TaxInclusive,
+			TaxAmount:     saleItem.TaxAmount,
+			PromotionDiscount: saleItem.PromotionDiscount,
+			VoucherDiscount:   saleItem.VoucherDiscount,
+			UnitID:            saleItem.UnitID,
+			UnitName:          saleItem.UnitName,
+			UnitFactor:        saleItem.UnitFactor,
+			ProductName:   line.Product.Name,
+			Sku:           line.Product.Sku,
+		}, promotions)
+	}
+
+	var charge *pendingCharge
+	if chargeAt >= 0 {
+		charge = &pendingCharge{Method: payments[chargeAt].Method, Amount: tender.Applied[chargeAt]}
+	}
 
-		// Get product info
-		product, _ := qtx.GetProductByID(ctx, item.ProductID)
+	// Get sale with cashier name
+	saleWithUser, err := qtx.GetSaleByID(ctx, sale.ID)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		var saleItemProductID int32
-		if saleItem.ProductID.Valid {
-			saleItemProductID = saleItem.ProductID.Int32
-		}
+	return saleResponseFromRow(saleWithUser, items, paymentResponses), charge, nil
+}
 
-		var sku *string
-		if product.Sku.Valid {
-			sku = &product.Sku.String
+func (s *Service) GetByID(ctx context.Context, id int32) (*SaleResponse, error) {
+	sale, err := s.queries.GetSaleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("sale not found")
 		}
+		return nil, err
+	}
 
-		var priceStr string
-		if saleItem.Price.Valid {
-			priceStr = numericToString(saleItem.Price)
-		}
+	saleIDPg := pgtype.Int4{Int32: id, Valid: true}
+	items, err := s.queries.GetSaleItemsBySaleID(ctx, saleIDPg)
+	if err != nil {
+		return nil, err
+	}
 
-		var discountStr string
-		if saleItem.Discount.Valid {
-			discountStr = numericToString(saleItem.Discount)
-		}
+	promotions, err := s.queries.GetSaleItemPromotionsBySaleID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-		var subtotalStr string
-		if saleItem.Subtotal.Valid {
-			subtotalStr = numericToString(saleItem.Subtotal)
-		}
+	payments, err := s.queries.GetSalePaymentsBySaleID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-		items[i] = SaleItemResponse{
-			ID:          saleItem.ID,
-			ProductID:   saleItemProductID,
-			ProductName: product.Name,
-			SKU:         sku,
-			Qty:         saleItem.Qty,
-			Price:       priceStr,
-			Discount:    discountStr,
-			Subtotal:    subtotalStr,
-		}
+	resp := saleResponseFromRow(sale, saleItemResponses(items, promotions), salePaymentResponses(payments))
+	charge, err := s.queries.GetPaymentChargeBySale(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+	if err == nil {
+		resp.Payment = paymentChargeResponseFromRow(charge)
 	}
+	return resp, nil
+}
 
-	if err := tx.Commit(ctx); err != nil {
+func (s *Service) ListByDateRange(ctx context.Context, from, to time.Time) ([]SaleResponse, error) {
+	fromPg := pgtype.Timestamptz{Time: from, Valid: true}
+	toPg := pgtype.Timestamptz{Time: to, Valid: true}
+	sales, err := s.queries.ListSalesByDateRange(ctx, db.ListSalesByDateRangeParams{
+		CreatedAt:   fromPg,
+		CreatedAt_2: toPg,
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Get sale with cashier name
-	saleWithUser, _ := s.queries.GetSaleByID(ctx, sale.ID)
-	var cashierName *string
-	if saleWithUser.CashierName.Valid {
-		cashierName = &saleWithUser.CashierName.String
+	rows := make([]db.GetSaleByIDRow, len(sales))
+	for i, sale := range sales {
+		rows[i] = db.GetSaleByIDRow(sale)
 	}
 
-	var saleUserID *int32
-	if saleWithUser.UserID.Valid {
-		saleUserID = &saleWithUser.UserID.Int32
+	return saleResponses(ctx, s.queries, rows)
+}
+
+
+// Void marks a completed sale as voided and returns every sold quantity to
+// inventory. Both happen in one transaction so stock never drifts from the
+// sale's state.
+func (s *Service) Void(ctx context.Context, id, userID int32, req VoidSaleRequest) (*SaleResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer tx.Rollback(ctx)
 
-	var saleTotalAmount string
-	if saleWithUser.TotalAmount.Valid {
-		saleTotalAmount = numericToString(saleWithUser.TotalAmount)
+	qtx := s.queries.WithTx(tx)
+
+	// VoidSale only matches sales that are not voided yet, so two concurrent
+	// voids cannot both restock the same items
+	voided, err := qtx.VoidSale(ctx, db.VoidSaleParams{
+		ID:         id,
+		VoidedBy:   pgtype.Int4{Int32: userID, Valid: true},
+		VoidReason: pgtype.Text{String: req.Reason, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, err := qtx.GetSaleByID(ctx, id); err != nil {
+				if errors.Is(err, pgx.ErrNoRows) {
+					return nil, errors.New("sale not found")
+				}
+				return nil, err
+			}
+			return nil, errors.New("sale already voided")
+		}
+		return nil, err
 	}
 
-	var salePaidAmount string
-	if saleWithUser.PaidAmount.Valid {
-		salePaidAmount = numericToString(saleWithUser.PaidAmount)
+	// Its stock was never taken; cancelling the payment voids it instead
+	if voided.PaymentStatus == PaymentStatusPending {
+		return nil, errors.New("sale is awaiting payment")
 	}
 
-	var saleChangeAmount string
-	if saleWithUser.ChangeAmount.Valid {
-		saleChangeAmount = numericToString(saleWithUser.ChangeAmount)
+	// Returned items are already back in stock; restoring the full sale on
+	// top of them would count those units twice
+	returnCount, err := qtx.CountSaleReturnsBySale(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if returnCount > 0 {
+		return nil, errors.New("sale has returns and cannot be voided")
 	}
 
-	var paymentMethod *string
-	if saleWithUser.PaymentMethod.Valid {
-		paymentMethod = &saleWithUser.PaymentMethod.String
+	saleIDPg := pgtype.Int4{Int32: id, Valid: true}
+	items, err := qtx.GetSaleItemsBySaleID(ctx, saleIDPg)
+	if err != nil {
+		return nil, err
 	}
 
-	var createdAt string
-	if saleWithUser.CreatedAt.Valid {
-		createdAt = saleWithUser.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	// Restore stock (increase)
+	needs := make([]stockNeed, len(items))
+	for i, item := range items {
+		qty, err := itemStockQty(item.Qty, item.UnitFactor)
+		if err != nil {
+			return nil, err
+		}
+		needs[i] = stockNeed{ProductID: item.ProductID.Int32, Name: item.ProductName, Qty: qty}
+	}
+	if err := restock(ctx, qtx, needs); err != nil {
+		return nil, err
 	}
 
-	return &SaleResponse{
-		ID:            saleWithUser.ID,
-		InvoiceNo:     saleWithUser.InvoiceNo,
-		UserID:        saleUserID,
-		CashierName:   cashierName,
-		TotalAmount:   saleTotalAmount,
-		PaidAmount:    salePaidAmount,
-		ChangeAmount:  saleChangeAmount,
-		PaymentMethod: paymentMethod,
-		Items:         items,
-		CreatedAt:     createdAt,
-	}, nil
+	// Give back the voucher use, points and gift card payments
+	if err := releaseSale(ctx, qtx, voided, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	// Pay back a QRIS or e-wallet charge now that the sale and stock rows are
+	// unlocked. The sale is voided either way; if the provider turns the
+	// refund down the charge stays paid and SweepPayments tries again.
+	s.refundSaleCharge(ctx, id)
+
+	return s.GetByID(ctx, id)
 }
 
-func (s *Service) GetByID(ctx context.Context, id int32) (*SaleResponse, error) {
-	sale, err := s.queries.GetSaleByID(ctx, id)
+func salePaymentResponseFromRow(p db.SalePayment) SalePaymentResponse {
+	return SalePaymentResponse{
+		ID:     p.ID,
+		Method: p.Method,
+		Amount: numericToString(p.Amount),
+	}
+}
+
+func salePaymentResponses(payments []db.SalePayment) []SalePaymentResponse {
+	result := make([]SalePaymentResponse, len(payments))
+	for i, p := range payments {
+		result[i] = salePaymentResponseFromRow(p)
+	}
+	return result
+}
+
+// saleResponses builds the responses for a page of sales. Items, their
+// promotions and payments are loaded for the whole page in one query each,
+// so the number of queries does not grow with the page size.
+func saleResponses(ctx context.Context, q *db.Queries, sales []db.GetSaleByIDRow) ([]SaleResponse, error) {
+	result := make([]SaleResponse, len(sales))
+	if len(sales) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int32, len(sales))
+	for i, sale := range sales {
+		ids[i] = sale.ID
+	}
+
+	items, err := q.GetSaleItemsBySaleIDs(ctx, ids)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errors.New("sale not found")
-		}
 		return nil, err
 	}
-
-	saleIDPg := pgtype.Int4{Int32: id, Valid: true}
-	items, err := s.queries.GetSaleItemsBySaleID(ctx, saleIDPg)
+	promotions, err := q.GetSaleItemPromotionsBySaleIDs(ctx, ids)
 	if err != nil {
 		return nil, err
 	}
+	payments, err := q.GetSalePaymentsBySaleIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	itemsBySale := make(map[int32][]db.GetSaleItemsBySaleIDRow, len(sales))
+	for _, item := range items {
+		itemsBySale[item.SaleID.Int32] = append(itemsBySale[item.SaleID.Int32], db.GetSaleItemsBySaleIDRow(item))
+	}
+	promotionsBySale := make(map[int32][]db.SaleItemPromotion, len(sales))
+	for _, p := range promotions {
+		promotionsBySale[p.SaleID] = append(promotionsBySale[p.SaleID], p)
+	}
+	paymentsBySale := make(map[int32][]db.SalePayment, len(sales))
+	for _, p := range payments {
+		paymentsBySale[p.SaleID] = append(paymentsBySale[p.SaleID], p)
+	}
+
+	for i, sale := range sales {
+		result[i] = *saleResponseFromRow(sale, saleItemResponses(itemsBySale[sale.ID], promotionsBySale[sale.ID]), salePaymentResponses(paymentsBySale[sale.ID]))
+	}
+	return result, nil
+}
+
+// saleItemResponses builds the item responses for one sale, attaching each
+// item's recorded promotions.
+func saleItemResponses(items []db.GetSaleItemsBySaleIDRow, promotions []db.SaleItemPromotion) []SaleItemResponse {
+	byItem := make(map[int32][]db.SaleItemPromotion)
+	for _, p := range promotions {
+		byItem[p.SaleItemID] = append(byItem[p.SaleItemID], p)
+	}
 
-	itemResponses := make([]SaleItemResponse, len(items))
+	result := make([]SaleItemResponse, len(items))
 	for i, item := range items {
-		var productID int32
-		if item.ProductID.Valid {
-			productID = item.ProductID.Int32
-		}
+		result[i] = saleItemResponseFromRow(item, byItem[item.ID])
+	}
+	return result
+}
 
-		var sku *string
-		if item.Sku.Valid {
-			sku = &item.Sku.String
-		}
+func saleItemResponseFromRow(item db.GetSaleItemsBySaleIDRow, promotions []db.SaleItemPromotion) SaleItemResponse {
+	var productID int32
+	if item.ProductID.Valid {
+		productID = item.ProductID.Int32
+	}
 
-		var priceStr string
-		if item.Price.Valid {
-			priceStr = numericToString(item.Price)
-		}
+	var sku *string
+	if item.Sku.Valid {
+		sku = &item.Sku.String
+	}
 
-		var discountStr string
-		if item.Discount.Valid {
-			discountStr = numericToString(item.Discount)
-		}
+	var priceStr string
+	if item.Price.Valid {
+		priceStr = numericToString(item.Price)
+	}
 
-		var subtotalStr string
-		if item.Subtotal.Valid {
-			subtotalStr = numericToString(item.Subtotal)
-		}
+	var discountStr string
+	if item.Discount.Valid {
+		discountStr = numericToString(item.Discount)
+	}
+
+	var subtotalStr string
+	if item.Subtotal.Valid {
+		subtotalStr = numericToString(item.Subtotal)
+	}
+
+	var listPriceStr string
+	if item.ListPrice.Valid {
+		listPriceStr = numericToString(item.ListPrice)
+	}
+
+	var overridePrice *string
+	if item.OverridePrice.Valid {
+		op := numericToString(item.OverridePrice)
+		overridePrice = &op
+	}
+
+	var overrideBy *int32
+	if item.OverrideBy.Valid {
+		overrideBy = &item.OverrideBy.Int32
+	}
+
+	var unitID *int32
+	if item.UnitID.Valid {
+		unitID = &item.UnitID.Int32
+	}
 
-		itemResponses[i] = SaleItemResponse{
-			ID:          item.ID,
-			ProductID:   productID,
-			ProductName: item.ProductName,
-			SKU:         sku,
-			Qty:         item.Qty,
-			Price:       priceStr,
-			Discount:    discountStr,
-			Subtotal:    subtotalStr,
+	var unit *string
+	if item.UnitName.Valid {
+		unit = &item.UnitName.String
+	}
+
+	promotionResponses := make([]SaleItemPromotionResponse, len(promotions))
+	for i, p := range promotions {
+		var promotionID *int32
+		if p.PromotionID.Valid {
+			promotionID = &p.PromotionID.Int32
+		}
+		promotionResponses[i] = SaleItemPromotionResponse{
+			PromotionID: promotionID,
+			Name:        p.PromotionName,
+			Amount:      numericToString(p.Amount),
 		}
 	}
 
+	return SaleItemResponse{
+		ID:          item.ID,
+		ProductID:   productID,
+		ProductName: item.ProductName,
+		SKU:         sku,
+		Qty:         numericToQty(item.Qty),
+		UnitID:      unitID,
+		Unit:        unit,
+		UnitFactor:  numericToQty(item.UnitFactor),
+		Price:       priceStr,
+		Discount:    discountStr,
+		Subtotal:    subtotalStr,
+		ListPrice:     listPriceStr,
+		OverridePrice: overridePrice,
+		OverrideBy:    overrideBy,
+		TaxRate:       numericToString(item.TaxRate),
+		TaxInclusive:  item.TaxInclusive,
+		TaxAmount:     numericToString(item.TaxAmount),
+		PromotionDiscount: numericToString(item.PromotionDiscount),
+		Promotions:        promotionResponses,
+		VoucherDiscount:   numericToString(item.VoucherDiscount),
+	}
+}
+
+// saleResponseFromRow builds a SaleResponse from a sale row. ListSalesRow and
+// ListSalesByDateRangeRow share GetSaleByIDRow's columns and convert directly.
+func saleResponseFromRow(sale db.GetSaleByIDRow, items []SaleItemResponse, payments []SalePaymentResponse) *SaleResponse {
 	var cashierName *string
 	if sale.CashierName.Valid {
 		cashierName = &sale.CashierName.String
@@ -385,6 +846,37 @@ func (s *Service) GetByID(ctx context.Context, id int32) (*SaleResponse, error)
 		createdAt = sale.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
 	}
 
+	var voidedAt *string
+	if sale.VoidedAt.Valid {
+		v := sale.VoidedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		voidedAt = &v
+	}
+
+	var voidedBy *int32
+	if sale.VoidedBy.Valid {
+		voidedBy = &sale.VoidedBy.Int32
+	}
+
+	var voidReason *string
+	if sale.VoidReason.Valid {
+		voidReason = &sale.VoidReason.String
+	}
+
+	var voucherCode *string
+	if sale.VoucherCode.Valid {
+		voucherCode = &sale.VoucherCode.String
+	}
+
+	var customerID *int32
+	if sale.CustomerID.Valid {
+		customerID = &sale.CustomerID.Int32
+	}
+
+	var shiftID *int32
+	if sale.ShiftID.Valid {
+		shiftID = &sale.ShiftID.Int32
+	}
+
 	return &SaleResponse{
 		ID:            sale.ID,
 		InvoiceNo:     sale.InvoiceNo,
@@ -393,219 +885,22 @@ func (s *Service) GetByID(ctx context.Context, id int32) (*SaleResponse, error)
 		TotalAmount:   totalAmount,
 		PaidAmount:    paidAmount,
 		ChangeAmount:  changeAmount,
+		RoundingAmount: numericToString(sale.RoundingAmount),
+		SubtotalAmount: numericToString(sale.SubtotalAmount),
+		TaxAmount:      numericToString(sale.TaxAmount),
+		VoucherCode:     voucherCode,
+		VoucherDiscount: numericToString(sale.VoucherDiscount),
+		CustomerID:      customerID,
+		PointsEarned:    sale.PointsEarned,
+		PointsRedeemed:  sale.PointsRedeemed,
+		ShiftID:         shiftID,
+		PaymentStatus:   sale.PaymentStatus,
 		PaymentMethod: paymentMethod,
-		Items:         itemResponses,
+		Payments:      payments,
+		Items:         items,
 		CreatedAt:     createdAt,
-	}, nil
-}
-
-func (s *Service) List(ctx context.Context, limit, offset int32) ([]SaleResponse, error) {
-	sales, err := s.queries.ListSales(ctx, db.ListSalesParams{
-		Limit:  limit,
-		Offset: offset,
-	})
-	if err != nil {
-		return nil, err
+		VoidedAt:      voidedAt,
+		VoidedBy:      voidedBy,
+		VoidReason:    voidReason,
 	}
-
-	result := make([]SaleResponse, len(sales))
-	for i, sale := range sales {
-		saleIDPg := pgtype.Int4{Int32: sale.ID, Valid: true}
-		items, _ := s.queries.GetSaleItemsBySaleID(ctx, saleIDPg)
-		itemResponses := make([]SaleItemResponse, len(items))
-		for j, item := range items {
-			var productID int32
-			if item.ProductID.Valid {
-				productID = item.ProductID.Int32
-			}
-
-			var sku *string
-			if item.Sku.Valid {
-				sku = &item.Sku.String
-			}
-
-			var priceStr string
-			if item.Price.Valid {
-				priceStr = numericToString(item.Price)
-			}
-
-			var discountStr string
-			if item.Discount.Valid {
-				discountStr = numericToString(item.Discount)
-			}
-
-			var subtotalStr string
-			if item.Subtotal.Valid {
-				subtotalStr = numericToString(item.Subtotal)
-			}
-
-			itemResponses[j] = SaleItemResponse{
-				ID:          item.ID,
-				ProductID:   productID,
-				ProductName: item.ProductName,
-				SKU:         sku,
-				Qty:         item.Qty,
-				Price:       priceStr,
-				Discount:    discountStr,
-				Subtotal:    subtotalStr,
-			}
-		}
-
-		var cashierName *string
-		if sale.CashierName.Valid {
-			cashierName = &sale.CashierName.String
-		}
-
-		var userID *int32
-		if sale.UserID.Valid {
-			userID = &sale.UserID.Int32
-		}
-
-		var saleTotalAmount string
-		if sale.TotalAmount.Valid {
-			saleTotalAmount = numericToString(sale.TotalAmount)
-		}
-
-		var salePaidAmount string
-		if sale.PaidAmount.Valid {
-			salePaidAmount = numericToString(sale.PaidAmount)
-		}
-
-		var saleChangeAmount string
-		if sale.ChangeAmount.Valid {
-			saleChangeAmount = numericToString(sale.ChangeAmount)
-		}
-
-		var paymentMethod *string
-		if sale.PaymentMethod.Valid {
-			paymentMethod = &sale.PaymentMethod.String
-		}
-
-		var createdAt string
-		if sale.CreatedAt.Valid {
-			createdAt = sale.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
-		}
-
-		result[i] = SaleResponse{
-			ID:            sale.ID,
-			InvoiceNo:     sale.InvoiceNo,
-			UserID:        userID,
-			CashierName:   cashierName,
-			TotalAmount:   saleTotalAmount,
-			PaidAmount:    salePaidAmount,
-			ChangeAmount:  saleChangeAmount,
-			PaymentMethod: paymentMethod,
-			Items:         itemResponses,
-			CreatedAt:     createdAt,
-		}
-	}
-
-	return result, nil
 }
-
-func (s *Service) ListByDateRange(ctx context.Context, from, to time.Time) ([]SaleResponse, error) {
-	fromPg := pgtype.Timestamptz{Time: from, Valid: true}
-	toPg := pgtype.Timestamptz{Time: to, Valid: true}
-	sales, err := s.queries.ListSalesByDateRange(ctx, db.ListSalesByDateRangeParams{
-		CreatedAt:   fromPg,
-		CreatedAt_2: toPg,
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	result := make([]SaleResponse, len(sales))
-	for i, sale := range sales {
-		saleIDPg := pgtype.Int4{Int32: sale.ID, Valid: true}
-		items, _ := s.queries.GetSaleItemsBySaleID(ctx, saleIDPg)
-		itemResponses := make([]SaleItemResponse, len(items))
-		for j, item := range items {
-			var productID int32
-			if item.ProductID.Valid {
-				productID = item.ProductID.Int32
-			}
-			
-			var sku *string
-			if item.Sku.Valid {
-				sku = &item.Sku.String
-			}
-
-			var priceStr string
-			if item.Price.Valid {
-				priceStr = numericToString(item.Price)
-			}
-
-			var discountStr string
-			if item.Discount.Valid {
-				discountStr = numericToString(item.Discount)
-			}
-
-			var subtotalStr string
-			if item.Subtotal.Valid {
-				subtotalStr = numericToString(item.Subtotal)
-			}
-
-			itemResponses[j] = SaleItemResponse{
-				ID:          item.ID,
-				ProductID:   productID,
-				ProductName: item.ProductName,
-				SKU:         sku,
-				Qty:         item.Qty,
-				Price:       priceStr,
-				Discount:    discountStr,
-				Subtotal:    subtotalStr,
-			}
-		}
-
-		var cashierName *string
-		if sale.CashierName.Valid {
-			cashierName = &sale.CashierName.String
-		}
-
-		var saleUserID *int32
-		if sale.UserID.Valid {
-			saleUserID = &sale.UserID.Int32
-		}
-
-		var saleTotalAmount string
-		if sale.TotalAmount.Valid {
-			saleTotalAmount = numericToString(sale.TotalAmount)
-		}
-
-		var salePaidAmount string
-		if sale.PaidAmount.Valid {
-			salePaidAmount = numericToString(sale.PaidAmount)
-		}
-
-		var saleChangeAmount string
-		if sale.ChangeAmount.Valid {
-			saleChangeAmount = numericToString(sale.ChangeAmount)
-		}
-
-		var paymentMethod *string
-		if sale.PaymentMethod.Valid {
-			paymentMethod = &sale.PaymentMethod.String
-		}
-
-		var createdAt string
-		if sale.CreatedAt.Valid {
-			createdAt = sale.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
-		}
-
-		result[i] = SaleResponse{
-			ID:            sale.ID,
-			InvoiceNo:     sale.InvoiceNo,
-			UserID:        saleUserID,
-			CashierName:   cashierName,
-			TotalAmount:   saleTotalAmount,
-			PaidAmount:    salePaidAmount,
-			ChangeAmount:  saleChangeAmount,
-			PaymentMethod: paymentMethod,
-			Items:         itemResponses,
-			CreatedAt:     createdAt,
-		}
-	}
-
-	return result, nil
-}
-
@@ -0,0 +1,133 @@
+package sale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-system/internal/db"
+	"pos-system/internal/quantity"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// stockNeed is the quantity of one product a sale takes from inventory.
+type stockNeed struct {
+	ProductID int32
+	Name      string
+	Qty       quantity.Quantity
+}
+
+// itemStockQty is the stock a stored sale item took: its qty times the
+// factor of the unit it was sold in.
+func itemStockQty(qty, unitFactor pgtype.Numeric) (quantity.Quantity, error) {
+	q, err := quantity.FromNumeric(qty)
+	if err != nil {
+		return 0, err
+	}
+	factor, err := quantity.FromNumeric(unitFactor)
+	if err != nil {
+		return 0, err
+	}
+	return q.Mul(factor), nil
+}
+
+// mergeStockNeeds adds up the quantities of products that appear on more
+// than one line and orders the result by product ID, the order inventory
+// rows are locked in.
+func mergeStockNeeds(needs []stockNeed) []stockNeed {
+	merged := make([]stockNeed, 0, len(needs))
+	index := make(map[int32]int, len(needs))
+	for _, n := range needs {
+		if i, ok := index[n.ProductID]; ok {
+			merged[i].Qty += n.Qty
+			continue
+		}
+		index[n.ProductID] = len(merged)
+		merged = append(merged, n)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].ProductID < merged[j].ProductID })
+	return merged
+}
+
+// checkStock reports the first product without enough stock for needs. With
+// lock set the inventory rows stay locked until qtx's transaction ends, so
+// the quantities read cannot change before deductStock takes them.
+func checkStock(ctx context.Context, qtx *db.Queries, needs []stockNeed, lock bool) error {
+	lookup := qtx.GetInventoryByProduct
+	if lock {
+		lookup = qtx.GetInventoryByProductForUpdate
+	}
+
+	for _, n := range mergeStockNeeds(needs) {
+		inv, err := lookup(ctx, pgtype.Int4{Int32: n.ProductID, Valid: true})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("stock not sufficient for product: %s (inventory not found)", n.Name)
+			}
+			return fmt.Errorf("failed to check inventory for product %d: %w", n.ProductID, err)
+		}
+		available, err := quantity.FromNumeric(inv.Qty)
+		if err != nil {
+			return err
+		}
+		if available < n.Qty {
+			return fmt.Errorf("stock not sufficient for product: %s (available: %s, requested: %s)", n.Name, available, n.Qty)
+		}
+	}
+	return nil
+}
+
+// deductStock takes needs out of inventory. Every row is locked and checked
+// before any is changed, in product ID order, so two sales of the last unit
+// cannot both succeed and concurrent sales of the same products cannot
+// deadlock. Nothing is changed when stock is short.
+func deductStock(ctx context.Context, qtx *db.Queries, needs []stockNeed) error {
+	needs = mergeStockNeeds(needs)
+	if err := checkStock(ctx, qtx, needs, true); err != nil {
+		return err
+	}
+
+	for _, n := range needs {
+		_, err := qtx.AdjustInventoryQty(ctx, db.AdjustInventoryQtyParams{
+			ProductID: pgtype.Int4{Int32: n.ProductID, Valid: true},
+			Qty:       (-n.Qty).Numeric(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update inventory for product %d: %w", n.ProductID, err)
+		}
+	}
+	return nil
+}
+
+// restock puts needs back into inventory. Rows are changed in product ID
+// order, the order deductStock locks them in, so a void or return running
+// alongside a sale of the same products cannot deadlock with it.
+func restock(ctx context.Context, qtx *db.Queries, needs []stockNeed) error {
+	for _, n := range mergeStockNeeds(needs) {
+		_, err := qtx.AdjustInventoryQty(ctx, db.AdjustInventoryQtyParams{
+			ProductID: pgtype.Int4{Int32: n.ProductID, Valid: true},
+			Qty:       n.Qty.Numeric(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore inventory for product %d: %w", n.ProductID, err)
+		}
+	}
+	return nil
+}
+
+// Restock puts the given base-unit quantity of each product back into
+// inventory, locking rows in the same order as a sale does.
+func Restock(ctx context.Context, qtx *db.Queries, qty map[int32]quantity.Quantity) error {
+	needs := make([]stockNeed, 0, len(qty))
+	for productID, q := range qty {
+		needs = append(needs, stockNeed{ProductID: productID, Qty: q})
+	}
+	return restock(ctx, qtx, needs)
+}
+
+func isStockShortage(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "stock not sufficient")
+}
@@ -0,0 +1,138 @@
+package sale
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestMergeStockNeeds(t *testing.T) {
+	got := mergeStockNeeds([]stockNeed{
+		{ProductID: 7, Name: "Tea", Qty: quantity.New(1)},
+		{ProductID: 3, Name: "Coffee", Qty: quantity.MustParse("0.25")},
+		{ProductID: 7, Name: "Tea", Qty: quantity.New(4)},
+	})
+	want := []stockNeed{
+		{ProductID: 3, Name: "Coffee", Qty: quantity.MustParse("0.25")},
+		{ProductID: 7, Name: "Tea", Qty: quantity.New(5)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeStockNeeds = %+v, want %+v", got, want)
+	}
+}
+
+// TestConcurrentSalesNeverOversell fires more simultaneous sales at one
+// product than it has stock for. It runs when TEST_DATABASE_URL points at a
+// migrated Postgres database.
+func TestConcurrentSalesNeverOversell(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	const stock, sales = 5, 20
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer pool.Close()
+	queries := db.New(pool)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	user, err := queries.CreateUser(ctx, db.CreateUserParams{
+		Username:     "stock-test-" + suffix,
+		PasswordHash: "-",
+		Role:         "cashier",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	shift, err := queries.CreateShift(ctx, db.CreateShiftParams{
+		UserID:       user.ID,
+		OpeningFloat: money.Amount(0).Numeric(),
+	})
+	if err != nil {
+		t.Fatalf("CreateShift: %v", err)
+	}
+	product, err := queries.CreateProduct(ctx, db.CreateProductParams{
+		Sku:       pgtype.Text{String: "STOCK-TEST-" + suffix, Valid: true},
+		Name:      "Stock test " + suffix,
+		Price:     money.MustParse("1000").Numeric(),
+		CostPrice: money.MustParse("500").Numeric(),
+		TaxExempt: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	productIDPg := pgtype.Int4{Int32: product.ID, Valid: true}
+	if _, err := queries.CreateInventory(ctx, db.CreateInventoryParams{ProductID: productIDPg, Qty: quantity.New(stock).Numeric()}); err != nil {
+		t.Fatalf("CreateInventory: %v", err)
+	}
+
+	t.Cleanup(func() {
+		for _, q := range []string{
+			"DELETE FROM sales WHERE shift_id = $1",
+			"DELETE FROM shifts WHERE id = $1",
+		} {
+			pool.Exec(ctx, q, shift.ID)
+		}
+		pool.Exec(ctx, "DELETE FROM products WHERE id = $1", product.ID)
+		pool.Exec(ctx, "DELETE FROM users WHERE id = $1", user.ID)
+	})
+
+	svc := NewService(queries, pool, nil, Config{Invoice: InvoiceConfig{Prefix: "TST", CounterWidth: 5}})
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var sold int
+	start := make(chan struct{})
+	for i := 0; i < sales; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := svc.Create(ctx, user.ID, CreateSaleRequest{
+				Items:    []SaleItemRequest{{ProductID: product.ID, Qty: quantity.New(1)}},
+				Payments: []PaymentRequest{{Method: "cash", Amount: money.MustParse("1000")}},
+			})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				sold++
+			case !strings.HasPrefix(err.Error(), "stock not sufficient"):
+				t.Errorf("Create: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	inv, err := queries.GetInventoryByProduct(ctx, productIDPg)
+	if err != nil {
+		t.Fatalf("GetInventoryByProduct: %v", err)
+	}
+	left, err := quantity.FromNumeric(inv.Qty)
+	if err != nil {
+		t.Fatalf("FromNumeric: %v", err)
+	}
+	if left < 0 {
+		t.Fatalf("stock went negative: %s", left)
+	}
+	if sold != stock || left != 0 {
+		t.Errorf("sold %d with %s left, want %d sold and none left", sold, left, stock)
+	}
+}
@@ -0,0 +1,136 @@
+package sale
+
+import (
+	"errors"
+	"pos-system/internal/money"
+	"strings"
+)
+
+// PaymentMethodCash is the only tender that can be overpaid; change is
+// always given back from cash.
+const PaymentMethodCash = "cash"
+
+// PaymentMethodSplit is stored in sales.payment_method when a sale was paid
+// with more than one tender. The individual tenders live in sale_payments.
+const PaymentMethodSplit = "split"
+
+// PaymentMethodGiftCard pays from the balance of a gift card or store credit
+// card, given by the tender's CardNumber.
+const PaymentMethodGiftCard = "gift_card"
+
+type PaymentRequest struct {
+	Method string       `json:"method" binding:"required"`
+	Amount money.Amount `json:"amount" binding:"required"`
+	// CardNumber is the card a gift_card tender draws on
+	CardNumber string `json:"card_number,omitempty"`
+}
+
+type SalePaymentResponse struct {
+	ID     int32  `json:"id"`
+	Method string `json:"method"`
+	Amount string `json:"amount"`
+}
+
+// tenderResult is the outcome of settling a sale total against its payments.
+// Applied holds, per payment, the amount that actually went towards the total.
+// Rounding is the cash rounding added to the total; the applied amounts add
+// up to the total plus Rounding.
+type tenderResult struct {
+	Applied       []money.Amount
+	PaidAmount    money.Amount
+	ChangeAmount  money.Amount
+	Rounding      money.Amount
+	PaymentMethod string
+}
+
+// payments returns the tenders of the request, falling back to the single
+// paid_amount/payment_method pair used by older clients.
+func (r CreateSaleRequest) payments() []PaymentRequest {
+	if len(r.Payments) > 0 {
+		return r.Payments
+	}
+
+	method := r.PaymentMethod
+	if method == "" {
+		method = PaymentMethodCash
+	}
+	return []PaymentRequest{{Method: method, Amount: r.PaidAmount}}
+}
+
+func isCash(method string) bool {
+	return strings.EqualFold(method, PaymentMethodCash)
+}
+
+func isGiftCard(method string) bool {
+	return strings.EqualFold(method, PaymentMethodGiftCard)
+}
+
+// settleTenders checks that payments cover total and works out the change.
+// Non-cash tenders are charged exactly, so together they may not exceed the
+// total; any overpayment must come from cash and is returned as change.
+// Whatever is left for cash to pay is rounded first.
+func settleTenders(total money.Amount, payments []PaymentRequest, rounding CashRounding) (*tenderResult, error) {
+	if len(payments) == 0 {
+		return nil, errors.New("at least one payment is required")
+	}
+
+	var paid, nonCash money.Amount
+	hasCash := false
+	for _, p := range payments {
+		if p.Amount <= 0 {
+			return nil, errors.New("payment amount must be greater than zero")
+		}
+		if isGiftCard(p.Method) && strings.TrimSpace(p.CardNumber) == "" {
+			return nil, errors.New("gift card payment requires a card_number")
+		}
+		paid += p.Amount
+		if isCash(p.Method) {
+			hasCash = true
+		} else {
+			nonCash += p.Amount
+		}
+	}
+
+	if nonCash > total {
+		return nil, errors.New("non-cash payments exceed total amount")
+	}
+
+	var roundingAmount money.Amount
+	if cashDue := total - nonCash; hasCash && cashDue > 0 {
+		roundingAmount = rounding.round(cashDue) - cashDue
+	}
+	due := total + roundingAmount
+
+	change := paid - due
+	if change < 0 {
+		return nil, errors.New("paid amount is less than total amount")
+	}
+
+	// Take the change back out of the cash tenders, last one first
+	applied := make([]money.Amount, len(payments))
+	remaining := change
+	for i := len(payments) - 1; i >= 0; i-- {
+		applied[i] = payments[i].Amount
+		if remaining > 0 && isCash(payments[i].Method) {
+			take := remaining
+			if take > applied[i] {
+				take = applied[i]
+			}
+			applied[i] -= take
+			remaining -= take
+		}
+	}
+
+	method := payments[0].Method
+	if len(payments) > 1 {
+		method = PaymentMethodSplit
+	}
+
+	return &tenderResult{
+		Applied:       applied,
+		PaidAmount:    paid,
+		ChangeAmount:  change,
+		Rounding:      roundingAmount,
+		PaymentMethod: method,
+	}, nil
+}
@@ -0,0 +1,172 @@
+package sale
+
+import (
+	"pos-system/internal/money"
+	"reflect"
+	"testing"
+)
+
+func TestSettleTenders(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    money.Amount
+		payments []PaymentRequest
+		applied  []money.Amount
+		paid     money.Amount
+		change   money.Amount
+		method   string
+	}{
+		{
+			name:     "exact cash",
+			total:    money.New(25000),
+			payments: []PaymentRequest{{Method: "cash", Amount: money.New(25000)}},
+			applied:  []money.Amount{money.New(25000)},
+			paid:     money.New(25000),
+			method:   "cash",
+		},
+		{
+			name:     "cash with change",
+			total:    money.New(18500),
+			payments: []PaymentRequest{{Method: "cash", Amount: money.New(20000)}},
+			applied:  []money.Amount{money.New(18500)},
+			paid:     money.New(20000),
+			change:   money.New(1500),
+			method:   "cash",
+		},
+		{
+			name:  "card and cash, change from the cash",
+			total: money.New(50000),
+			payments: []PaymentRequest{
+				{Method: "card", Amount: money.New(30000)},
+				{Method: "cash", Amount: money.New(50000)},
+			},
+			applied: []money.Amount{money.New(30000), money.New(20000)},
+			paid:    money.New(80000),
+			change:  money.New(30000),
+			method:  "split",
+		},
+		{
+			name:  "change comes out of the last cash tender first",
+			total: money.New(60000),
+			payments: []PaymentRequest{
+				{Method: "cash", Amount: money.New(50000)},
+				{Method: "qris", Amount: money.New(5000)},
+				{Method: "cash", Amount: money.New(20000)},
+			},
+			applied: []money.Amount{money.New(50000), money.New(5000), money.New(5000)},
+			paid:    money.New(75000),
+			change:  money.New(15000),
+			method:  "split",
+		},
+		{
+			name:  "change larger than the last cash tender spills into earlier cash",
+			total: money.New(30000),
+			payments: []PaymentRequest{
+				{Method: "cash", Amount: money.New(50000)},
+				{Method: "cash", Amount: money.New(10000)},
+			},
+			applied: []money.Amount{money.New(30000), 0},
+			paid:    money.New(60000),
+			change:  money.New(30000),
+			method:  "split",
+		},
+		{
+			name:  "non-cash tenders only",
+			total: money.New(40000),
+			payments: []PaymentRequest{
+				{Method: "card", Amount: money.New(25000)},
+				{Method: "gift_card", Amount: money.New(15000), CardNumber: "GC-1"},
+			},
+			applied: []money.Amount{money.New(25000), money.New(15000)},
+			paid:    money.New(40000),
+			method:  "split",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := settleTenders(tt.total, tt.payments, CashRounding{})
+			if err != nil {
+				t.Fatalf("settleTenders: %v", err)
+			}
+			if !reflect.DeepEqual(got.Applied, tt.applied) {
+				t.Errorf("Applied = %v, want %v", got.Applied, tt.applied)
+			}
+			if got.PaidAmount != tt.paid || got.ChangeAmount != tt.change {
+				t.Errorf("paid, change = %s, %s, want %s, %s", got.PaidAmount, got.ChangeAmount, tt.paid, tt.change)
+			}
+			if got.PaymentMethod != tt.method {
+				t.Errorf("PaymentMethod = %q, want %q", got.PaymentMethod, tt.method)
+			}
+
+			var applied money.Amount
+			for _, a := range got.Applied {
+				applied += a
+			}
+			if applied != tt.total {
+				t.Errorf("applied amounts add up to %s, want the total %s", applied, tt.total)
+			}
+		})
+	}
+}
+
+func TestSettleTendersRejects(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    money.Amount
+		payments []PaymentRequest
+		want     string
+	}{
+		{
+			name:  "no payments",
+			total: money.New(10000),
+			want:  "at least one payment is required",
+		},
+		{
+			name:     "over-paying card",
+			total:    money.New(10000),
+			payments: []PaymentRequest{{Method: "card", Amount: money.New(12000)}},
+			want:     "non-cash payments exceed total amount",
+		},
+		{
+			name:  "non-cash tenders together over the total",
+			total: money.New(10000),
+			payments: []PaymentRequest{
+				{Method: "card", Amount: money.New(6000)},
+				{Method: "qris", Amount: money.New(6000)},
+				{Method: "cash", Amount: money.New(1000)},
+			},
+			want: "non-cash payments exceed total amount",
+		},
+		{
+			name:  "short",
+			total: money.New(10000),
+			payments: []PaymentRequest{
+				{Method: "card", Amount: money.New(4000)},
+				{Method: "cash", Amount: money.New(5000)},
+			},
+			want: "paid amount is less than total amount",
+		},
+		{
+			name:     "zero tender",
+			total:    money.New(10000),
+			payments: []PaymentRequest{{Method: "cash", Amount: 0}},
+			want:     "payment amount must be greater than zero",
+		},
+		{
+			name:     "gift card without a card number",
+			total:    money.New(10000),
+			payments: []PaymentRequest{{Method: "gift_card", Amount: money.New(10000)}},
+			want:     "gift card payment requires a card_number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := settleTenders(tt.total, tt.payments, CashRounding{})
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package sale
+
+import (
+	"context"
+	"pos-system/internal/money"
+	"pos-system/internal/voucher"
+	"strings"
+)
+
+// ValidateVoucherRequest asks whether a code would be accepted for a cart,
+// without redeeming it. Items are priced exactly as CreateSaleRequest's.
+type ValidateVoucherRequest struct {
+	Code     string            `json:"code" binding:"required"`
+	Customer string            `json:"customer"`
+	Items    []SaleItemRequest `json:"items" binding:"required"`
+}
+
+// VoucherValidationResponse reports the outcome. Reason explains why an
+// invalid code was refused. CartAmount is what the voucher is measured
+// against: the cart after promotions, before the voucher and exclusive tax.
+// TotalAmount is what the sale would come to with the voucher applied.
+type VoucherValidationResponse struct {
+	Code        string  `json:"code"`
+	Valid       bool    `json:"valid"`
+	Reason      *string `json:"reason,omitempty"`
+	CartAmount  string  `json:"cart_amount"`
+	Discount    string  `json:"discount"`
+	TotalAmount string  `json:"total_amount"`
+}
+
+// ValidateVoucher prices the cart the way Create would and checks the code
+// against it. A voucher that is refused is reported in the response; other
+// errors (an unknown product, say) are returned.
+func (s *Service) ValidateVoucher(ctx context.Context, req ValidateVoucherRequest) (*VoucherValidationResponse, error) {
+	resp := &VoucherValidationResponse{Code: voucher.NormalizeCode(req.Code)}
+
+	// Price without the voucher first so a refused code still reports the cart
+	priced, err := s.priceSale(ctx, s.queries, CreateSaleRequest{Items: req.Items}, false)
+	if err != nil {
+		return nil, err
+	}
+	var cartAmount, totalAmount money.Amount
+	for _, line := range priced.Lines {
+		cartAmount += line.Subtotal
+		if !line.TaxInclusive {
+			cartAmount -= line.Tax
+		}
+		totalAmount += line.Subtotal
+	}
+	resp.CartAmount = cartAmount.String()
+	resp.Discount = money.Amount(0).String()
+	resp.TotalAmount = totalAmount.String()
+
+	priced, err = s.priceSale(ctx, s.queries, CreateSaleRequest{
+		Items:           req.Items,
+		VoucherCode:     req.Code,
+		VoucherCustomer: req.Customer,
+	}, false)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "voucher") {
+			reason := err.Error()
+			resp.Reason = &reason
+			return resp, nil
+		}
+		return nil, err
+	}
+
+	totalAmount = 0
+	for _, line := range priced.Lines {
+		totalAmount += line.Subtotal
+	}
+	resp.Valid = true
+	resp.Discount = priced.VoucherDiscount.String()
+	resp.TotalAmount = totalAmount.String()
+	return resp, nil
+}
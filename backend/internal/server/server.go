@@ -3,10 +3,21 @@ package server
 import (
 	"pos-system/internal/auth"
 	"pos-system/internal/category"
+	"pos-system/internal/customer"
+	"pos-system/internal/export"
+	"pos-system/internal/giftcard"
 	"pos-system/internal/inventory"
+	"pos-system/internal/invoice"
 	"pos-system/internal/product"
+	"pos-system/internal/promotion"
+	"pos-system/internal/receipt"
 	"pos-system/internal/report"
+	"pos-system/internal/returns"
 	"pos-system/internal/sale"
+	"pos-system/internal/shift"
+	"pos-system/internal/table"
+	"pos-system/internal/tax"
+	"pos-system/internal/voucher"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -18,7 +29,18 @@ type Server struct {
 	productHandler  *product.Handler
 	inventoryHandler *inventory.Handler
 	categoryHandler *category.Handler
+	taxHandler      *tax.Handler
+	promotionHandler *promotion.Handler
+	voucherHandler   *voucher.Handler
+	customerHandler  *customer.Handler
+	shiftHandler     *shift.Handler
+	giftCardHandler  *giftcard.Handler
 	saleHandler     *sale.Handler
+	receiptHandler  *receipt.Handler
+	invoiceHandler  *invoice.Handler
+	exportHandler   *export.Handler
+	returnHandler   *returns.Handler
+	tableHandler    *table.Handler
 	reportHandler   *report.Handler
 	authService     *auth.Service
 	logger          *zap.Logger
@@ -29,7 +51,18 @@ func NewServer(
 	productHandler *product.Handler,
 	inventoryHandler *inventory.Handler,
 	categoryHandler *category.Handler,
+	taxHandler *tax.Handler,
+	promotionHandler *promotion.Handler,
+	voucherHandler *voucher.Handler,
+	customerHandler *customer.Handler,
+	shiftHandler *shift.Handler,
+	giftCardHandler *giftcard.Handler,
 	saleHandler *sale.Handler,
+	receiptHandler *receipt.Handler,
+	invoiceHandler *invoice.Handler,
+	exportHandler *export.Handler,
+	returnHandler *returns.Handler,
+	tableHandler *table.Handler,
 	reportHandler *report.Handler,
 	authService *auth.Service,
 	logger *zap.Logger,
@@ -51,7 +84,18 @@ func NewServer(
 		productHandler:   productHandler,
 		inventoryHandler: inventoryHandler,
 		categoryHandler:  categoryHandler,
+		taxHandler:       taxHandler,
+		promotionHandler: promotionHandler,
+		voucherHandler:   voucherHandler,
+		customerHandler:  customerHandler,
+		shiftHandler:     shiftHandler,
+		giftCardHandler:  giftCardHandler,
 		saleHandler:      saleHandler,
+		receiptHandler:   receiptHandler,
+		invoiceHandler:   invoiceHandler,
+		exportHandler:    exportHandler,
+		returnHandler:    returnHandler,
+		tableHandler:     tableHandler,
 		reportHandler:    reportHandler,
 		authService:      authService,
 		logger:           logger,
@@ -76,6 +120,9 @@ func (s *Server) setupRoutes() {
 			authGroup.POST("/login", s.authHandler.Login)
 		}
 
+		// Payment provider callbacks (public, signed by the provider)
+		v1.POST("/payments/callback/:provider", s.saleHandler.PaymentCallback)
+
 		// Protected routes
 		protected := v1.Group("")
 		protected.Use(auth.AuthMiddleware(s.authService))
@@ -89,15 +136,62 @@ func (s *Server) setupRoutes() {
 				categories.DELETE("/:id", auth.AdminOnlyMiddleware(), s.categoryHandler.Delete)
 			}
 
+			// Tax rates
+			taxRates := protected.Group("/tax-rates")
+			{
+				taxRates.GET("", s.taxHandler.List)
+				taxRates.POST("", auth.AdminOnlyMiddleware(), s.taxHandler.Create)
+				taxRates.PUT("/:id", auth.AdminOnlyMiddleware(), s.taxHandler.Update)
+				taxRates.DELETE("/:id", auth.AdminOnlyMiddleware(), s.taxHandler.Delete)
+			}
+
+			// Promotions
+			promotions := protected.Group("/promotions")
+			{
+				promotions.GET("", s.promotionHandler.List)
+				promotions.GET("/:id", s.promotionHandler.GetByID)
+				promotions.POST("", auth.AdminOnlyMiddleware(), s.promotionHandler.Create)
+				promotions.PUT("/:id", auth.AdminOnlyMiddleware(), s.promotionHandler.Update)
+				promotions.DELETE("/:id", auth.AdminOnlyMiddleware(), s.promotionHandler.Delete)
+			}
+
+			// Vouchers
+			vouchers := protected.Group("/vouchers")
+			{
+				vouchers.GET("", s.voucherHandler.List)
+				vouchers.POST("/validate", s.saleHandler.ValidateVoucher)
+				vouchers.GET("/:id", s.voucherHandler.GetByID)
+				vouchers.POST("", auth.AdminOnlyMiddleware(), s.voucherHandler.Create)
+				vouchers.PUT("/:id", auth.AdminOnlyMiddleware(), s.voucherHandler.Update)
+				vouchers.DELETE("/:id", auth.AdminOnlyMiddleware(), s.voucherHandler.Delete)
+			}
+
+			// Customers
+			customers := protected.Group("/customers")
+			{
+				customers.GET("", s.customerHandler.List)
+				customers.GET("/search", s.customerHandler.Search)
+				customers.GET("/:id", s.customerHandler.GetByID)
+				customers.GET("/:id/sales", s.customerHandler.History)
+				customers.POST("", s.customerHandler.Create)
+				customers.PUT("/:id", s.customerHandler.Update)
+				customers.DELETE("/:id", auth.AdminOnlyMiddleware(), s.customerHandler.Delete)
+			}
+
 			// Products
 			products := protected.Group("/products")
 			{
 				products.GET("", s.productHandler.List)
 				products.GET("/search", s.productHandler.Search)
+				products.GET("/barcode/:code", s.productHandler.Scan)
 				products.GET("/:id", s.productHandler.GetByID)
 				products.POST("", auth.AdminOnlyMiddleware(), s.productHandler.Create)
 				products.PUT("/:id", auth.AdminOnlyMiddleware(), s.productHandler.Update)
 				products.DELETE("/:id", auth.AdminOnlyMiddleware(), s.productHandler.Delete)
+				products.GET("/:id/units", s.productHandler.ListUnits)
+				products.POST("/:id/units", auth.AdminOnlyMiddleware(), s.productHandler.CreateUnit)
+				products.PUT("/:id/units/:unit_id", auth.AdminOnlyMiddleware(), s.productHandler.UpdateUnit)
+				products.DELETE("/:id/units/:unit_id", auth.AdminOnlyMiddleware(), s.productHandler.DeleteUnit)
 			}
 
 			// Inventory
@@ -108,12 +202,83 @@ func (s *Server) setupRoutes() {
 				inventory.POST("/adjust", auth.AdminOnlyMiddleware(), s.inventoryHandler.Adjust)
 			}
 
+			// Shifts
+			shifts := protected.Group("/shifts")
+			{
+				shifts.GET("", auth.AdminOnlyMiddleware(), s.shiftHandler.List)
+				shifts.POST("/open", s.shiftHandler.Open)
+				shifts.GET("/current", s.shiftHandler.Current)
+				shifts.GET("/:id", s.shiftHandler.GetByID)
+				shifts.POST("/:id/pay-in", s.shiftHandler.PayIn)
+				shifts.POST("/:id/pay-out", s.shiftHandler.PayOut)
+				shifts.POST("/:id/close", s.shiftHandler.Close)
+			}
+
+			// Gift cards and store credit
+			giftCards := protected.Group("/gift-cards")
+			{
+				giftCards.GET("", auth.AdminOnlyMiddleware(), s.giftCardHandler.List)
+				giftCards.GET("/balance", s.giftCardHandler.Balance)
+				giftCards.GET("/number/:number", auth.AdminOnlyMiddleware(), s.giftCardHandler.GetByNumber)
+				giftCards.GET("/:id", auth.AdminOnlyMiddleware(), s.giftCardHandler.GetByID)
+				giftCards.POST("", s.giftCardHandler.Issue)
+				giftCards.POST("/expire", auth.AdminOnlyMiddleware(), s.giftCardHandler.Expire)
+				giftCards.POST("/:id/reload", s.giftCardHandler.Reload)
+				giftCards.POST("/:id/activate", auth.AdminOnlyMiddleware(), s.giftCardHandler.Activate)
+				giftCards.POST("/:id/deactivate", auth.AdminOnlyMiddleware(), s.giftCardHandler.Deactivate)
+			}
+
 			// Sales
 			sales := protected.Group("/sales")
 			{
 				sales.POST("", s.saleHandler.Create)
 				sales.GET("", s.saleHandler.List)
+				sales.GET("/export", auth.AdminOnlyMiddleware(), s.exportHandler.Sales)
+				sales.GET("/held", s.saleHandler.ListHeld)
+				sales.POST("/held", s.saleHandler.Hold)
+				sales.POST("/held/:id/resume", s.saleHandler.Resume)
+				sales.POST("/held/:id/checkout", s.saleHandler.CheckoutHeld)
 				sales.GET("/:id", s.saleHandler.GetByID)
+				sales.GET("/:id/receipt", s.receiptHandler.Receipt)
+				sales.GET("/:id/invoice.pdf", s.invoiceHandler.PDF)
+				sales.GET("/:id/payment", s.saleHandler.Payment)
+				sales.POST("/:id/payment/cancel", s.saleHandler.CancelPayment)
+				sales.POST("/:id/void", auth.AdminOnlyMiddleware(), s.saleHandler.Void)
+			}
+
+			// Mock payment provider, for development
+			protected.POST("/payments/mock/:reference/:status", s.saleHandler.SettleMock)
+
+			// Returns
+			returnsGroup := protected.Group("/returns")
+			{
+				returnsGroup.POST("", s.returnHandler.Create)
+				returnsGroup.GET("", s.returnHandler.List)
+				returnsGroup.GET("/:id", s.returnHandler.GetByID)
+			}
+
+			// Dine-in tables, their orders and the kitchen queue
+			tables := protected.Group("/tables")
+			{
+				tables.GET("", s.tableHandler.ListTables)
+				tables.POST("", auth.AdminOnlyMiddleware(), s.tableHandler.CreateTable)
+				tables.PUT("/:id", auth.AdminOnlyMiddleware(), s.tableHandler.UpdateTable)
+				tables.POST("/:id/orders", s.tableHandler.OpenOrder)
+			}
+			tableOrders := protected.Group("/table-orders")
+			{
+				tableOrders.GET("/:id", s.tableHandler.GetOrder)
+				tableOrders.POST("/:id/items", s.tableHandler.AddItems)
+				tableOrders.DELETE("/:id/items/:item_id", s.tableHandler.RemoveItem)
+				tableOrders.POST("/:id/kitchen", s.tableHandler.SendToKitchen)
+				tableOrders.POST("/:id/bill", s.tableHandler.RequestBill)
+				tableOrders.POST("/:id/settle", s.tableHandler.Settle)
+				tableOrders.POST("/:id/cancel", s.tableHandler.Cancel)
+			}
+			kitchen := protected.Group("/kitchen")
+			{
+				kitchen.GET("/tickets", s.tableHandler.ListTickets)
+				kitchen.POST("/tickets/:id/done", s.tableHandler.CompleteTicket)
 			}
 
 			// Reports
@@ -122,6 +287,7 @@ func (s *Server) setupRoutes() {
 				reports.GET("/sales", s.reportHandler.GetSales)
 				reports.GET("/top-products", s.reportHandler.GetTopProducts)
 				reports.GET("/stats", s.reportHandler.GetStats)
+				reports.GET("/tax-summary", s.reportHandler.GetTaxSummary)
 			}
 		}
 	}
@@ -149,8 +315,9 @@ func corsMiddleware() gin.HandlerFunc {
 			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
 		}
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Idempotency-Key")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Total-Count, X-Next-Cursor, Content-Disposition")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
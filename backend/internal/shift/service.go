@@ -0,0 +1,483 @@
+package shift
+
+import (
+	"context"
+	"errors"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	MovementPayIn  = "pay_in"
+	MovementPayOut = "pay_out"
+)
+
+// methodCash is the tender the opening float and pay-ins/outs belong to
+const methodCash = "cash"
+
+type Service struct {
+	queries *db.Queries
+	db      *pgxpool.Pool
+}
+
+func NewService(queries *db.Queries, db *pgxpool.Pool) *Service {
+	return &Service{queries: queries, db: db}
+}
+
+type OpenShiftRequest struct {
+	OpeningFloat money.Amount `json:"opening_float"`
+	Note         string       `json:"note"`
+}
+
+type CashMovementRequest struct {
+	Amount money.Amount `json:"amount" binding:"required"`
+	Reason string       `json:"reason" binding:"required"`
+}
+
+// CloseShiftRequest carries what the cashier counted. CountedCash is the cash
+// in the drawer, float included. Counted may add totals for other methods,
+// e.g. from the card terminal's settlement slip; methods left out are not
+// reconciled.
+type CloseShiftRequest struct {
+	CountedCash *money.Amount           `json:"counted_cash" binding:"required"`
+	Counted     map[string]money.Amount `json:"counted"`
+	Note        string                  `json:"note"`
+}
+
+type ShiftResponse struct {
+	ID            int32                  `json:"id"`
+	UserID        int32                  `json:"user_id"`
+	OpeningFloat  string                 `json:"opening_float"`
+	OpeningNote   *string                `json:"opening_note"`
+	OpenedAt      string                 `json:"opened_at"`
+	ClosedAt      *string                `json:"closed_at"`
+	ExpectedCash  *string                `json:"expected_cash"`
+	CountedCash   *string                `json:"counted_cash"`
+	CashVariance  *string                `json:"cash_variance"`
+	ClosingNote   *string                `json:"closing_note"`
+	SaleCount     int64                  `json:"sale_count"`
+	SalesTotal    string                 `json:"sales_total"`
+	Tenders       []TenderResponse       `json:"tenders,omitempty"`
+	CashMovements []CashMovementResponse `json:"cash_movements,omitempty"`
+}
+
+// TenderResponse is the reconciliation of one payment method. While the
+// shift is open Expected is a running figure and Counted is empty.
+type TenderResponse struct {
+	Method   string  `json:"method"`
+	Expected string  `json:"expected"`
+	Counted  *string `json:"counted"`
+	Variance *string `json:"variance"`
+}
+
+type CashMovementResponse struct {
+	ID        int32  `json:"id"`
+	Type      string `json:"type"`
+	Amount    string `json:"amount"`
+	Reason    string `json:"reason"`
+	UserID    *int32 `json:"user_id"`
+	CreatedAt string `json:"created_at"`
+}
+
+// numericToString formats a NUMERIC money column with two decimals
+func numericToString(n pgtype.Numeric) string {
+	amount, err := money.FromNumeric(n)
+	if err != nil {
+		return "0.00"
+	}
+	return amount.String()
+}
+
+func optNumeric(n pgtype.Numeric) *string {
+	if !n.Valid {
+		return nil
+	}
+	s := numericToString(n)
+	return &s
+}
+
+func optText(t pgtype.Text) *string {
+	if !t.Valid {
+		return nil
+	}
+	return &t.String
+}
+
+func toResponse(s db.Shift) ShiftResponse {
+	resp := ShiftResponse{
+		ID:           s.ID,
+		UserID:       s.UserID,
+		OpeningFloat: numericToString(s.OpeningFloat),
+		OpeningNote:  optText(s.OpeningNote),
+		ExpectedCash: optNumeric(s.ExpectedCash),
+		CountedCash:  optNumeric(s.CountedCash),
+		CashVariance: optNumeric(s.CashVariance),
+		ClosingNote:  optText(s.ClosingNote),
+		SalesTotal:   "0.00",
+	}
+	if s.OpenedAt.Valid {
+		resp.OpenedAt = s.OpenedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if s.ClosedAt.Valid {
+		v := s.ClosedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		resp.ClosedAt = &v
+	}
+	return resp
+}
+
+// expectedTenders works out what each payment method should hold for the
+// shift, counting refunds the cashier made and gift cards they sold up to
+// until. Cash also carries the opening float and the pay-ins and pay-outs.
+func expectedTenders(ctx context.Context, q *db.Queries, s db.Shift, until time.Time) (map[string]money.Amount, error) {
+	openingFloat, err := money.FromNumeric(s.OpeningFloat)
+	if err != nil {
+		return nil, err
+	}
+	expected := map[string]money.Amount{methodCash: openingFloat}
+
+	payments, err := q.GetShiftPaymentsByMethod(ctx, pgtype.Int4{Int32: s.ID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range payments {
+		amount, err := money.FromNumeric(p.Amount)
+		if err != nil {
+			return nil, err
+		}
+		expected[strings.ToLower(p.Method)] += amount
+	}
+
+	refunds, err := q.GetShiftRefundsByMethod(ctx, db.GetShiftRefundsByMethodParams{
+		UserID:   pgtype.Int4{Int32: s.UserID, Valid: true},
+		OpenedAt: s.OpenedAt,
+		ClosedAt: pgtype.Timestamptz{Time: until, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range refunds {
+		amount, err := money.FromNumeric(r.Amount)
+		if err != nil {
+			return nil, err
+		}
+		expected[strings.ToLower(r.Method)] -= amount
+	}
+
+	giftCards, err := q.GetShiftGiftCardSalesByMethod(ctx, db.GetShiftGiftCardSalesByMethodParams{
+		UserID:   pgtype.Int4{Int32: s.UserID, Valid: true},
+		OpenedAt: s.OpenedAt,
+		ClosedAt: pgtype.Timestamptz{Time: until, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range giftCards {
+		amount, err := money.FromNumeric(g.Amount)
+		if err != nil {
+			return nil, err
+		}
+		expected[strings.ToLower(g.Method)] += amount
+	}
+
+	movements, err := q.ListShiftCashMovements(ctx, s.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range movements {
+		amount, err := money.FromNumeric(m.Amount)
+		if err != nil {
+			return nil, err
+		}
+		if m.Type == MovementPayIn {
+			expected[methodCash] += amount
+		} else {
+			expected[methodCash] -= amount
+		}
+	}
+
+	return expected, nil
+}
+
+func sortedMethods(amounts map[string]money.Amount) []string {
+	methods := make([]string, 0, len(amounts))
+	for method := range amounts {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// loadShift finds a shift the user may work on. Admins may work on any
+// cashier's shift.
+func loadShift(ctx context.Context, q *db.Queries, id, userID int32, isAdmin bool) (db.Shift, error) {
+	s, err := q.GetShiftForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Shift{}, errors.New("shift not found")
+		}
+		return db.Shift{}, err
+	}
+	if s.UserID != userID && !isAdmin {
+		return db.Shift{}, errors.New("shift belongs to another cashier")
+	}
+	if s.ClosedAt.Valid {
+		return db.Shift{}, errors.New("shift is already closed")
+	}
+	return s, nil
+}
+
+// Open starts a shift for the cashier. Only one shift per cashier may be
+// open at a time.
+func (s *Service) Open(ctx context.Context, userID int32, req OpenShiftRequest) (*ShiftResponse, error) {
+	if req.OpeningFloat < 0 {
+		return nil, errors.New("opening float cannot be negative")
+	}
+
+	var notePg pgtype.Text
+	if req.Note != "" {
+		notePg = pgtype.Text{String: req.Note, Valid: true}
+	}
+
+	shift, err := s.queries.CreateShift(ctx, db.CreateShiftParams{
+		UserID:       userID,
+		OpeningFloat: req.OpeningFloat.Numeric(),
+		OpeningNote:  notePg,
+	})
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "duplicate key") && strings.Contains(errMsg, "idx_shifts_open_user") {
+			return nil, errors.New("cashier already has an open shift")
+		}
+		return nil, err
+	}
+
+	return s.GetByID(ctx, shift.ID)
+}
+
+// Current returns the cashier's open shift with its running totals.
+func (s *Service) Current(ctx context.Context, userID int32) (*ShiftResponse, error) {
+	shift, err := s.queries.GetOpenShiftByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no open shift")
+		}
+		return nil, err
+	}
+	return s.GetByID(ctx, shift.ID)
+}
+
+func (s *Service) List(ctx context.Context, limit, offset int32) ([]ShiftResponse, error) {
+	shifts, err := s.queries.ListShifts(ctx, db.ListShiftsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ShiftResponse, len(shifts))
+	for i, shift := range shifts {
+		result[i] = toResponse(shift)
+		summary, err := s.queries.GetShiftSalesSummary(ctx, pgtype.Int4{Int32: shift.ID, Valid: true})
+		if err != nil {
+			return nil, err
+		}
+		result[i].SaleCount = summary.SaleCount
+		result[i].SalesTotal = numericToString(summary.TotalAmount)
+	}
+	return result, nil
+}
+
+// GetByID returns the shift with its sales summary, cash movements and
+// per-method reconciliation.
+func (s *Service) GetByID(ctx context.Context, id int32) (*ShiftResponse, error) {
+	shift, err := s.queries.GetShiftByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("shift not found")
+		}
+		return nil, err
+	}
+
+	resp := toResponse(shift)
+
+	summary, err := s.queries.GetShiftSalesSummary(ctx, pgtype.Int4{Int32: id, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	resp.SaleCount = summary.SaleCount
+	resp.SalesTotal = numericToString(summary.TotalAmount)
+
+	movements, err := s.queries.ListShiftCashMovements(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	resp.CashMovements = make([]CashMovementResponse, len(movements))
+	for i, m := range movements {
+		resp.CashMovements[i] = CashMovementResponse{
+			ID:     m.ID,
+			Type:   m.Type,
+			Amount: numericToString(m.Amount),
+			Reason: m.Reason,
+		}
+		if m.UserID.Valid {
+			resp.CashMovements[i].UserID = &m.UserID.Int32
+		}
+		if m.CreatedAt.Valid {
+			resp.CashMovements[i].CreatedAt = m.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+		}
+	}
+
+	// A closed shift reports what was recorded at closing
+	if shift.ClosedAt.Valid {
+		tenders, err := s.queries.ListShiftTenders(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		resp.Tenders = make([]TenderResponse, len(tenders))
+		for i, t := range tenders {
+			resp.Tenders[i] = TenderResponse{
+				Method:   t.Method,
+				Expected: numericToString(t.Expected),
+				Counted:  optNumeric(t.Counted),
+				Variance: optNumeric(t.Variance),
+			}
+		}
+		return &resp, nil
+	}
+
+	expected, err := expectedTenders(ctx, s.queries, shift, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, method := range sortedMethods(expected) {
+		resp.Tenders = append(resp.Tenders, TenderResponse{
+			Method:   method,
+			Expected: expected[method].String(),
+		})
+	}
+	return &resp, nil
+}
+
+// AddCashMovement records a pay-in or pay-out on an open shift.
+func (s *Service) AddCashMovement(ctx context.Context, id, userID int32, isAdmin bool, movementType string, req CashMovementRequest) (*ShiftResponse, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New("amount must be greater than zero")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	if _, err := loadShift(ctx, qtx, id, userID, isAdmin); err != nil {
+		return nil, err
+	}
+
+	if _, err := qtx.CreateShiftCashMovement(ctx, db.CreateShiftCashMovementParams{
+		ShiftID: id,
+		Type:    movementType,
+		Amount:  req.Amount.Numeric(),
+		Reason:  req.Reason,
+		UserID:  pgtype.Int4{Int32: userID, Valid: true},
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
+
+// Close ends the shift and records expected against counted amounts per
+// payment method. The shift row is locked first, so sales still being
+// written to it finish before the expected figures are taken.
+func (s *Service) Close(ctx context.Context, id, userID int32, isAdmin bool, req CloseShiftRequest) (*ShiftResponse, error) {
+	if *req.CountedCash < 0 {
+		return nil, errors.New("counted amounts cannot be negative")
+	}
+	counted := map[string]money.Amount{methodCash: *req.CountedCash}
+	for method, amount := range req.Counted {
+		method = strings.ToLower(strings.TrimSpace(method))
+		if method == methodCash {
+			return nil, errors.New("count cash with counted_cash")
+		}
+		if amount < 0 {
+			return nil, errors.New("counted amounts cannot be negative")
+		}
+		counted[method] = amount
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	shift, err := loadShift(ctx, qtx, id, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, err := expectedTenders(ctx, qtx, shift, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	// Methods counted without any takings still get a row
+	for method := range counted {
+		if _, ok := expected[method]; !ok {
+			expected[method] = 0
+		}
+	}
+
+	var notePg pgtype.Text
+	if req.Note != "" {
+		notePg = pgtype.Text{String: req.Note, Valid: true}
+	}
+
+	if _, err := qtx.CloseShift(ctx, db.CloseShiftParams{
+		ExpectedCash: expected[methodCash].Numeric(),
+		CountedCash:  counted[methodCash].Numeric(),
+		ClosingNote:  notePg,
+		ID:           id,
+	}); err != nil {
+		return nil, err
+	}
+
+	for _, method := range sortedMethods(expected) {
+		var countedPg, variancePg pgtype.Numeric
+		if amount, ok := counted[method]; ok {
+			countedPg = amount.Numeric()
+			variancePg = (amount - expected[method]).Numeric()
+		}
+		if _, err := qtx.CreateShiftTender(ctx, db.CreateShiftTenderParams{
+			ShiftID:  id,
+			Method:   method,
+			Expected: expected[method].Numeric(),
+			Counted:  countedPg,
+			Variance: variancePg,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(ctx, id)
+}
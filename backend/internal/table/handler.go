@@ -0,0 +1,305 @@
+package table
+
+import (
+	"net/http"
+	"pos-system/internal/sale"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func errorStatus(err error) int {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "table not found",
+		errMsg == "table order not found",
+		errMsg == "table order item not found",
+		errMsg == "kitchen ticket not found":
+		return http.StatusNotFound
+	case errMsg == "table name already exists",
+		errMsg == "table has an open order",
+		errMsg == "table is not active",
+		errMsg == "table is already occupied",
+		errMsg == "table order is not open",
+		errMsg == "table order is awaiting payment",
+		errMsg == "table order changed while settling, try again",
+		errMsg == "item has been sent to the kitchen and cannot be removed",
+		errMsg == "table order has items sent to the kitchen and cannot be cancelled",
+		errMsg == "kitchen ticket is already done":
+		return http.StatusConflict
+	case strings.HasPrefix(errMsg, "table "),
+		strings.HasPrefix(errMsg, "item "),
+		strings.HasPrefix(errMsg, "items "),
+		strings.HasPrefix(errMsg, "product "),
+		strings.HasPrefix(errMsg, "guests "),
+		strings.HasPrefix(errMsg, "ticket status"),
+		errMsg == "no new items to send to the kitchen":
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func parseID(c *gin.Context, param, name string) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param(param), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name + " id"})
+		return 0, false
+	}
+	return int32(id), true
+}
+
+func (h *Handler) ListTables(c *gin.Context) {
+	tables, err := h.service.ListTables(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tables)
+}
+
+func (h *Handler) CreateTable(c *gin.Context) {
+	var req TableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	table, err := h.service.CreateTable(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, table)
+}
+
+func (h *Handler) UpdateTable(c *gin.Context) {
+	id, ok := parseID(c, "id", "table")
+	if !ok {
+		return
+	}
+
+	var req TableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	table, err := h.service.UpdateTable(c.Request.Context(), id, req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, table)
+}
+
+func (h *Handler) OpenOrder(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, ok := parseID(c, "id", "table")
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req OpenOrderRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	order, err := h.service.OpenOrder(c.Request.Context(), userID.(int32), id, req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+func (h *Handler) GetOrder(c *gin.Context) {
+	id, ok := parseID(c, "id", "table order")
+	if !ok {
+		return
+	}
+
+	order, err := h.service.GetOrder(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *Handler) AddItems(c *gin.Context) {
+	id, ok := parseID(c, "id", "table order")
+	if !ok {
+		return
+	}
+
+	var req AddItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.service.AddItems(c.Request.Context(), id, req)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *Handler) RemoveItem(c *gin.Context) {
+	id, ok := parseID(c, "id", "table order")
+	if !ok {
+		return
+	}
+	itemID, ok := parseID(c, "item_id", "item")
+	if !ok {
+		return
+	}
+
+	order, err := h.service.RemoveItem(c.Request.Context(), id, itemID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *Handler) SendToKitchen(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, ok := parseID(c, "id", "table order")
+	if !ok {
+		return
+	}
+
+	ticket, err := h.service.SendToKitchen(c.Request.Context(), userID.(int32), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ticket)
+}
+
+func (h *Handler) RequestBill(c *gin.Context) {
+	id, ok := parseID(c, "id", "table order")
+	if !ok {
+		return
+	}
+
+	order, err := h.service.RequestBill(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+func (h *Handler) Cancel(c *gin.Context) {
+	id, ok := parseID(c, "id", "table order")
+	if !ok {
+		return
+	}
+
+	order, err := h.service.Cancel(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// Settle serves POST /table-orders/:id/settle, which answers like POST
+// /sales with the sale that paid for the order.
+func (h *Handler) Settle(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "user not authenticated"})
+		return
+	}
+
+	id, ok := parseID(c, "id", "table order")
+	if !ok {
+		return
+	}
+
+	var req SettleOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if key := c.GetHeader("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
+
+	sold, err := h.service.Settle(c.Request.Context(), userID.(int32), id, req)
+	if err != nil {
+		status := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = sale.CreateErrorStatus(err)
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sold)
+}
+
+func (h *Handler) ListTickets(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "50"), 10, 32)
+
+	tickets, err := h.service.ListTickets(c.Request.Context(), c.Query("status"), int32(limit))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tickets)
+}
+
+func (h *Handler) CompleteTicket(c *gin.Context) {
+	id, ok := parseID(c, "id", "kitchen ticket")
+	if !ok {
+		return
+	}
+
+	ticket, err := h.service.CompleteTicket(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ticket)
+}
@@ -0,0 +1,163 @@
+package table
+
+import (
+	"context"
+	"errors"
+	"pos-system/internal/db"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Kitchen ticket statuses
+const (
+	TicketStatusPending = "pending"
+	TicketStatusDone    = "done"
+)
+
+// TicketResponse is a batch of items sent to the kitchen together. TicketNo
+// counts the order's tickets from 1.
+type TicketResponse struct {
+	ID        int32               `json:"id"`
+	OrderID   int32               `json:"order_id"`
+	TableName string              `json:"table_name"`
+	TicketNo  int32               `json:"ticket_no"`
+	Status    string              `json:"status"`
+	SentBy    *int32              `json:"sent_by"`
+	Items     []OrderItemResponse `json:"items"`
+	CreatedAt string              `json:"created_at"`
+	DoneAt    *string             `json:"done_at"`
+}
+
+// ticketResponses fills in the items of each ticket.
+func (s *Service) ticketResponses(ctx context.Context, tickets []db.GetKitchenTicketRow) ([]TicketResponse, error) {
+	result := make([]TicketResponse, len(tickets))
+	if len(tickets) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int32, len(tickets))
+	byID := make(map[int32]*TicketResponse, len(tickets))
+	for i, t := range tickets {
+		ids[i] = t.ID
+		result[i] = TicketResponse{
+			ID:        t.ID,
+			OrderID:   t.OrderID,
+			TableName: t.TableName,
+			TicketNo:  t.TicketNo,
+			Status:    t.Status,
+			SentBy:    optInt4(t.UserID),
+			Items:     []OrderItemResponse{},
+			CreatedAt: *optTime(t.CreatedAt),
+			DoneAt:    optTime(t.DoneAt),
+		}
+		byID[t.ID] = &result[i]
+	}
+
+	items, err := s.queries.ListKitchenTicketItems(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if t, ok := byID[item.TicketID.Int32]; ok {
+			t.Items = append(t.Items, orderItemResponse(db.ListTableOrderItemsRow(item)))
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) getTicket(ctx context.Context, id int32) (*TicketResponse, error) {
+	ticket, err := s.queries.GetKitchenTicket(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("kitchen ticket not found")
+		}
+		return nil, err
+	}
+
+	result, err := s.ticketResponses(ctx, []db.GetKitchenTicketRow{ticket})
+	if err != nil {
+		return nil, err
+	}
+	return &result[0], nil
+}
+
+// SendToKitchen puts the order's unsent items on a new kitchen ticket.
+func (s *Service) SendToKitchen(ctx context.Context, userID, orderID int32) (*TicketResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	if _, err := lockOpenOrder(ctx, qtx, orderID); err != nil {
+		return nil, err
+	}
+
+	ticket, err := qtx.CreateKitchenTicket(ctx, db.CreateKitchenTicketParams{
+		OrderID: orderID,
+		UserID:  pgtype.Int4{Int32: userID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sent, err := qtx.SendTableOrderItems(ctx, db.SendTableOrderItemsParams{
+		OrderID:  orderID,
+		TicketID: pgtype.Int4{Int32: ticket.ID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sent == 0 {
+		return nil, errors.New("no new items to send to the kitchen")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.getTicket(ctx, ticket.ID)
+}
+
+// ListTickets returns the kitchen's tickets in status, oldest first. Status
+// defaults to pending.
+func (s *Service) ListTickets(ctx context.Context, status string, limit int32) ([]TicketResponse, error) {
+	if status == "" {
+		status = TicketStatusPending
+	}
+	if status != TicketStatusPending && status != TicketStatusDone {
+		return nil, errors.New("ticket status must be pending or done")
+	}
+
+	tickets, err := s.queries.ListKitchenTicketsByStatus(ctx, db.ListKitchenTicketsByStatusParams{
+		Status: status,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]db.GetKitchenTicketRow, len(tickets))
+	for i, t := range tickets {
+		rows[i] = db.GetKitchenTicketRow(t)
+	}
+	return s.ticketResponses(ctx, rows)
+}
+
+// CompleteTicket marks a ticket as done by the kitchen.
+func (s *Service) CompleteTicket(ctx context.Context, id int32) (*TicketResponse, error) {
+	if _, err := s.queries.CompleteKitchenTicket(ctx, id); err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+		if _, err := s.getTicket(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("kitchen ticket is already done")
+	}
+
+	return s.getTicket(ctx, id)
+}
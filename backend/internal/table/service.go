@@ -0,0 +1,668 @@
+// Package table runs dine-in service: orders opened on a table that collect
+// items over the meal, send them to the kitchen as tickets and are settled as
+// one sale at the end.
+package table
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/quantity"
+	"pos-system/internal/sale"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Table statuses, derived from the table's open order
+const (
+	StatusFree          = "free"
+	StatusOccupied      = "occupied"
+	StatusBillRequested = "bill_requested"
+)
+
+// Order statuses. An order is open until it is settled or cancelled;
+// bill_requested is still open and goes back to open when items are added.
+const (
+	OrderStatusOpen          = "open"
+	OrderStatusBillRequested = "bill_requested"
+	OrderStatusSettled       = "settled"
+	OrderStatusCancelled     = "cancelled"
+)
+
+type Service struct {
+	queries *db.Queries
+	db      *pgxpool.Pool
+	sales   *sale.Service
+}
+
+func NewService(queries *db.Queries, db *pgxpool.Pool, sales *sale.Service) *Service {
+	return &Service{queries: queries, db: db, sales: sales}
+}
+
+type TableRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Seats *int32 `json:"seats"`
+	// Active defaults to true; inactive tables cannot take orders
+	Active *bool `json:"active"`
+}
+
+type TableResponse struct {
+	ID     int32  `json:"id"`
+	Name   string `json:"name"`
+	Seats  *int32 `json:"seats"`
+	Active bool   `json:"active"`
+	// Status is free, occupied or bill_requested; the open order's id,
+	// guests and opening time are set unless the table is free
+	Status   string  `json:"status"`
+	OrderID  *int32  `json:"order_id"`
+	Guests   *int32  `json:"guests"`
+	OpenedAt *string `json:"opened_at"`
+}
+
+type OpenOrderRequest struct {
+	Guests *int32 `json:"guests"`
+	Note   string `json:"note"`
+}
+
+// OrderItemRequest adds Qty of a product to an order, in one of its
+// packaging units when UnitID is set. Note is for the kitchen, e.g. "no ice".
+type OrderItemRequest struct {
+	ProductID int32             `json:"product_id" binding:"required"`
+	Qty       quantity.Quantity `json:"qty" binding:"required"`
+	UnitID    *int32            `json:"unit_id"`
+	Note      string            `json:"note"`
+}
+
+type AddItemsRequest struct {
+	Items []OrderItemRequest `json:"items" binding:"required"`
+}
+
+// SettleOrderRequest carries the tenders for an order; its items come from
+// the order and are priced from the catalogue at settlement.
+type SettleOrderRequest struct {
+	Payments        []sale.PaymentRequest `json:"payments"`
+	PaidAmount      money.Amount          `json:"paid_amount"`
+	PaymentMethod   string                `json:"payment_method"`
+	VoucherCode     string                `json:"voucher_code"`
+	VoucherCustomer string                `json:"voucher_customer"`
+	CustomerID      *int32                `json:"customer_id"`
+	IdempotencyKey  string                `json:"idempotency_key"`
+}
+
+type OrderResponse struct {
+	ID        int32               `json:"id"`
+	TableID   int32               `json:"table_id"`
+	TableName string              `json:"table_name"`
+	Status    string              `json:"status"`
+	Guests    *int32              `json:"guests"`
+	Note      *string             `json:"note"`
+	OpenedBy  *int32              `json:"opened_by"`
+	SaleID    *int32              `json:"sale_id"`
+	Items     []OrderItemResponse `json:"items"`
+	Tickets   []TicketResponse    `json:"tickets"`
+	OpenedAt  string              `json:"opened_at"`
+	ClosedAt  *string             `json:"closed_at"`
+}
+
+// OrderItemResponse is an item of an order. TicketID is the kitchen ticket
+// it went out on, null while it has not been sent.
+type OrderItemResponse struct {
+	ID          int32             `json:"id"`
+	ProductID   int32             `json:"product_id"`
+	ProductName string            `json:"product_name"`
+	Qty         quantity.Quantity `json:"qty"`
+	UnitID      *int32            `json:"unit_id"`
+	Unit        *string           `json:"unit"`
+	Note        *string           `json:"note"`
+	TicketID    *int32            `json:"ticket_id"`
+	CreatedAt   string            `json:"created_at"`
+}
+
+func optInt4(v pgtype.Int4) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
+
+func optText(v pgtype.Text) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+func optTime(v pgtype.Timestamptz) *string {
+	if !v.Valid {
+		return nil
+	}
+	s := v.Time.Format("2006-01-02T15:04:05Z07:00")
+	return &s
+}
+
+func text(s string) pgtype.Text {
+	s = strings.TrimSpace(s)
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func int4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func isOpen(status string) bool {
+	return status == OrderStatusOpen || status == OrderStatusBillRequested
+}
+
+// tableStatus is what a table shows for the status of its open order, if
+// any.
+func tableStatus(orderStatus pgtype.Text) string {
+	switch {
+	case !orderStatus.Valid:
+		return StatusFree
+	case orderStatus.String == OrderStatusBillRequested:
+		return StatusBillRequested
+	default:
+		return StatusOccupied
+	}
+}
+
+func isDuplicate(err error, constraint string) bool {
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "duplicate key") && strings.Contains(errMsg, constraint)
+}
+
+func validateTable(req *TableRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("table name is required")
+	}
+	if req.Seats != nil && *req.Seats <= 0 {
+		return errors.New("table seats must be greater than zero")
+	}
+	return nil
+}
+
+func tableResponse(t db.DiningTable) TableResponse {
+	return TableResponse{
+		ID:     t.ID,
+		Name:   t.Name,
+		Seats:  optInt4(t.Seats),
+		Active: t.Active,
+		Status: StatusFree,
+	}
+}
+
+// ListTables returns every table with its status.
+func (s *Service) ListTables(ctx context.Context) ([]TableResponse, error) {
+	tables, err := s.queries.ListDiningTables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TableResponse, len(tables))
+	for i, t := range tables {
+		result[i] = TableResponse{
+			ID:       t.ID,
+			Name:     t.Name,
+			Seats:    optInt4(t.Seats),
+			Active:   t.Active,
+			Status:   tableStatus(t.OrderStatus),
+			OrderID:  optInt4(t.OrderID),
+			Guests:   optInt4(t.Guests),
+			OpenedAt: optTime(t.OpenedAt),
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) CreateTable(ctx context.Context, req TableRequest) (*TableResponse, error) {
+	if err := validateTable(&req); err != nil {
+		return nil, err
+	}
+
+	t, err := s.queries.CreateDiningTable(ctx, db.CreateDiningTableParams{
+		Name:  req.Name,
+		Seats: int4(req.Seats),
+	})
+	if err != nil {
+		if isDuplicate(err, "dining_tables_name_key") {
+			return nil, errors.New("table name already exists")
+		}
+		return nil, err
+	}
+
+	result := tableResponse(t)
+	return &result, nil
+}
+
+// UpdateTable renames, resizes or (de)activates a table. A table cannot be
+// deactivated while it has an open order.
+func (s *Service) UpdateTable(ctx context.Context, id int32, req TableRequest) (*TableResponse, error) {
+	if err := validateTable(&req); err != nil {
+		return nil, err
+	}
+
+	active := req.Active == nil || *req.Active
+	if !active {
+		_, err := s.queries.GetOpenTableOrderByTable(ctx, id)
+		if err == nil {
+			return nil, errors.New("table has an open order")
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return nil, err
+		}
+	}
+
+	t, err := s.queries.UpdateDiningTable(ctx, db.UpdateDiningTableParams{
+		ID:     id,
+		Name:   req.Name,
+		Seats:  int4(req.Seats),
+		Active: active,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("table not found")
+		}
+		if isDuplicate(err, "dining_tables_name_key") {
+			return nil, errors.New("table name already exists")
+		}
+		return nil, err
+	}
+
+	result := tableResponse(t)
+	return &result, nil
+}
+
+// OpenOrder seats guests at a free table.
+func (s *Service) OpenOrder(ctx context.Context, userID, tableID int32, req OpenOrderRequest) (*OrderResponse, error) {
+	if req.Guests != nil && *req.Guests <= 0 {
+		return nil, errors.New("guests must be greater than zero")
+	}
+
+	t, err := s.queries.GetDiningTable(ctx, tableID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("table not found")
+		}
+		return nil, err
+	}
+	if !t.Active {
+		return nil, errors.New("table is not active")
+	}
+
+	order, err := s.queries.CreateTableOrder(ctx, db.CreateTableOrderParams{
+		TableID: tableID,
+		UserID:  pgtype.Int4{Int32: userID, Valid: true},
+		Guests:  int4(req.Guests),
+		Note:    text(req.Note),
+	})
+	if err != nil {
+		if isDuplicate(err, "table_orders_open_table_key") {
+			return nil, errors.New("table is already occupied")
+		}
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, order.ID)
+}
+
+func (s *Service) GetOrder(ctx context.Context, id int32) (*OrderResponse, error) {
+	order, err := s.queries.GetTableOrder(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("table order not found")
+		}
+		return nil, err
+	}
+
+	items, err := s.queries.ListTableOrderItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	itemResponses := make([]OrderItemResponse, len(items))
+	for i, item := range items {
+		itemResponses[i] = orderItemResponse(item)
+	}
+
+	tickets, err := s.queries.ListKitchenTicketsByOrder(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	rows := make([]db.GetKitchenTicketRow, len(tickets))
+	for i, t := range tickets {
+		rows[i] = db.GetKitchenTicketRow(t)
+	}
+	ticketResponses, err := s.ticketResponses(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OrderResponse{
+		ID:        order.ID,
+		TableID:   order.TableID,
+		TableName: order.TableName,
+		Status:    order.Status,
+		Guests:    optInt4(order.Guests),
+		Note:      optText(order.Note),
+		OpenedBy:  optInt4(order.UserID),
+		SaleID:    optInt4(order.SaleID),
+		Items:     itemResponses,
+		Tickets:   ticketResponses,
+		OpenedAt:  *optTime(order.OpenedAt),
+		ClosedAt:  optTime(order.ClosedAt),
+	}, nil
+}
+
+func orderItemResponse(item db.ListTableOrderItemsRow) OrderItemResponse {
+	qty, _ := quantity.FromNumeric(item.Qty)
+	return OrderItemResponse{
+		ID:          item.ID,
+		ProductID:   item.ProductID,
+		ProductName: item.ProductName,
+		Qty:         qty,
+		UnitID:      optInt4(item.UnitID),
+		Unit:        optText(item.UnitName),
+		Note:        optText(item.Note),
+		TicketID:    optInt4(item.TicketID),
+		CreatedAt:   *optTime(item.CreatedAt),
+	}
+}
+
+// lockOpenOrder locks an order for a change to it, which must be open.
+func lockOpenOrder(ctx context.Context, qtx *db.Queries, id int32) (db.TableOrder, error) {
+	order, err := qtx.GetTableOrderForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return order, errors.New("table order not found")
+		}
+		return order, err
+	}
+	if !isOpen(order.Status) {
+		return order, errors.New("table order is not open")
+	}
+	if order.SaleID.Valid {
+		return order, errors.New("table order is awaiting payment")
+	}
+	return order, nil
+}
+
+// AddItems adds items to an open order. They are priced at settlement, so
+// only the product, unit and quantity are checked here.
+func (s *Service) AddItems(ctx context.Context, orderID int32, req AddItemsRequest) (*OrderResponse, error) {
+	if len(req.Items) == 0 {
+		return nil, errors.New("items must contain at least one item")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	if _, err := lockOpenOrder(ctx, qtx, orderID); err != nil {
+		return nil, err
+	}
+
+	for _, item := range req.Items {
+		unitName, err := checkItem(ctx, qtx, item)
+		if err != nil {
+			return nil, err
+		}
+		_, err = qtx.CreateTableOrderItem(ctx, db.CreateTableOrderItemParams{
+			OrderID:   orderID,
+			ProductID: item.ProductID,
+			Qty:       item.Qty.Numeric(),
+			UnitID:    int4(item.UnitID),
+			UnitName:  unitName,
+			Note:      text(item.Note),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := qtx.ReviseTableOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, orderID)
+}
+
+// checkItem checks an item the way a sale line is checked and returns the
+// name of its unit, if it has one.
+func checkItem(ctx context.Context, qtx *db.Queries, item OrderItemRequest) (pgtype.Text, error) {
+	if item.Qty <= 0 {
+		return pgtype.Text{}, errors.New("item qty must be greater than zero")
+	}
+
+	product, err := qtx.GetProductByID(ctx, item.ProductID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return pgtype.Text{}, fmt.Errorf("product %d not found", item.ProductID)
+		}
+		return pgtype.Text{}, err
+	}
+
+	var unitName pgtype.Text
+	base := item.Qty
+	if item.UnitID != nil {
+		unit, err := qtx.GetProductUnit(ctx, db.GetProductUnitParams{ID: *item.UnitID, ProductID: product.ID})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return pgtype.Text{}, fmt.Errorf("item unit %d not found for product: %s", *item.UnitID, product.Name)
+			}
+			return pgtype.Text{}, err
+		}
+		factor, err := quantity.FromNumeric(unit.Factor)
+		if err != nil {
+			return pgtype.Text{}, err
+		}
+		base = item.Qty.Mul(factor)
+		unitName = pgtype.Text{String: unit.Name, Valid: true}
+	}
+	if !base.Fits(int(product.QtyPrecision)) {
+		return pgtype.Text{}, fmt.Errorf("item qty %s for product: %s allows at most %d decimal places", item.Qty, product.Name, product.QtyPrecision)
+	}
+	return unitName, nil
+}
+
+// RemoveItem takes an item off an order. Items already sent to the kitchen
+// stay on it.
+func (s *Service) RemoveItem(ctx context.Context, orderID, itemID int32) (*OrderResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	if _, err := lockOpenOrder(ctx, qtx, orderID); err != nil {
+		return nil, err
+	}
+
+	item, err := qtx.GetTableOrderItem(ctx, db.GetTableOrderItemParams{ID: itemID, OrderID: orderID})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("table order item not found")
+		}
+		return nil, err
+	}
+	if item.TicketID.Valid {
+		return nil, errors.New("item has been sent to the kitchen and cannot be removed")
+	}
+
+	if err := qtx.DeleteTableOrderItem(ctx, itemID); err != nil {
+		return nil, err
+	}
+	if err := qtx.ReviseTableOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, orderID)
+}
+
+// RequestBill marks the table as waiting for its bill.
+func (s *Service) RequestBill(ctx context.Context, orderID int32) (*OrderResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	if _, err := lockOpenOrder(ctx, qtx, orderID); err != nil {
+		return nil, err
+	}
+	items, err := qtx.ListTableOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errors.New("table order has no items")
+	}
+
+	if _, err := qtx.RequestTableBill(ctx, orderID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, orderID)
+}
+
+// Cancel closes an order without a sale and frees its table. Once items
+// have gone to the kitchen the order has to be settled instead.
+func (s *Service) Cancel(ctx context.Context, orderID int32) (*OrderResponse, error) {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.queries.WithTx(tx)
+
+	if _, err := lockOpenOrder(ctx, qtx, orderID); err != nil {
+		return nil, err
+	}
+	tickets, err := qtx.ListKitchenTicketsByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) > 0 {
+		return nil, errors.New("table order has items sent to the kitchen and cannot be cancelled")
+	}
+
+	if _, err := qtx.CancelTableOrder(ctx, orderID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.GetOrder(ctx, orderID)
+}
+
+// Settle sells the order's items through sale.Service.Create and closes the
+// order with the sale in the same transaction, freeing the table. Items added
+// while the sale is being recorded make the settlement fail rather than go
+// unpaid. A sale left awaiting a QRIS or e-wallet payment holds the order,
+// bill requested and closed to changes, until the payment goes through and
+// settles it, or fails and frees it to be settled again.
+func (s *Service) Settle(ctx context.Context, userID, orderID int32, req SettleOrderRequest) (*sale.SaleResponse, error) {
+	order, err := s.queries.GetTableOrder(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("table order not found")
+		}
+		return nil, err
+	}
+	if !isOpen(order.Status) {
+		return nil, errors.New("table order is not open")
+	}
+	if order.SaleID.Valid {
+		return nil, errors.New("table order is awaiting payment")
+	}
+
+	items, err := s.queries.ListTableOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, errors.New("table order has no items")
+	}
+	saleItems, err := saleItems(items)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.sales.CreateWith(ctx, userID, sale.CreateSaleRequest{
+		Items:           saleItems,
+		Payments:        req.Payments,
+		PaidAmount:      req.PaidAmount,
+		PaymentMethod:   req.PaymentMethod,
+		VoucherCode:     req.VoucherCode,
+		VoucherCustomer: req.VoucherCustomer,
+		CustomerID:      req.CustomerID,
+		IdempotencyKey:  req.IdempotencyKey,
+	}, func(ctx context.Context, qtx *db.Queries, sold *sale.SaleResponse) error {
+		var err error
+		if sold.PaymentStatus == sale.PaymentStatusPending {
+			_, err = qtx.HoldTableOrderForPayment(ctx, db.HoldTableOrderForPaymentParams{
+				ID:       orderID,
+				SaleID:   pgtype.Int4{Int32: sold.ID, Valid: true},
+				Revision: order.Revision,
+			})
+		} else {
+			_, err = qtx.SettleTableOrder(ctx, db.SettleTableOrderParams{
+				ID:       orderID,
+				SaleID:   pgtype.Int4{Int32: sold.ID, Valid: true},
+				Revision: order.Revision,
+			})
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("table order changed while settling, try again")
+		}
+		return err
+	})
+}
+
+// saleItems turns an order's items into sale lines at catalogue prices.
+func saleItems(items []db.ListTableOrderItemsRow) ([]sale.SaleItemRequest, error) {
+	result := make([]sale.SaleItemRequest, len(items))
+	for i, item := range items {
+		// Selling in the base unit instead would charge for the wrong amount
+		if item.UnitName.Valid && !item.UnitID.Valid {
+			return nil, fmt.Errorf("item unit %s for product: %s no longer exists", item.UnitName.String, item.ProductName)
+		}
+
+		qty, err := quantity.FromNumeric(item.Qty)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = sale.SaleItemRequest{
+			ProductID: item.ProductID,
+			Qty:       qty,
+			UnitID:    optInt4(item.UnitID),
+		}
+	}
+	return result, nil
+}
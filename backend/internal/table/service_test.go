@@ -0,0 +1,392 @@
+package table
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"pos-system/internal/payment"
+	"pos-system/internal/quantity"
+	"pos-system/internal/sale"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestTableStatus(t *testing.T) {
+	tests := []struct {
+		order pgtype.Text
+		want  string
+	}{
+		{pgtype.Text{}, StatusFree},
+		{pgtype.Text{String: OrderStatusOpen, Valid: true}, StatusOccupied},
+		{pgtype.Text{String: OrderStatusBillRequested, Valid: true}, StatusBillRequested},
+	}
+	for _, tt := range tests {
+		if got := tableStatus(tt.order); got != tt.want {
+			t.Errorf("tableStatus(%+v) = %q, want %q", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestSaleItems(t *testing.T) {
+	pack := pgtype.Text{String: "pack", Valid: true}
+	items := []db.ListTableOrderItemsRow{
+		{ProductID: 3, ProductName: "Nasi Goreng", Qty: quantity.New(2).Numeric()},
+		{ProductID: 7, ProductName: "Es Teh", Qty: quantity.MustParse("1.5").Numeric(),
+			UnitID: pgtype.Int4{Int32: 4, Valid: true}, UnitName: pack},
+	}
+
+	got, err := saleItems(items)
+	if err != nil {
+		t.Fatalf("saleItems: %v", err)
+	}
+	unitID := int32(4)
+	want := []sale.SaleItemRequest{
+		{ProductID: 3, Qty: quantity.New(2)},
+		{ProductID: 7, Qty: quantity.MustParse("1.5"), UnitID: &unitID},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("saleItems = %+v, want %+v", got, want)
+	}
+
+	// The unit was deleted after the item was ordered
+	items[1].UnitID = pgtype.Int4{}
+	if _, err := saleItems(items); err == nil || err.Error() != "item unit pack for product: Es Teh no longer exists" {
+		t.Errorf("saleItems with a deleted unit: err = %v", err)
+	}
+}
+
+// fixture is a cashier with an open shift, a stocked product and a free
+// table, all removed again when the test ends. It needs TEST_DATABASE_URL to
+// point at a migrated Postgres database and skips the test otherwise.
+type fixture struct {
+	ctx     context.Context
+	pool    *pgxpool.Pool
+	queries *db.Queries
+	sales   *sale.Service
+	svc     *Service
+	userID  int32
+	product db.Product
+	table   db.DiningTable
+}
+
+func newFixture(t *testing.T, payments payment.Provider) *fixture {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	queries := db.New(pool)
+
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	user, err := queries.CreateUser(ctx, db.CreateUserParams{
+		Username:     "table-test-" + suffix,
+		PasswordHash: "-",
+		Role:         "cashier",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	shift, err := queries.CreateShift(ctx, db.CreateShiftParams{
+		UserID:       user.ID,
+		OpeningFloat: money.Amount(0).Numeric(),
+	})
+	if err != nil {
+		t.Fatalf("CreateShift: %v", err)
+	}
+	product, err := queries.CreateProduct(ctx, db.CreateProductParams{
+		Sku:       pgtype.Text{String: "TABLE-TEST-" + suffix, Valid: true},
+		Name:      "Table test " + suffix,
+		Price:     money.MustParse("1000").Numeric(),
+		CostPrice: money.MustParse("500").Numeric(),
+		TaxExempt: true,
+	})
+	if err != nil {
+		t.Fatalf("CreateProduct: %v", err)
+	}
+	if _, err := queries.CreateInventory(ctx, db.CreateInventoryParams{
+		ProductID: pgtype.Int4{Int32: product.ID, Valid: true},
+		Qty:       quantity.New(100).Numeric(),
+	}); err != nil {
+		t.Fatalf("CreateInventory: %v", err)
+	}
+	table, err := queries.CreateDiningTable(ctx, db.CreateDiningTableParams{Name: "Table test " + suffix})
+	if err != nil {
+		t.Fatalf("CreateDiningTable: %v", err)
+	}
+
+	t.Cleanup(func() {
+		for _, q := range []string{
+			"DELETE FROM table_order_items WHERE order_id IN (SELECT id FROM table_orders WHERE table_id = $1)",
+			"DELETE FROM kitchen_tickets WHERE order_id IN (SELECT id FROM table_orders WHERE table_id = $1)",
+			"DELETE FROM table_orders WHERE table_id = $1",
+			"DELETE FROM dining_tables WHERE id = $1",
+		} {
+			pool.Exec(ctx, q, table.ID)
+		}
+		for _, q := range []string{
+			"DELETE FROM payment_charges WHERE sale_id IN (SELECT id FROM sales WHERE shift_id = $1)",
+			"DELETE FROM sales WHERE shift_id = $1",
+			"DELETE FROM shifts WHERE id = $1",
+		} {
+			pool.Exec(ctx, q, shift.ID)
+		}
+		pool.Exec(ctx, "DELETE FROM products WHERE id = $1", product.ID)
+		pool.Exec(ctx, "DELETE FROM users WHERE id = $1", user.ID)
+	})
+
+	sales := sale.NewService(queries, pool, nil, sale.Config{
+		Invoice:   sale.InvoiceConfig{Prefix: "TST", CounterWidth: 5},
+		Payments:  payments,
+		ChargeTTL: time.Minute,
+	})
+	return &fixture{
+		ctx:     ctx,
+		pool:    pool,
+		queries: queries,
+		sales:   sales,
+		svc:     NewService(queries, pool, sales),
+		userID:  user.ID,
+		product: product,
+		table:   table,
+	}
+}
+
+// openWithItems opens an order on the fixture's table with qty of its product.
+func (f *fixture) openWithItems(t *testing.T, qty int64) *OrderResponse {
+	order, err := f.svc.OpenOrder(f.ctx, f.userID, f.table.ID, OpenOrderRequest{})
+	if err != nil {
+		t.Fatalf("OpenOrder: %v", err)
+	}
+	order, err = f.svc.AddItems(f.ctx, order.ID, AddItemsRequest{
+		Items: []OrderItemRequest{{ProductID: f.product.ID, Qty: quantity.New(qty)}},
+	})
+	if err != nil {
+		t.Fatalf("AddItems: %v", err)
+	}
+	return order
+}
+
+func TestOneOpenOrderPerTable(t *testing.T) {
+	f := newFixture(t, nil)
+
+	const openers = 10
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var opened int
+	start := make(chan struct{})
+	for i := 0; i < openers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := f.svc.OpenOrder(f.ctx, f.userID, f.table.ID, OpenOrderRequest{})
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				opened++
+			case err.Error() != "table is already occupied":
+				t.Errorf("OpenOrder: %v", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if opened != 1 {
+		t.Errorf("%d orders opened on one table, want 1", opened)
+	}
+}
+
+func TestKitchenTicketNumbers(t *testing.T) {
+	f := newFixture(t, nil)
+	order := f.openWithItems(t, 2)
+
+	first, err := f.svc.SendToKitchen(f.ctx, f.userID, order.ID)
+	if err != nil {
+		t.Fatalf("SendToKitchen: %v", err)
+	}
+	if _, err := f.svc.SendToKitchen(f.ctx, f.userID, order.ID); err == nil || err.Error() != "no new items to send to the kitchen" {
+		t.Errorf("SendToKitchen with nothing new: err = %v", err)
+	}
+	if _, err := f.svc.AddItems(f.ctx, order.ID, AddItemsRequest{
+		Items: []OrderItemRequest{{ProductID: f.product.ID, Qty: quantity.New(1)}},
+	}); err != nil {
+		t.Fatalf("AddItems: %v", err)
+	}
+	second, err := f.svc.SendToKitchen(f.ctx, f.userID, order.ID)
+	if err != nil {
+		t.Fatalf("SendToKitchen: %v", err)
+	}
+
+	if first.TicketNo != 1 || second.TicketNo != 2 {
+		t.Errorf("ticket numbers %d, %d, want 1, 2", first.TicketNo, second.TicketNo)
+	}
+	if len(first.Items) != 1 || len(second.Items) != 1 {
+		t.Errorf("tickets carry %d and %d items, want 1 each", len(first.Items), len(second.Items))
+	}
+}
+
+func TestCancelRefusedAfterKitchenTicket(t *testing.T) {
+	f := newFixture(t, nil)
+	order := f.openWithItems(t, 1)
+
+	if _, err := f.svc.SendToKitchen(f.ctx, f.userID, order.ID); err != nil {
+		t.Fatalf("SendToKitchen: %v", err)
+	}
+	_, err := f.svc.Cancel(f.ctx, order.ID)
+	if err == nil || err.Error() != "table order has items sent to the kitchen and cannot be cancelled" {
+		t.Fatalf("Cancel after a kitchen ticket: err = %v", err)
+	}
+
+	got, err := f.svc.GetOrder(f.ctx, order.ID)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if got.Status != OrderStatusOpen {
+		t.Errorf("order status %q after a refused cancel, want %q", got.Status, OrderStatusOpen)
+	}
+}
+
+// TestSettleRejectsItemsAddedMeanwhile adds an item while Settle is recording
+// the sale. The settlement must fail and record no sale, so the new item is
+// not left unpaid on a closed order.
+func TestSettleRejectsItemsAddedMeanwhile(t *testing.T) {
+	f := newFixture(t, nil)
+	order := f.openWithItems(t, 1)
+
+	// Hold the order row so Settle blocks on closing the order
+	tx, err := f.pool.Begin(f.ctx)
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tx.Rollback(f.ctx)
+	qtx := f.queries.WithTx(tx)
+	if _, err := qtx.GetTableOrderForUpdate(f.ctx, order.ID); err != nil {
+		t.Fatalf("GetTableOrderForUpdate: %v", err)
+	}
+
+	settled := make(chan error, 1)
+	go func() {
+		_, err := f.svc.Settle(f.ctx, f.userID, order.ID, SettleOrderRequest{
+			Payments: []sale.PaymentRequest{{Method: "cash", Amount: money.MustParse("1000")}},
+		})
+		settled <- err
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var waiting int
+		err := f.pool.QueryRow(f.ctx, "SELECT count(*) FROM pg_stat_activity WHERE wait_event_type = 'Lock' AND query LIKE '%UPDATE table_orders%'").Scan(&waiting)
+		if err != nil {
+			t.Fatalf("pg_stat_activity: %v", err)
+		}
+		if waiting > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Settle never waited on the order row")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := qtx.CreateTableOrderItem(f.ctx, db.CreateTableOrderItemParams{
+		OrderID:   order.ID,
+		ProductID: f.product.ID,
+		Qty:       quantity.New(1).Numeric(),
+	}); err != nil {
+		t.Fatalf("CreateTableOrderItem: %v", err)
+	}
+	if err := qtx.ReviseTableOrder(f.ctx, order.ID); err != nil {
+		t.Fatalf("ReviseTableOrder: %v", err)
+	}
+	if err := tx.Commit(f.ctx); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	if err := <-settled; err == nil || err.Error() != "table order changed while settling, try again" {
+		t.Fatalf("Settle with items added meanwhile: err = %v", err)
+	}
+
+	got, err := f.svc.GetOrder(f.ctx, order.ID)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if got.Status != OrderStatusOpen || got.SaleID != nil || len(got.Items) != 2 {
+		t.Errorf("order after a failed settle: status %q, sale %v, %d items; want open, no sale, 2 items", got.Status, got.SaleID, len(got.Items))
+	}
+	var sales int
+	if err := f.pool.QueryRow(f.ctx, "SELECT count(*) FROM sales WHERE user_id = $1", f.userID).Scan(&sales); err != nil {
+		t.Fatalf("count sales: %v", err)
+	}
+	if sales != 0 {
+		t.Errorf("%d sales recorded by a failed settle, want 0", sales)
+	}
+}
+
+// TestSettleWithPendingPayment settles an order with QRIS. The order stays
+// bill requested while the charge is pending, is freed when the charge is
+// cancelled and closes only once a payment goes through.
+func TestSettleWithPendingPayment(t *testing.T) {
+	f := newFixture(t, payment.NewMock("secret"))
+	order := f.openWithItems(t, 1)
+	qris := SettleOrderRequest{Payments: []sale.PaymentRequest{{Method: payment.MethodQRIS, Amount: money.MustParse("1000")}}}
+
+	status := func(want string, wantSale bool) {
+		t.Helper()
+		got, err := f.svc.GetOrder(f.ctx, order.ID)
+		if err != nil {
+			t.Fatalf("GetOrder: %v", err)
+		}
+		if got.Status != want || (got.SaleID != nil) != wantSale {
+			t.Fatalf("order status %q with sale %v, want %q with sale: %v", got.Status, got.SaleID, want, wantSale)
+		}
+	}
+
+	pending, err := f.svc.Settle(f.ctx, f.userID, order.ID, qris)
+	if err != nil {
+		t.Fatalf("Settle: %v", err)
+	}
+	if pending.PaymentStatus != sale.PaymentStatusPending {
+		t.Fatalf("sale payment status %q, want pending", pending.PaymentStatus)
+	}
+	status(OrderStatusBillRequested, true)
+
+	if _, err := f.svc.AddItems(f.ctx, order.ID, AddItemsRequest{
+		Items: []OrderItemRequest{{ProductID: f.product.ID, Qty: quantity.New(1)}},
+	}); err == nil || err.Error() != "table order is awaiting payment" {
+		t.Errorf("AddItems while awaiting payment: err = %v", err)
+	}
+	if _, err := f.svc.Settle(f.ctx, f.userID, order.ID, qris); err == nil || err.Error() != "table order is awaiting payment" {
+		t.Errorf("Settle while awaiting payment: err = %v", err)
+	}
+
+	// The customer walks away from the QR code
+	if _, err := f.sales.CancelPayment(f.ctx, pending.ID); err != nil {
+		t.Fatalf("CancelPayment: %v", err)
+	}
+	status(OrderStatusBillRequested, false)
+
+	// and pays on the second try
+	retry, err := f.svc.Settle(f.ctx, f.userID, order.ID, qris)
+	if err != nil {
+		t.Fatalf("Settle again: %v", err)
+	}
+	if _, err := f.sales.SettleMock(f.ctx, retry.Payment.Reference, payment.StatusPaid); err != nil {
+		t.Fatalf("SettleMock: %v", err)
+	}
+	status(OrderStatusSettled, true)
+}
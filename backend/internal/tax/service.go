@@ -0,0 +1,136 @@
+package tax
+
+import (
+	"context"
+	"errors"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type Service struct {
+	queries *db.Queries
+}
+
+func NewService(queries *db.Queries) *Service {
+	return &Service{queries: queries}
+}
+
+// CreateTaxRateRequest takes the rate as a percentage, e.g. "11.00". An
+// inclusive rate means prices of products taxed at it already contain the
+// tax.
+type CreateTaxRateRequest struct {
+	Name      string       `json:"name" binding:"required"`
+	Rate      money.Amount `json:"rate"`
+	Inclusive bool         `json:"inclusive"`
+}
+
+type UpdateTaxRateRequest struct {
+	Name      string       `json:"name" binding:"required"`
+	Rate      money.Amount `json:"rate"`
+	Inclusive bool         `json:"inclusive"`
+}
+
+type TaxRateResponse struct {
+	ID        int32  `json:"id"`
+	Name      string `json:"name"`
+	Rate      string `json:"rate"`
+	Inclusive bool   `json:"inclusive"`
+	CreatedAt string `json:"created_at"`
+}
+
+func validateRate(rate money.Amount) error {
+	if rate < 0 || rate > money.New(100) {
+		return errors.New("tax rate must be between 0 and 100")
+	}
+	return nil
+}
+
+func toResponse(rate db.TaxRate) TaxRateResponse {
+	var createdAt string
+	if rate.CreatedAt.Valid {
+		createdAt = rate.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	var percent string
+	if amount, err := money.FromNumeric(rate.Rate); err == nil {
+		percent = amount.String()
+	}
+
+	return TaxRateResponse{
+		ID:        rate.ID,
+		Name:      rate.Name,
+		Rate:      percent,
+		Inclusive: rate.Inclusive,
+		CreatedAt: createdAt,
+	}
+}
+
+func (s *Service) List(ctx context.Context) ([]TaxRateResponse, error) {
+	rates, err := s.queries.ListTaxRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TaxRateResponse, len(rates))
+	for i, rate := range rates {
+		result[i] = toResponse(rate)
+	}
+
+	return result, nil
+}
+
+func (s *Service) Create(ctx context.Context, req CreateTaxRateRequest) (*TaxRateResponse, error) {
+	if err := validateRate(req.Rate); err != nil {
+		return nil, err
+	}
+
+	rate, err := s.queries.CreateTaxRate(ctx, db.CreateTaxRateParams{
+		Name:      req.Name,
+		Rate:      req.Rate.Numeric(),
+		Inclusive: req.Inclusive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := toResponse(rate)
+	return &result, nil
+}
+
+// Update changes a rate for future sales only; sale lines keep the rate they
+// were charged at.
+func (s *Service) Update(ctx context.Context, id int32, req UpdateTaxRateRequest) (*TaxRateResponse, error) {
+	if err := validateRate(req.Rate); err != nil {
+		return nil, err
+	}
+
+	rate, err := s.queries.UpdateTaxRate(ctx, db.UpdateTaxRateParams{
+		ID:        id,
+		Name:      req.Name,
+		Rate:      req.Rate.Numeric(),
+		Inclusive: req.Inclusive,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("tax rate not found")
+		}
+		return nil, err
+	}
+
+	result := toResponse(rate)
+	return &result, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int32) error {
+	if _, err := s.queries.GetTaxRateByID(ctx, id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("tax rate not found")
+		}
+		return err
+	}
+
+	// Products and categories using the rate fall back to no tax (ON DELETE SET NULL)
+	return s.queries.DeleteTaxRate(ctx, id)
+}
@@ -0,0 +1,17 @@
+package tax
+
+import "pos-system/internal/money"
+
+// Calculate returns the tax on a line amount at ratePercent (e.g. 11.00 for
+// 11% PPN). With an exclusive rate the tax comes on top of amount; with an
+// inclusive rate amount already contains the tax and the result is the part
+// of it above the net price. The tax is rounded half away from zero to the
+// cent.
+func Calculate(amount, ratePercent money.Amount, inclusive bool) money.Amount {
+	// ratePercent holds hundredths of a percent, so 11% is 1100 of 10000
+	basisPoints := ratePercent.Cents()
+	if inclusive {
+		return amount - amount.MulDiv(10000, 10000+basisPoints)
+	}
+	return amount.MulDiv(basisPoints, 10000)
+}
@@ -0,0 +1,59 @@
+package tax
+
+import (
+	"pos-system/internal/money"
+	"testing"
+)
+
+func TestCalculate(t *testing.T) {
+	ppn := money.MustParse("11")
+
+	tests := []struct {
+		name      string
+		amount    string
+		rate      money.Amount
+		inclusive bool
+		want      string
+	}{
+		{"exclusive", "10000", ppn, false, "1100"},
+		{"exclusive rounds half up", "15000.50", ppn, false, "1650.06"},
+		{"exclusive rounds down below half", "0.04", ppn, false, "0"},
+		{"exclusive rounds the half cent up", "0.50", ppn, false, "0.06"},
+		{"inclusive", "11100", ppn, true, "1100"},
+		{"inclusive rounds the net price", "10000", ppn, true, "990.99"},
+		{"inclusive of a cent", "0.01", ppn, true, "0"},
+		{"fractional rate", "10000", money.MustParse("2.5"), false, "250"},
+		{"zero rate", "10000", 0, false, "0"},
+		{"zero rate inclusive", "10000", 0, true, "0"},
+		{"negative amount", "-10000", ppn, false, "-1100"},
+		{"negative amount inclusive", "-11100", ppn, true, "-1100"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Calculate(money.MustParse(tt.amount), tt.rate, tt.inclusive)
+			if got != money.MustParse(tt.want) {
+				t.Errorf("Calculate(%s, %s, %v) = %s, want %s", tt.amount, tt.rate, tt.inclusive, got, tt.want)
+			}
+		})
+	}
+}
+
+// An inclusive price splits into a net amount and tax that add back up to
+// it exactly, so no cent is lost to rounding.
+func TestCalculateInclusiveAddsUp(t *testing.T) {
+	ppn := money.MustParse("11")
+	for cents := int64(1); cents <= 100000; cents += 37 {
+		amount := money.FromCents(cents)
+		tax := Calculate(amount, ppn, true)
+		net := amount - tax
+		if tax < 0 || net < 0 {
+			t.Fatalf("Calculate(%s) split into net %s and tax %s", amount, net, tax)
+		}
+		// The tax is what the rate would put on top of the net price, to
+		// within the cent lost rounding the net price
+		if diff := Calculate(net, ppn, false) - tax; diff < -1 || diff > 1 {
+			t.Fatalf("Calculate(%s) = %s, but %s on the net %s", amount, tax, Calculate(net, ppn, false), net)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package voucher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Check finds the voucher for code and works out what it takes off a cart
+// totalling cartTotal at time at. customerRef identifies the customer for
+// per-customer limits. With lock set the voucher row stays locked until q's
+// transaction ends, so a Redeem in the same transaction cannot oversell it.
+func Check(ctx context.Context, q *db.Queries, code, customerRef string, cartTotal money.Amount, at time.Time, lock bool) (db.Voucher, money.Amount, error) {
+	code = NormalizeCode(code)
+	lookup := q.GetVoucherByCode
+	if lock {
+		lookup = q.GetVoucherByCodeForUpdate
+	}
+
+	v, err := lookup(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return v, 0, errors.New("voucher not found")
+		}
+		return v, 0, err
+	}
+
+	if !v.Active {
+		return v, 0, errors.New("voucher is not active")
+	}
+	if v.StartsAt.Valid && at.Before(v.StartsAt.Time) {
+		return v, 0, errors.New("voucher is not valid yet")
+	}
+	if v.ExpiresAt.Valid && !at.Before(v.ExpiresAt.Time) {
+		return v, 0, errors.New("voucher has expired")
+	}
+	if v.UsageLimit.Valid && v.UsedCount >= v.UsageLimit.Int32 {
+		return v, 0, errors.New("voucher usage limit reached")
+	}
+
+	if v.PerCustomerLimit.Valid {
+		customerRef = strings.TrimSpace(customerRef)
+		if customerRef == "" {
+			return v, 0, errors.New("voucher requires a customer")
+		}
+		used, err := q.CountVoucherRedemptionsByCustomer(ctx, db.CountVoucherRedemptionsByCustomerParams{
+			VoucherID:   v.ID,
+			CustomerRef: pgtype.Text{String: customerRef, Valid: true},
+		})
+		if err != nil {
+			return v, 0, err
+		}
+		if used >= int64(v.PerCustomerLimit.Int32) {
+			return v, 0, errors.New("voucher usage limit reached for this customer")
+		}
+	}
+
+	minSpend, err := money.FromNumeric(v.MinSpend)
+	if err != nil {
+		return v, 0, err
+	}
+	if cartTotal < minSpend {
+		return v, 0, fmt.Errorf("voucher requires a minimum spend of %s", minSpend)
+	}
+
+	value, err := money.FromNumeric(v.Value)
+	if err != nil {
+		return v, 0, err
+	}
+	discount := value
+	if v.Type == TypePercent {
+		discount = cartTotal.MulDiv(value.Cents(), money.New(100).Cents())
+	}
+	if discount > cartTotal {
+		discount = cartTotal
+	}
+	return v, discount, nil
+}
+
+// Redeem records that saleID used v for amount. It must run in the same
+// transaction as the Check that locked v and the insert of the sale.
+func Redeem(ctx context.Context, q *db.Queries, v db.Voucher, saleID int32, customerRef string, amount money.Amount) error {
+	updated, err := q.IncrementVoucherUsage(ctx, v.ID)
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return errors.New("voucher usage limit reached")
+	}
+
+	var customerRefPg pgtype.Text
+	if customerRef = strings.TrimSpace(customerRef); customerRef != "" {
+		customerRefPg = pgtype.Text{String: customerRef, Valid: true}
+	}
+
+	_, err = q.CreateVoucherRedemption(ctx, db.CreateVoucherRedemptionParams{
+		VoucherID:   v.ID,
+		SaleID:      saleID,
+		CustomerRef: customerRefPg,
+		Amount:      amount.Numeric(),
+	})
+	return err
+}
+
+// Release undoes the redemption made by saleID, if any, so a voided sale
+// does not count against the voucher's limits.
+func Release(ctx context.Context, q *db.Queries, saleID int32) error {
+	redemption, err := q.DeleteVoucherRedemptionBySale(ctx, saleID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+	return q.DecrementVoucherUsage(ctx, redemption.VoucherID)
+}
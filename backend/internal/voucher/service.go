@@ -0,0 +1,278 @@
+package voucher
+
+import (
+	"context"
+	"errors"
+	"pos-system/internal/db"
+	"pos-system/internal/money"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	TypeFixed   = "fixed"
+	TypePercent = "percent"
+)
+
+type Service struct {
+	queries *db.Queries
+}
+
+func NewService(queries *db.Queries) *Service {
+	return &Service{queries: queries}
+}
+
+// VoucherRequest describes a voucher. Value is an amount for fixed vouchers
+// and a percentage for percent vouchers. UsageLimit and PerCustomerLimit are
+// unlimited when omitted; Active defaults to true.
+type VoucherRequest struct {
+	Code             string       `json:"code" binding:"required"`
+	Description      *string      `json:"description"`
+	Type             string       `json:"type" binding:"required"`
+	Value            money.Amount `json:"value"`
+	MinSpend         money.Amount `json:"min_spend"`
+	UsageLimit       *int32       `json:"usage_limit"`
+	PerCustomerLimit *int32       `json:"per_customer_limit"`
+	StartsAt         *time.Time   `json:"starts_at"`
+	ExpiresAt        *time.Time   `json:"expires_at"`
+	Active           *bool        `json:"active"`
+}
+
+type VoucherResponse struct {
+	ID               int32   `json:"id"`
+	Code             string  `json:"code"`
+	Description      *string `json:"description"`
+	Type             string  `json:"type"`
+	Value            string  `json:"value"`
+	MinSpend         string  `json:"min_spend"`
+	UsageLimit       *int32  `json:"usage_limit"`
+	PerCustomerLimit *int32  `json:"per_customer_limit"`
+	UsedCount        int32   `json:"used_count"`
+	StartsAt         *string `json:"starts_at"`
+	ExpiresAt        *string `json:"expires_at"`
+	Active           bool    `json:"active"`
+	CreatedAt        string  `json:"created_at"`
+}
+
+// NormalizeCode is how codes are stored and looked up, so "summer10 " and
+// "SUMMER10" are the same voucher.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func validate(req *VoucherRequest) error {
+	req.Code = NormalizeCode(req.Code)
+	if req.Code == "" {
+		return errors.New("voucher code is required")
+	}
+
+	switch req.Type {
+	case TypeFixed:
+		if req.Value <= 0 {
+			return errors.New("voucher value must be greater than zero")
+		}
+	case TypePercent:
+		if req.Value <= 0 || req.Value > money.New(100) {
+			return errors.New("voucher percent must be between 0 and 100")
+		}
+	default:
+		return errors.New("voucher type must be fixed or percent")
+	}
+
+	if req.MinSpend < 0 {
+		return errors.New("voucher min_spend cannot be negative")
+	}
+	if (req.UsageLimit != nil && *req.UsageLimit <= 0) || (req.PerCustomerLimit != nil && *req.PerCustomerLimit <= 0) {
+		return errors.New("voucher usage limits must be greater than zero")
+	}
+	if req.StartsAt != nil && req.ExpiresAt != nil && !req.ExpiresAt.After(*req.StartsAt) {
+		return errors.New("voucher expires_at must be after starts_at")
+	}
+	return nil
+}
+
+func toResponse(v db.Voucher) VoucherResponse {
+	formatTime := func(t pgtype.Timestamptz) *string {
+		if !t.Valid {
+			return nil
+		}
+		s := t.Time.Format("2006-01-02T15:04:05Z07:00")
+		return &s
+	}
+	formatAmount := func(n pgtype.Numeric) string {
+		amount, err := money.FromNumeric(n)
+		if err != nil {
+			return "0.00"
+		}
+		return amount.String()
+	}
+
+	resp := VoucherResponse{
+		ID:        v.ID,
+		Code:      v.Code,
+		Type:      v.Type,
+		Value:     formatAmount(v.Value),
+		MinSpend:  formatAmount(v.MinSpend),
+		UsedCount: v.UsedCount,
+		StartsAt:  formatTime(v.StartsAt),
+		ExpiresAt: formatTime(v.ExpiresAt),
+		Active:    v.Active,
+	}
+	if v.Description.Valid {
+		resp.Description = &v.Description.String
+	}
+	if v.UsageLimit.Valid {
+		resp.UsageLimit = &v.UsageLimit.Int32
+	}
+	if v.PerCustomerLimit.Valid {
+		resp.PerCustomerLimit = &v.PerCustomerLimit.Int32
+	}
+	if v.CreatedAt.Valid {
+		resp.CreatedAt = v.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return resp
+}
+
+func optText(v *string) pgtype.Text {
+	if v == nil {
+		return pgtype.Text{}
+	}
+	return pgtype.Text{String: *v, Valid: true}
+}
+
+func optInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func optTime(v *time.Time) pgtype.Timestamptz {
+	if v == nil {
+		return pgtype.Timestamptz{}
+	}
+	return pgtype.Timestamptz{Time: *v, Valid: true}
+}
+
+func isDuplicateCode(err error) bool {
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "duplicate key") && strings.Contains(errMsg, "vouchers_code_key")
+}
+
+func (s *Service) List(ctx context.Context) ([]VoucherResponse, error) {
+	vouchers, err := s.queries.ListVouchers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]VoucherResponse, len(vouchers))
+	for i, v := range vouchers {
+		result[i] = toResponse(v)
+	}
+	return result, nil
+}
+
+func (s *Service) GetByID(ctx context.Context, id int32) (*VoucherResponse, error) {
+	v, err := s.queries.GetVoucherByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("voucher not found")
+		}
+		return nil, err
+	}
+
+	result := toResponse(v)
+	return &result, nil
+}
+
+func (s *Service) Create(ctx context.Context, req VoucherRequest) (*VoucherResponse, error) {
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+
+	v, err := s.queries.CreateVoucher(ctx, db.CreateVoucherParams{
+		Code:             req.Code,
+		Description:      optText(req.Description),
+		Type:             req.Type,
+		Value:            req.Value.Numeric(),
+		MinSpend:         req.MinSpend.Numeric(),
+		UsageLimit:       optInt4(req.UsageLimit),
+		PerCustomerLimit: optInt4(req.PerCustomerLimit),
+		StartsAt:         optTime(req.StartsAt),
+		ExpiresAt:        optTime(req.ExpiresAt),
+		Active:           req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		if isDuplicateCode(err) {
+			return nil, errors.New("voucher code already exists")
+		}
+		return nil, err
+	}
+
+	result := toResponse(v)
+	return &result, nil
+}
+
+// Update changes a voucher's terms. Its usage count is kept, and the usage
+// limit cannot drop below it.
+func (s *Service) Update(ctx context.Context, id int32, req VoucherRequest) (*VoucherResponse, error) {
+	if err := validate(&req); err != nil {
+		return nil, err
+	}
+
+	current, err := s.queries.GetVoucherByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("voucher not found")
+		}
+		return nil, err
+	}
+	if req.UsageLimit != nil && *req.UsageLimit < current.UsedCount {
+		return nil, errors.New("voucher usage limit cannot be below its usage count")
+	}
+
+	v, err := s.queries.UpdateVoucher(ctx, db.UpdateVoucherParams{
+		ID:               id,
+		Code:             req.Code,
+		Description:      optText(req.Description),
+		Type:             req.Type,
+		Value:            req.Value.Numeric(),
+		MinSpend:         req.MinSpend.Numeric(),
+		UsageLimit:       optInt4(req.UsageLimit),
+		PerCustomerLimit: optInt4(req.PerCustomerLimit),
+		StartsAt:         optTime(req.StartsAt),
+		ExpiresAt:        optTime(req.ExpiresAt),
+		Active:           req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("voucher not found")
+		}
+		if isDuplicateCode(err) {
+			return nil, errors.New("voucher code already exists")
+		}
+		return nil, err
+	}
+
+	result := toResponse(v)
+	return &result, nil
+}
+
+func (s *Service) Delete(ctx context.Context, id int32) error {
+	v, err := s.queries.GetVoucherByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("voucher not found")
+		}
+		return err
+	}
+
+	// Redeemed vouchers stay on the sales that used them; deactivate instead
+	if v.UsedCount > 0 {
+		return errors.New("voucher has been redeemed and cannot be deleted")
+	}
+	return s.queries.DeleteVoucher(ctx, id)
+}
@@ -0,0 +1,9 @@
+-- 0004_sale_voids.sql
+-- Void support: a voided sale keeps its rows for audit but its stock is
+-- returned to inventory and it no longer counts towards reports.
+
+ALTER TABLE sales ADD COLUMN voided_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE sales ADD COLUMN voided_by INT REFERENCES users(id);
+ALTER TABLE sales ADD COLUMN void_reason TEXT;
+
+CREATE INDEX idx_sales_voided_at ON sales(voided_at);
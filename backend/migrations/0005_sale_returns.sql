@@ -0,0 +1,28 @@
+-- 0005_sale_returns.sql
+-- Partial returns: a return document references a sale and the specific
+-- sale_items being brought back, with the refunded amount per line.
+
+CREATE TABLE sale_returns (
+  id SERIAL PRIMARY KEY,
+  return_no TEXT UNIQUE NOT NULL,
+  sale_id INT NOT NULL REFERENCES sales(id),
+  user_id INT REFERENCES users(id),
+  refund_amount NUMERIC(14,2) NOT NULL,
+  refund_method TEXT,
+  reason TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE TABLE sale_return_items (
+  id SERIAL PRIMARY KEY,
+  return_id INT NOT NULL REFERENCES sale_returns(id) ON DELETE CASCADE,
+  sale_item_id INT NOT NULL REFERENCES sale_items(id),
+  product_id INT REFERENCES products(id),
+  qty INTEGER NOT NULL CHECK (qty > 0),
+  refund_amount NUMERIC(12,2) NOT NULL
+);
+
+CREATE INDEX idx_sale_returns_sale ON sale_returns(sale_id);
+CREATE INDEX idx_sale_returns_created_at ON sale_returns(created_at);
+CREATE INDEX idx_sale_return_items_return ON sale_return_items(return_id);
+CREATE INDEX idx_sale_return_items_sale_item ON sale_return_items(sale_item_id);
@@ -0,0 +1,20 @@
+-- 0006_sale_payments.sql
+-- Split tender: one row per payment used on a sale. amount is the part of
+-- the sale total settled by that tender, so cash rows are net of change and
+-- the payments of a sale always add up to its total_amount.
+
+CREATE TABLE sale_payments (
+  id SERIAL PRIMARY KEY,
+  sale_id INT NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+  method TEXT NOT NULL,
+  amount NUMERIC(14,2) NOT NULL CHECK (amount >= 0),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_sale_payments_sale ON sale_payments(sale_id);
+CREATE INDEX idx_sale_payments_method ON sale_payments(method);
+
+-- Existing sales were single-tender
+INSERT INTO sale_payments (sale_id, method, amount, created_at)
+SELECT id, COALESCE(payment_method, 'cash'), total_amount, created_at
+FROM sales;
@@ -0,0 +1,15 @@
+-- 0007_held_carts.sql
+-- Parked transactions: a cashier can suspend a cart and pick it up later.
+-- items holds the cart lines in the same shape as a sale request item.
+
+CREATE TABLE held_carts (
+  id SERIAL PRIMARY KEY,
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  label TEXT,
+  items JSONB NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE INDEX idx_held_carts_user ON held_carts(user_id);
+CREATE INDEX idx_held_carts_expires_at ON held_carts(expires_at);
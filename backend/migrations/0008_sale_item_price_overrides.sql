@@ -0,0 +1,11 @@
+-- 0008_sale_item_price_overrides.sql
+-- Sale lines are priced from products.price on the server. Any deviation
+-- (a different unit price or a manual discount) needs an admin's approval,
+-- which is recorded per line for audit.
+
+ALTER TABLE sale_items ADD COLUMN list_price NUMERIC(12,2);
+ALTER TABLE sale_items ADD COLUMN override_price NUMERIC(12,2);
+ALTER TABLE sale_items ADD COLUMN override_by INT REFERENCES users(id);
+
+-- Historical lines were sold at whatever price the client sent
+UPDATE sale_items SET list_price = price WHERE list_price IS NULL;
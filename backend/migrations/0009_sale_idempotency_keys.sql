@@ -0,0 +1,15 @@
+-- 0009_sale_idempotency_keys.sql
+-- Client-supplied idempotency keys for sale creation. A retried request with
+-- the same key replays the original sale; request_hash detects a key reused
+-- for a different request. Keys are scoped per cashier.
+
+CREATE TABLE sale_idempotency_keys (
+  user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  idempotency_key TEXT NOT NULL,
+  request_hash TEXT NOT NULL,
+  sale_id INT NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX idx_sale_idempotency_keys_sale ON sale_idempotency_keys(sale_id);
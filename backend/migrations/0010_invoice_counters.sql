@@ -0,0 +1,11 @@
+-- 0010_invoice_counters.sql
+-- Per-period counters for sequential invoice numbers. scope identifies the
+-- prefix, store code and period (e.g. INV|JKT01|20260115); a new period gets
+-- a fresh row and so starts again from 1. Counters are bumped inside the sale
+-- transaction, so a rolled-back sale leaves no gap.
+
+CREATE TABLE invoice_counters (
+  scope TEXT PRIMARY KEY,
+  last_value BIGINT NOT NULL,
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
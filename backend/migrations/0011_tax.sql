@@ -0,0 +1,38 @@
+-- 0011_tax.sql
+-- VAT (PPN) support. A product is taxed at its own rate, or else its
+-- category's; tax_exempt products are never taxed. Inclusive rates mean the
+-- product price already contains the tax. Each sale line stores the rate it
+-- was charged at so later rate changes do not rewrite history.
+
+CREATE TABLE tax_rates (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  rate NUMERIC(5,2) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+  inclusive BOOLEAN NOT NULL DEFAULT false,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+ALTER TABLE categories
+  ADD COLUMN tax_rate_id INT REFERENCES tax_rates(id) ON DELETE SET NULL;
+
+ALTER TABLE products
+  ADD COLUMN tax_rate_id INT REFERENCES tax_rates(id) ON DELETE SET NULL,
+  ADD COLUMN tax_exempt BOOLEAN NOT NULL DEFAULT false;
+
+-- subtotal_amount is the sum of the line subtotals as priced; total_amount
+-- adds exclusive tax on top of it
+ALTER TABLE sales
+  ADD COLUMN subtotal_amount NUMERIC(14,2),
+  ADD COLUMN tax_amount NUMERIC(14,2) NOT NULL DEFAULT 0;
+
+UPDATE sales SET subtotal_amount = total_amount WHERE subtotal_amount IS NULL;
+
+ALTER TABLE sale_items
+  ADD COLUMN tax_rate_id INT REFERENCES tax_rates(id) ON DELETE SET NULL,
+  ADD COLUMN tax_rate NUMERIC(5,2) NOT NULL DEFAULT 0,
+  ADD COLUMN tax_inclusive BOOLEAN NOT NULL DEFAULT false,
+  ADD COLUMN tax_amount NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+INSERT INTO tax_rates (name, rate, inclusive) VALUES
+('PPN 11%', 11.00, false),
+('PPN 11% (inclusive)', 11.00, true);
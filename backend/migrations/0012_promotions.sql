@@ -0,0 +1,48 @@
+-- 0012_promotions.sql
+-- Automatic discounts. Which columns a promotion uses depends on its type:
+--   category_percent  category_id, percent         percent off every line in the category
+--   product_amount    product_id, amount           amount off each unit of the product
+--   buy_x_get_y       product_id, buy_qty, get_qty every buy_qty + get_qty units, get_qty are free
+--   bundle_price      product_id, buy_qty, amount  every buy_qty units cost amount
+--   min_spend         min_spend, percent or amount cart discount once the cart reaches min_spend
+-- A promotion applies from starts_at until ends_at (open-ended when NULL)
+-- while active is true.
+
+CREATE TABLE promotions (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  type TEXT NOT NULL CHECK (type IN ('category_percent', 'product_amount', 'buy_x_get_y', 'bundle_price', 'min_spend')),
+  category_id INT REFERENCES categories(id) ON DELETE CASCADE,
+  product_id INT REFERENCES products(id) ON DELETE CASCADE,
+  percent NUMERIC(5,2),
+  amount NUMERIC(12,2),
+  buy_qty INT,
+  get_qty INT,
+  min_spend NUMERIC(14,2),
+  starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+  ends_at TIMESTAMP WITH TIME ZONE,
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_promotions_window ON promotions(starts_at, ends_at) WHERE active;
+
+-- promotion_discount is the part of a line's discount that came from
+-- promotions; discount remains the manual (approved) discount
+ALTER TABLE sale_items
+  ADD COLUMN promotion_discount NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+-- Which promotion produced each discount. Cart promotions are spread over the
+-- lines, so every row belongs to a sale item. The name is kept so receipts
+-- still read correctly after a promotion is renamed or deleted.
+CREATE TABLE sale_item_promotions (
+  id SERIAL PRIMARY KEY,
+  sale_id INT NOT NULL REFERENCES sales(id) ON DELETE CASCADE,
+  sale_item_id INT NOT NULL REFERENCES sale_items(id) ON DELETE CASCADE,
+  promotion_id INT REFERENCES promotions(id) ON DELETE SET NULL,
+  promotion_name TEXT NOT NULL,
+  amount NUMERIC(12,2) NOT NULL CHECK (amount >= 0)
+);
+
+CREATE INDEX idx_sale_item_promotions_sale ON sale_item_promotions(sale_id);
+CREATE INDEX idx_sale_item_promotions_promotion ON sale_item_promotions(promotion_id);
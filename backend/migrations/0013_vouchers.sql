@@ -0,0 +1,45 @@
+-- 0013_vouchers.sql
+-- Printed voucher codes redeemed at checkout. A fixed voucher takes value off
+-- the cart; a percent voucher takes value percent of it. Codes are stored
+-- upper-case so lookups ignore the case the cashier typed.
+
+CREATE TABLE vouchers (
+  id SERIAL PRIMARY KEY,
+  code TEXT NOT NULL UNIQUE CHECK (code = upper(code)),
+  description TEXT,
+  type TEXT NOT NULL CHECK (type IN ('fixed', 'percent')),
+  value NUMERIC(12,2) NOT NULL CHECK (value > 0),
+  min_spend NUMERIC(14,2) NOT NULL DEFAULT 0 CHECK (min_spend >= 0),
+  -- NULL means unlimited
+  usage_limit INT CHECK (usage_limit > 0),
+  per_customer_limit INT CHECK (per_customer_limit > 0),
+  used_count INT NOT NULL DEFAULT 0,
+  starts_at TIMESTAMP WITH TIME ZONE,
+  expires_at TIMESTAMP WITH TIME ZONE,
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  CHECK (type <> 'percent' OR value <= 100),
+  CHECK (usage_limit IS NULL OR used_count <= usage_limit)
+);
+
+-- One row per sale that redeemed a voucher. customer_ref identifies the
+-- customer (phone number or member ID) for per-customer limits.
+CREATE TABLE voucher_redemptions (
+  id SERIAL PRIMARY KEY,
+  voucher_id INT NOT NULL REFERENCES vouchers(id) ON DELETE CASCADE,
+  sale_id INT NOT NULL UNIQUE REFERENCES sales(id) ON DELETE CASCADE,
+  customer_ref TEXT,
+  amount NUMERIC(12,2) NOT NULL CHECK (amount >= 0),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_voucher_redemptions_customer ON voucher_redemptions(voucher_id, customer_ref);
+
+-- The voucher discount is spread over the lines before tax, like a cart
+-- promotion; the sale keeps the code for receipts
+ALTER TABLE sales
+  ADD COLUMN voucher_code TEXT,
+  ADD COLUMN voucher_discount NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE sale_items
+  ADD COLUMN voucher_discount NUMERIC(12,2) NOT NULL DEFAULT 0;
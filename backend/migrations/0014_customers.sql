@@ -0,0 +1,35 @@
+-- 0014_customers.sql
+-- Customers and loyalty points. customers.points is the spendable balance;
+-- customer_point_entries is the ledger it is kept from.
+
+CREATE TABLE customers (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL,
+  phone TEXT UNIQUE,
+  email TEXT,
+  points BIGINT NOT NULL DEFAULT 0 CHECK (points >= 0),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_customers_name ON customers(name);
+
+ALTER TABLE sales
+  ADD COLUMN customer_id INT REFERENCES customers(id) ON DELETE SET NULL,
+  ADD COLUMN points_earned BIGINT NOT NULL DEFAULT 0,
+  ADD COLUMN points_redeemed BIGINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_sales_customer ON sales(customer_id, created_at DESC);
+
+-- points is signed: earned points are positive, redeemed points negative, and
+-- a void reverses both
+CREATE TABLE customer_point_entries (
+  id SERIAL PRIMARY KEY,
+  customer_id INT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+  sale_id INT REFERENCES sales(id) ON DELETE SET NULL,
+  type TEXT NOT NULL CHECK (type IN ('earn', 'redeem', 'void')),
+  points BIGINT NOT NULL,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_customer_point_entries_customer ON customer_point_entries(customer_id, created_at DESC);
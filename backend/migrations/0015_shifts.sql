@@ -0,0 +1,52 @@
+-- 0015_shifts.sql
+-- Cashier shifts. A cashier opens a shift with an opening float, every sale
+-- they ring up is attached to it, and closing it records the counted drawer
+-- against what the shift's tenders, pay-ins, pay-outs and refunds say should
+-- be there.
+
+CREATE TABLE shifts (
+  id SERIAL PRIMARY KEY,
+  user_id INT NOT NULL REFERENCES users(id),
+  opening_float NUMERIC(14,2) NOT NULL CHECK (opening_float >= 0),
+  opening_note TEXT,
+  opened_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  closed_at TIMESTAMP WITH TIME ZONE,
+  expected_cash NUMERIC(14,2),
+  counted_cash NUMERIC(14,2),
+  cash_variance NUMERIC(14,2),
+  closing_note TEXT
+);
+
+-- A cashier has at most one open shift
+CREATE UNIQUE INDEX idx_shifts_open_user ON shifts(user_id) WHERE closed_at IS NULL;
+CREATE INDEX idx_shifts_opened_at ON shifts(opened_at DESC);
+
+-- Cash put into (pay_in) or taken out of (pay_out) the drawer outside a sale,
+-- e.g. extra change or paying a supplier
+CREATE TABLE shift_cash_movements (
+  id SERIAL PRIMARY KEY,
+  shift_id INT NOT NULL REFERENCES shifts(id) ON DELETE CASCADE,
+  type TEXT NOT NULL CHECK (type IN ('pay_in', 'pay_out')),
+  amount NUMERIC(14,2) NOT NULL CHECK (amount > 0),
+  reason TEXT NOT NULL,
+  user_id INT REFERENCES users(id),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_shift_cash_movements_shift ON shift_cash_movements(shift_id);
+
+-- The reconciliation written when a shift closes, one row per payment
+-- method. counted and variance are NULL for methods that were not counted.
+CREATE TABLE shift_tenders (
+  id SERIAL PRIMARY KEY,
+  shift_id INT NOT NULL REFERENCES shifts(id) ON DELETE CASCADE,
+  method TEXT NOT NULL,
+  expected NUMERIC(14,2) NOT NULL,
+  counted NUMERIC(14,2),
+  variance NUMERIC(14,2),
+  UNIQUE (shift_id, method)
+);
+
+ALTER TABLE sales ADD COLUMN shift_id INT REFERENCES shifts(id);
+
+CREATE INDEX idx_sales_shift ON sales(shift_id);
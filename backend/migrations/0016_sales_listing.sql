@@ -0,0 +1,6 @@
+-- 0016_sales_listing.sql
+-- Indexes for the filtered sales listing. Pages are read newest first on
+-- (created_at, id), and invoice numbers are matched by prefix.
+
+CREATE INDEX idx_sales_created_at_id ON sales(created_at DESC, id DESC);
+CREATE INDEX idx_sales_invoice_no_prefix ON sales(invoice_no text_pattern_ops);
@@ -0,0 +1,42 @@
+-- 0017_gift_cards.sql
+-- Stored value: gift cards sold to customers and store credit issued in place
+-- of a cash refund. Both are a card number with a balance; every change to
+-- the balance is written to the ledger.
+
+CREATE TABLE gift_cards (
+  id SERIAL PRIMARY KEY,
+  card_number TEXT NOT NULL UNIQUE,
+  type TEXT NOT NULL CHECK (type IN ('gift_card', 'store_credit')),
+  balance NUMERIC(14,2) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+  customer_id INT REFERENCES customers(id) ON DELETE SET NULL,
+  expires_at TIMESTAMP WITH TIME ZONE,
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_gift_cards_customer ON gift_cards(customer_id);
+CREATE INDEX idx_gift_cards_expires_at ON gift_cards(expires_at) WHERE balance > 0;
+
+-- amount is signed: issue, reload and void add to the balance, redeem and
+-- expire take from it. payment_method is the tender that paid for an issue
+-- or reload, so the cashier's shift can account for it; it is NULL for store
+-- credit, which nobody paid for.
+CREATE TABLE gift_card_transactions (
+  id SERIAL PRIMARY KEY,
+  gift_card_id INT NOT NULL REFERENCES gift_cards(id) ON DELETE CASCADE,
+  type TEXT NOT NULL CHECK (type IN ('issue', 'reload', 'redeem', 'void', 'expire')),
+  amount NUMERIC(14,2) NOT NULL,
+  balance_after NUMERIC(14,2) NOT NULL,
+  sale_id INT REFERENCES sales(id),
+  return_id INT REFERENCES sale_returns(id),
+  payment_method TEXT,
+  user_id INT REFERENCES users(id),
+  note TEXT,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+CREATE INDEX idx_gift_card_transactions_card ON gift_card_transactions(gift_card_id, created_at);
+CREATE INDEX idx_gift_card_transactions_sale ON gift_card_transactions(sale_id) WHERE sale_id IS NOT NULL;
+CREATE INDEX idx_gift_card_transactions_return ON gift_card_transactions(return_id) WHERE return_id IS NOT NULL;
+CREATE INDEX idx_gift_card_transactions_user ON gift_card_transactions(user_id, created_at) WHERE payment_method IS NOT NULL;
@@ -0,0 +1,7 @@
+-- 0018_cash_rounding.sql
+-- Cash payments are rounded to the smallest increment the store can give in
+-- change. rounding_amount is what the rounding added to (or, when negative,
+-- took off) total_amount, so total_amount + rounding_amount is what the
+-- sale's tenders add up to.
+
+ALTER TABLE sales ADD COLUMN rounding_amount NUMERIC(14,2) NOT NULL DEFAULT 0;
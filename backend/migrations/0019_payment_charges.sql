@@ -0,0 +1,31 @@
+-- 0019_payment_charges.sql
+-- QRIS and e-wallet tenders are charged through a payment provider. A sale
+-- paid that way waits in payment_status 'pending', with its stock untouched,
+-- until the provider confirms the charge. A failed charge voids the sale.
+
+ALTER TABLE sales ADD COLUMN payment_status TEXT NOT NULL DEFAULT 'paid'
+  CHECK (payment_status IN ('pending', 'paid', 'failed'));
+
+CREATE INDEX idx_sales_payment_pending ON sales(created_at) WHERE payment_status = 'pending';
+
+-- reference is the provider's id for the charge. status follows the
+-- provider; cancelled is set locally when the cashier gives up waiting.
+CREATE TABLE payment_charges (
+  id SERIAL PRIMARY KEY,
+  sale_id INT NOT NULL REFERENCES sales(id),
+  provider TEXT NOT NULL,
+  method TEXT NOT NULL,
+  reference TEXT NOT NULL,
+  amount NUMERIC(14,2) NOT NULL CHECK (amount > 0),
+  status TEXT NOT NULL DEFAULT 'pending'
+    CHECK (status IN ('pending', 'paid', 'failed', 'expired', 'cancelled', 'refunded')),
+  qr_string TEXT,
+  checkout_url TEXT,
+  expires_at TIMESTAMP WITH TIME ZONE,
+  paid_at TIMESTAMP WITH TIME ZONE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  updated_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  UNIQUE (provider, reference)
+);
+
+CREATE INDEX idx_payment_charges_sale ON payment_charges(sale_id);
@@ -0,0 +1,12 @@
+-- 0020_fractional_quantities.sql
+-- Weighed and measured goods (coffee beans by the kilogram, fabric by the
+-- meter) are sold in fractions of a unit. Quantities keep up to three
+-- decimals; qty_precision is how many of them a product's quantities may
+-- use, e.g. 3 for kg and 0 for pcs.
+
+ALTER TABLE products ADD COLUMN qty_precision SMALLINT NOT NULL DEFAULT 0
+  CHECK (qty_precision BETWEEN 0 AND 3);
+
+ALTER TABLE inventory ALTER COLUMN qty TYPE NUMERIC(12,3);
+ALTER TABLE sale_items ALTER COLUMN qty TYPE NUMERIC(12,3);
+ALTER TABLE sale_return_items ALTER COLUMN qty TYPE NUMERIC(12,3);
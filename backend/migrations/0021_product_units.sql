@@ -0,0 +1,22 @@
+-- 0021_product_units.sql
+-- Packaging units: a product bought by the carton and sold by the bottle has
+-- "dus" as a unit with factor 24, the number of base units (products.unit)
+-- in one. price is what one of the unit sells for; NULL means factor times
+-- the product's price. Inventory is always counted in the base unit.
+
+CREATE TABLE product_units (
+  id SERIAL PRIMARY KEY,
+  product_id INT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  factor NUMERIC(12,3) NOT NULL CHECK (factor > 0),
+  price NUMERIC(12,2) CHECK (price >= 0),
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  UNIQUE (product_id, name)
+);
+
+-- A sale line sold in a packaging unit keeps the unit's name and factor, so
+-- its qty and price stay readable after the unit is changed or deleted.
+-- qty and price are per unit; the stock taken is qty * unit_factor.
+ALTER TABLE sale_items ADD COLUMN unit_id INT REFERENCES product_units(id) ON DELETE SET NULL;
+ALTER TABLE sale_items ADD COLUMN unit_name TEXT;
+ALTER TABLE sale_items ADD COLUMN unit_factor NUMERIC(12,3) NOT NULL DEFAULT 1;
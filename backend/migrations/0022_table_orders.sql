@@ -0,0 +1,67 @@
+-- 0022_table_orders.sql
+-- Dine-in service: an order is opened on a table, receives items over the
+-- course of the meal and is settled as one sale at the end. Items go to the
+-- kitchen in batches, one kitchen ticket per batch. A table is free when it
+-- has no open order, occupied while it has one and bill requested once the
+-- guests ask for the bill.
+
+CREATE TABLE dining_tables (
+  id SERIAL PRIMARY KEY,
+  name TEXT NOT NULL UNIQUE,
+  seats INT CHECK (seats > 0),
+  active BOOLEAN NOT NULL DEFAULT true,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+-- revision goes up whenever the order's items change, so settling can tell
+-- whether items arrived after it read them.
+CREATE TABLE table_orders (
+  id SERIAL PRIMARY KEY,
+  table_id INT NOT NULL REFERENCES dining_tables(id),
+  user_id INT REFERENCES users(id),
+  guests INT CHECK (guests > 0),
+  note TEXT,
+  status TEXT NOT NULL DEFAULT 'open'
+    CHECK (status IN ('open', 'bill_requested', 'settled', 'cancelled')),
+  revision INT NOT NULL DEFAULT 0,
+  sale_id INT REFERENCES sales(id),
+  opened_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  closed_at TIMESTAMP WITH TIME ZONE
+);
+
+-- One open order per table
+CREATE UNIQUE INDEX table_orders_open_table_key ON table_orders (table_id)
+  WHERE status IN ('open', 'bill_requested');
+CREATE INDEX idx_table_orders_sale ON table_orders (sale_id);
+
+CREATE TABLE kitchen_tickets (
+  id SERIAL PRIMARY KEY,
+  order_id INT NOT NULL REFERENCES table_orders(id),
+  -- ticket_no counts the order's tickets: 1 for the first round, 2 for the next
+  ticket_no INT NOT NULL,
+  user_id INT REFERENCES users(id),
+  status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'done')),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  done_at TIMESTAMP WITH TIME ZONE,
+  UNIQUE (order_id, ticket_no)
+);
+
+CREATE INDEX idx_kitchen_tickets_status ON kitchen_tickets (status, created_at);
+
+-- Items are priced when the order is settled. unit_name keeps the packaging
+-- unit readable on kitchen tickets if the unit is deleted meanwhile; ticket_id
+-- is NULL until the item is sent to the kitchen.
+CREATE TABLE table_order_items (
+  id SERIAL PRIMARY KEY,
+  order_id INT NOT NULL REFERENCES table_orders(id),
+  product_id INT NOT NULL REFERENCES products(id),
+  qty NUMERIC(12,3) NOT NULL CHECK (qty > 0),
+  unit_id INT REFERENCES product_units(id) ON DELETE SET NULL,
+  unit_name TEXT,
+  note TEXT,
+  ticket_id INT REFERENCES kitchen_tickets(id),
+  created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_table_order_items_order ON table_order_items (order_id);
+CREATE INDEX idx_table_order_items_ticket ON table_order_items (ticket_id);
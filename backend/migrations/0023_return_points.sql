@@ -0,0 +1,7 @@
+-- 0023_return_points.sql
+-- A return takes back the loyalty points earned on the part of the sale it
+-- refunds, recorded in the ledger as a 'return' entry against the sale.
+
+ALTER TABLE customer_point_entries DROP CONSTRAINT customer_point_entries_type_check;
+ALTER TABLE customer_point_entries ADD CONSTRAINT customer_point_entries_type_check
+  CHECK (type IN ('earn', 'redeem', 'void', 'return'));